@@ -9,7 +9,8 @@ import (
 func main() {
 
 	root := cmd.NewCmdLogFilter(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	root.SetArgs(cmd.NormalizeLevelArgs(os.Args[1:]))
 	if err := root.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -4,10 +4,12 @@ go 1.16
 
 require (
 	github.com/jarcoal/httpmock v1.0.8
+	github.com/openshift/api v0.0.0-20220912165558-10c9464b1012
 	github.com/spf13/cobra v1.1.3
-	k8s.io/api v0.21.0
-	k8s.io/apimachinery v0.21.0
+	k8s.io/api v0.21.1
+	k8s.io/apimachinery v0.21.1
 	k8s.io/cli-runtime v0.21.0
 	k8s.io/client-go v0.21.0
 	k8s.io/kubectl v0.21.0
+	sigs.k8s.io/yaml v1.2.0
 )
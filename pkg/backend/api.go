@@ -0,0 +1,285 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// minimumPageWindow is the shortest time slice the API backend splits a full
+// page into. Logs beyond the page size within a single slice cannot be reached.
+const minimumPageWindow = time.Millisecond
+
+type responseLogs struct {
+	Logs  []string
+	Error string
+}
+
+// APIBackend reads logs through the log-exploration API, which answers one
+// pod, or a whole namespace, per request.
+type APIBackend struct {
+	HttpClient *http.Client
+	BaseUrl    string
+	// Warnings receives notices about logs which could not be reached.
+	Warnings io.Writer
+}
+
+func NewAPIBackend(httpClient *http.Client, baseUrl string) *APIBackend {
+	return &APIBackend{HttpClient: httpClient, BaseUrl: baseUrl, Warnings: ioutil.Discard}
+}
+
+// Query fetches the logs of every pod concurrently. The logs of the pods which
+// could be fetched are returned together with the errors of the others.
+func (b *APIBackend) Query(ctx context.Context, query Query) ([]logs.LogOptions, error) {
+	logList, _, err := b.queryPods(ctx, query)
+	return logList, err
+}
+
+// Stream pages through the logs by time slices, since the API has no cursors.
+// A slice for which the API returns a full page for any pod is split in two
+// halves until every slice fits in a page. A slice without a start is split
+// at the oldest log of its page instead, the page holding its newest logs.
+func (b *APIBackend) Stream(ctx context.Context, query Query, fn func(page []logs.LogOptions) error) error {
+
+	rangeStart := query.StartTime
+	rangeEnd := query.EndTime
+	if rangeEnd.IsZero() {
+		rangeEnd = time.Now().UTC()
+	}
+
+	windows := []pageWindow{{start: rangeStart, end: rangeEnd}}
+	for len(windows) > 0 {
+		window := windows[len(windows)-1]
+		windows = windows[:len(windows)-1]
+
+		pageQuery := query
+		pageQuery.StartTime = window.start
+		pageQuery.EndTime = window.end
+
+		logList, full, err := b.queryPods(ctx, pageQuery)
+		if err != nil {
+			return err
+		}
+		if full {
+			if window.end.Sub(window.start) > minimumPageWindow {
+				middle := window.start.Add(window.end.Sub(window.start) / 2)
+				if oldest := oldestTimestamp(logList); window.start.IsZero() && !oldest.IsZero() && oldest.Before(window.end) {
+					middle = oldest
+				}
+				older, newer := pageWindow{start: window.start, end: middle}, pageWindow{start: middle, end: window.end}
+				// the half on top of the stack is passed on first
+				if query.Ascending {
+					windows = append(windows, newer, older)
+				} else {
+					windows = append(windows, older, newer)
+				}
+				continue
+			}
+			fmt.Fprintf(b.Warnings, "warning: more than %d logs per pod between %s and %s, some of them were skipped\n", query.Limit, window.start.Format(time.RFC3339Nano), window.end.Format(time.RFC3339Nano))
+		}
+
+		logList = window.logs(logList, window.end.Equal(rangeEnd))
+		if len(logList) == 0 {
+			continue
+		}
+		sort.SliceStable(logList, func(index1, index2 int) bool {
+			if query.Ascending {
+				return logList[index1].Source.Timestamp.Before(logList[index2].Source.Timestamp)
+			}
+			return logList[index1].Source.Timestamp.After(logList[index2].Source.Timestamp)
+		})
+
+		err = fn(logList)
+		if err == ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchesLevels reports whether the API can select the levels, it accepts a
+// single level only.
+func (b *APIBackend) SearchesLevels(levels []string) bool {
+	return len(levels) <= 1
+}
+
+// Fields is not supported, the API does not expose the mapping of the indices.
+func (b *APIBackend) Fields(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("listing fields is not supported by the log-exploration API")
+}
+
+// Namespaces is not supported, the API does not expose aggregations.
+func (b *APIBackend) Namespaces(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("listing namespaces is not supported by the log-exploration API")
+}
+
+// queryPods fetches the logs of every pod concurrently and reports whether the
+// API returned a full page for any pod, in which case more logs may be stored.
+func (b *APIBackend) queryPods(ctx context.Context, query Query) ([]logs.LogOptions, bool, error) {
+
+	pods := query.Pods
+	if len(pods) == 0 {
+		pods = []string{""}
+	}
+
+	type podPage struct {
+		logs []logs.LogOptions
+		err  error
+	}
+
+	podPagesCh := make(chan podPage, len(pods))
+	for _, pod := range pods {
+		go func(pod string) {
+			podLogs, err := b.fetchLogs(ctx, query, pod)
+			podPagesCh <- podPage{logs: podLogs, err: err}
+		}(pod)
+	}
+
+	var logList []logs.LogOptions
+	var errs []error
+	full := false
+	for index := 0; index < len(pods); index++ {
+		page := <-podPagesCh
+		if page.err != nil {
+			errs = append(errs, page.err)
+			continue
+		}
+		logList = append(logList, page.logs...)
+		full = full || (query.Limit > 0 && len(page.logs) >= query.Limit)
+	}
+	if len(pods) == 1 && len(errs) == 1 {
+		// the error of a single pod keeps its kind
+		return logList, full, errs[0]
+	}
+	return logList, full, utilerrors.NewAggregate(errs)
+}
+
+func (b *APIBackend) fetchLogs(ctx context.Context, query Query, podname string) ([]logs.LogOptions, error) {
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.BaseUrl, nil)
+
+	if err != nil {
+		return nil, newError(ErrorUnknown, "unable to fetch logs of pod %s - http request failed: %v", podname, err)
+	}
+
+	level := ""
+	if len(query.Levels) > 0 {
+		level = query.Levels[0]
+	}
+
+	urlQuery := req.URL.Query()
+	urlQuery.Add("/pod/", podname)
+	urlQuery.Add("/namespace/", query.Namespace)
+	urlQuery.Add("/starttime/", formatTime(query.StartTime))
+	urlQuery.Add("/finishtime/", formatTime(query.EndTime))
+	urlQuery.Add("/maxlogs/", strconv.Itoa(query.Limit))
+	urlQuery.Add("/level/", level)
+	req.URL.RawQuery = urlQuery.Encode()
+
+	// authentication headers are added by the transport of HttpClient
+	response, err := b.HttpClient.Do(req)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to fetch logs of pod %s - failed to get http response %v", podname, err)
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to fetch logs of pod %s - failed to read response: %v", podname, err)
+	}
+
+	err = response.Body.Close()
+
+	if err != nil {
+		return nil, newError(ErrorUnavailable, "unable to fetch logs of pod %s - an error occurred while attempting to close response body %v", podname, err)
+	}
+
+	jsonResponse := &responseLogs{}
+	err = json.Unmarshal(responseBody, &jsonResponse)
+
+	if response.StatusCode >= http.StatusBadRequest {
+		message := jsonResponse.Error
+		if err != nil || len(message) == 0 {
+			message = response.Status
+		}
+		return nil, newError(statusErrorKind(response.StatusCode), "unable to fetch logs of pod %s - a server-side error occured: %v", podname, message)
+	}
+
+	if err != nil {
+		return nil, newError(ErrorParse, "unable to fetch logs of pod %s - an error occurred while unmarshalling JSON response: %v", podname, err)
+	}
+
+	if jsonResponse.Error != "" {
+		return nil, newError(ErrorUnknown, "unable to fetch logs of pod %s - a server-side error occured: %v", podname, jsonResponse.Error)
+	}
+
+	var logList []logs.LogOptions
+	for _, log := range jsonResponse.Logs {
+		logOption := logs.LogOptions{}
+		err := json.Unmarshal([]byte(log), &logOption)
+
+		if err != nil {
+			return nil, newError(ErrorParse, "unable to fetch logs of pod %s - no logs present, or input parameters were invalid %v", podname, err)
+		}
+		logList = append(logList, logOption)
+	}
+
+	return logList, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// oldestTimestamp returns the timestamp of the oldest of the logs which have
+// one, or a zero time.
+func oldestTimestamp(logList []logs.LogOptions) time.Time {
+
+	var oldest time.Time
+	for _, log := range logList {
+		if log.Source.Timestamp.IsZero() {
+			continue
+		}
+		if oldest.IsZero() || log.Source.Timestamp.Before(oldest) {
+			oldest = log.Source.Timestamp
+		}
+	}
+	return oldest
+}
+
+type pageWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// logs keeps the logs which belong to the window. Adjacent windows share their
+// boundary, which belongs to the newer one unless it ends the range.
+func (w pageWindow) logs(logList []logs.LogOptions, includeEnd bool) []logs.LogOptions {
+
+	var kept []logs.LogOptions
+	for _, log := range logList {
+		timestamp := log.Source.Timestamp
+		if timestamp.Before(w.start) {
+			continue
+		}
+		if timestamp.After(w.end) || (timestamp.Equal(w.end) && !includeEnd) {
+			continue
+		}
+		kept = append(kept, log)
+	}
+	return kept
+}
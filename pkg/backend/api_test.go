@@ -0,0 +1,240 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestAPIBackendQuery(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			if query.Get("/namespace/") != "openshift-logging" || query.Get("/maxlogs/") != "5" || query.Get("/level/") != "info" {
+				t.Errorf("Unexpected query %v", query)
+			}
+			if query.Get("/starttime/") != "2021-03-18T06:00:00Z" || query.Get("/finishtime/") != "" {
+				t.Errorf("Unexpected time range in query %v", query)
+			}
+			pod := query.Get("/pod/")
+			if pod == "pod-3" {
+				return httpmock.NewJsonResponse(400, map[string]interface{}{"Error": "Not Found Error", "Logs": nil})
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": {
+				fmt.Sprintf(`{"_id":"%s","_source":{"message":"%s","kubernetes":{"pod_name":"%s"}}}`, pod, pod, pod),
+			}})
+		})
+
+	apiBackend := NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	logList, err := apiBackend.Query(context.Background(), Query{
+		Namespace: "openshift-logging",
+		Pods:      []string{"pod-1", "pod-2", "pod-3"},
+		StartTime: time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC),
+		Levels:    []string{"info"},
+		Limit:     5,
+	})
+
+	expectedError := "unable to fetch logs of pod pod-3 - a server-side error occured: Not Found Error"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected error is %v, found %v", expectedError, err)
+	}
+	var messages []string
+	for _, log := range logList {
+		messages = append(messages, log.Source.Message)
+	}
+	if len(messages) != 2 || !strings.Contains(strings.Join(messages, ","), "pod-1") || !strings.Contains(strings.Join(messages, ","), "pod-2") {
+		t.Errorf("Expected logs of pods %v found %v", []string{"pod-1", "pod-2"}, messages)
+	}
+}
+
+func TestAPIBackendError(t *testing.T) {
+	tests := []struct {
+		TestName  string
+		Responder httpmock.Responder
+		Error     string
+		Kind      ErrorKind
+	}{
+		{
+			"Unauthorized",
+			httpmock.NewStringResponder(401, ""),
+			"unable to fetch logs of pod pod-1 - a server-side error occured: 401",
+			ErrorAuth,
+		},
+		{
+			"API unavailable",
+			httpmock.NewJsonResponderOrPanic(503, map[string]interface{}{"Error": "Elasticsearch is not reachable", "Logs": nil}),
+			"unable to fetch logs of pod pod-1 - a server-side error occured: Elasticsearch is not reachable",
+			ErrorUnavailable,
+		},
+		{
+			"Connection refused",
+			httpmock.ConnectionFailure,
+			"unable to fetch logs of pod pod-1 - failed to get http response Get \"http://localhost:8080/logs?%2Ffinishtime%2F=&%2Flevel%2F=&%2Fmaxlogs%2F=5&%2Fnamespace%2F=&%2Fpod%2F=pod-1&%2Fstarttime%2F=\": no responder found",
+			ErrorUnavailable,
+		},
+		{
+			"Invalid log",
+			httpmock.NewJsonResponderOrPanic(200, map[string][]string{"Logs": {"{"}}),
+			"unable to fetch logs of pod pod-1 - no logs present, or input parameters were invalid unexpected end of JSON input",
+			ErrorParse,
+		},
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		httpmock.RegisterResponder("GET", "http://localhost:8080/logs", tt.Responder)
+
+		_, err := NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs").Query(context.Background(), Query{Pods: []string{"pod-1"}, Limit: 5})
+		if err == nil || err.Error() != tt.Error {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if ErrorKindOf(err) != tt.Kind {
+			t.Errorf("Expected error kind %s found %s", tt.Kind, ErrorKindOf(err))
+		}
+	}
+}
+
+func TestAPIBackendStream(t *testing.T) {
+	// 2500 logs one second apart, the API returns the newest page of a range
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	var stored []time.Time
+	for index := 0; index < 2500; index++ {
+		stored = append(stored, base.Add(time.Duration(index)*time.Second))
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			start, _ := time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+			maxLogs, _ := strconv.Atoi(query.Get("/maxlogs/"))
+
+			var page []string
+			for index := len(stored) - 1; index >= 0 && len(page) < maxLogs; index-- {
+				if stored[index].Before(start) || stored[index].After(end) {
+					continue
+				}
+				page = append(page, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%d","@timestamp":"%s"}}`, index, index, stored[index].Format(time.RFC3339Nano)))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	warnings := &bytes.Buffer{}
+	apiBackend := NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	apiBackend.Warnings = warnings
+
+	var streamed []logs.LogOptions
+	err := apiBackend.Stream(context.Background(), Query{
+		Pods:      []string{"pod-1"},
+		StartTime: base,
+		EndTime:   base.Add(time.Hour),
+		Limit:     1000,
+	}, func(page []logs.LogOptions) error {
+		if len(page) > 1000 {
+			t.Errorf("Expected pages of at most %d logs found %d", 1000, len(page))
+		}
+		streamed = append(streamed, page...)
+		if len(streamed) >= 2000 {
+			return ErrStop
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(streamed) < 2000 {
+		t.Fatalf("Expected at least %d logs found %d", 2000, len(streamed))
+	}
+	for index, log := range streamed {
+		if log.Source.Message != strconv.Itoa(len(stored)-1-index) {
+			t.Errorf("Expected log %d at position %d found %s", len(stored)-1-index, index, log.Source.Message)
+			break
+		}
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("Expected no warnings found %q", warnings.String())
+	}
+}
+
+func TestAPIBackendStreamWithoutStart(t *testing.T) {
+	// 2500 logs one second apart, the API returns the newest page of a range
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	var stored []time.Time
+	for index := 0; index < 2500; index++ {
+		stored = append(stored, base.Add(time.Duration(index)*time.Second))
+	}
+
+	requests := 0
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			query := req.URL.Query()
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+			maxLogs, _ := strconv.Atoi(query.Get("/maxlogs/"))
+			var start time.Time
+			if len(query.Get("/starttime/")) > 0 {
+				start, _ = time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			}
+
+			var page []string
+			for index := len(stored) - 1; index >= 0 && len(page) < maxLogs; index-- {
+				if stored[index].Before(start) || stored[index].After(end) {
+					continue
+				}
+				page = append(page, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%d","@timestamp":"%s"}}`, index, index, stored[index].Format(time.RFC3339Nano)))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	warnings := &bytes.Buffer{}
+	apiBackend := NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	apiBackend.Warnings = warnings
+
+	var streamed []logs.LogOptions
+	err := apiBackend.Stream(context.Background(), Query{
+		Pods:    []string{"pod-1"},
+		EndTime: base.Add(time.Hour),
+		Limit:   1000,
+	}, func(page []logs.LogOptions) error {
+		streamed = append(streamed, page...)
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(streamed) != len(stored) {
+		t.Fatalf("Expected %d logs found %d", len(stored), len(streamed))
+	}
+	for index, log := range streamed {
+		if log.Source.Message != strconv.Itoa(len(stored)-1-index) {
+			t.Errorf("Expected log %d at position %d found %s", len(stored)-1-index, index, log.Source.Message)
+			break
+		}
+	}
+	// halving the time since the epoch takes more than 40 requests
+	if requests > 12 {
+		t.Errorf("Expected at most %d requests found %d", 12, requests)
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("Expected no warnings found %q", warnings.String())
+	}
+}
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// auditCodePattern matches a status code like 403 or a class of codes like 5xx.
+var auditCodePattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+// AuditQuery selects audit events in a backend. Empty fields do not restrict
+// the events, an event has to match one of the values of every other field.
+type AuditQuery struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+	// Ascending makes StreamAudit pass the oldest events first.
+	Ascending bool
+	Users     []string
+	Verbs     []string
+	// Resources are resource names like configmaps, without API group.
+	Resources  []string
+	Namespaces []string
+	Names      []string
+	// Codes are response status codes like 404, or classes of them like 5xx.
+	Codes []string
+}
+
+// AuditSearcher is implemented by backends which store the audit logs.
+type AuditSearcher interface {
+	// StreamAudit passes every audit event matching the query to fn, newest
+	// first unless the query is Ascending, one page of at most Limit events at
+	// a time. Returning ErrStop from fn ends the stream without an error.
+	StreamAudit(ctx context.Context, query AuditQuery, fn func(page []logs.AuditEvent) error) error
+}
+
+// ValidateAuditCodes checks the codes are status codes or classes of them.
+func ValidateAuditCodes(codes []string) error {
+
+	for _, code := range codes {
+		if !auditCodePattern.MatchString(strings.ToLower(code)) {
+			return fmt.Errorf("invalid response code \"%s\", a status code like 404 or a class like 5xx is required", code)
+		}
+	}
+	return nil
+}
+
+// auditCodeClass returns the first digit of a class of codes like 5xx, or an
+// empty string for a single code.
+func auditCodeClass(code string) string {
+	code = strings.ToLower(code)
+	if strings.HasSuffix(code, "xx") {
+		return strings.TrimSuffix(code, "xx")
+	}
+	return ""
+}
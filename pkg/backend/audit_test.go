@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestValidateAuditCodes(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Codes    []string
+		Error    string
+	}{
+		{
+			"Codes and classes",
+			[]string{"403", "5xx", "2XX"},
+			"",
+		},
+		{
+			"Invalid class",
+			[]string{"404", "6xx"},
+			"invalid response code \"6xx\", a status code like 404 or a class like 5xx is required",
+		},
+		{
+			"Not a code",
+			[]string{"forbidden"},
+			"invalid response code \"forbidden\", a status code like 404 or a class like 5xx is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		err := ValidateAuditCodes(tt.Codes)
+		if len(tt.Error) == 0 && err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if len(tt.Error) > 0 && (err == nil || err.Error() != tt.Error) {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// Query selects logs in a backend. Empty fields do not restrict the logs.
+type Query struct {
+	Namespace string
+	// Pods restricts the logs to these pods, all pods of the namespace are
+	// queried when it is empty.
+	Pods      []string
+	StartTime time.Time
+	EndTime   time.Time
+	// Levels restricts the logs to these normalized levels. Only backends
+	// implementing LevelSearcher are sent levels.
+	Levels []string
+	Limit  int
+	// Ascending makes Stream pass the oldest logs first.
+	Ascending bool
+	// Messages restricts the logs to those whose message matches every
+	// filter. Only backends implementing MessageSearcher or
+	// MessagePrefilterer are sent filters.
+	Messages []MessageFilter
+	// Containers and ExcludeContainers restrict the logs to the containers
+	// matching one of Containers and none of ExcludeContainers, shell
+	// patterns like istio-*. Only backends implementing ContainerSearcher
+	// are sent patterns.
+	Containers        []string
+	ExcludeContainers []string
+	// Hosts restricts the logs to those of pods running on these nodes. Only
+	// backends implementing HostSearcher are sent hosts.
+	Hosts []string
+	// LogType restricts the logs to application, infrastructure or audit
+	// logs. Only backends implementing LogTypeSearcher are sent a type.
+	LogType string
+	// Units restricts the logs to the journal logs of these systemd units
+	// instead of the logs of pods. Only backends implementing UnitSearcher
+	// are sent units.
+	Units []string
+}
+
+// MessageFilter selects logs by their message. Pattern is a substring, or a
+// regular expression in RE2 syntax when Regexp is set.
+type MessageFilter struct {
+	Pattern    string
+	Regexp     bool
+	IgnoreCase bool
+	// Invert selects the logs whose message does not match.
+	Invert bool
+}
+
+// Expression returns the filter as an unanchored regular expression.
+func (f MessageFilter) Expression() string {
+	expression := f.Pattern
+	if !f.Regexp {
+		expression = regexp.QuoteMeta(expression)
+	}
+	if f.IgnoreCase {
+		expression = "(?i)" + expression
+	}
+	return expression
+}
+
+// Compile returns a function reporting whether a message passes the filter.
+func (f MessageFilter) Compile() (func(message string) bool, error) {
+	expression, err := regexp.Compile(f.Expression())
+	if err != nil {
+		return nil, err
+	}
+	return func(message string) bool {
+		return expression.MatchString(message) != f.Invert
+	}, nil
+}
+
+// LevelSearcher is implemented by backends which can select logs by level in
+// the store. Levels a backend does not search for are selected among the logs
+// it returns instead.
+type LevelSearcher interface {
+	SearchesLevels(levels []string) bool
+}
+
+// MessageSearcher is implemented by backends which can apply message filters
+// in the store. Filters a backend does not search for are applied to the logs
+// it returns instead.
+type MessageSearcher interface {
+	SearchesMessages(filter MessageFilter) bool
+}
+
+// MessagePrefilterer is implemented by backends which can narrow the search
+// down to the logs whose message may match a filter without matching it
+// exactly. The filters are applied to the logs such a backend returns too.
+type MessagePrefilterer interface {
+	PrefiltersMessages(filter MessageFilter) bool
+}
+
+// ContainerSearcher is implemented by backends which can select logs by
+// container in the store. Containers a backend does not search for are
+// selected among the logs it returns instead.
+type ContainerSearcher interface {
+	SearchesContainers(patterns []string) bool
+}
+
+// HostSearcher is implemented by backends which can select logs by the node
+// they were written on in the store. Hosts a backend does not search for are
+// selected among the logs it returns instead.
+type HostSearcher interface {
+	SearchesHosts() bool
+}
+
+// LogTypeSearcher is implemented by backends which can select logs by their
+// type in the store. Types a backend does not search for are selected among
+// the logs it returns instead.
+type LogTypeSearcher interface {
+	SearchesLogTypes() bool
+}
+
+// UnitSearcher is implemented by backends which store the journal logs of
+// nodes and can select them by systemd unit.
+type UnitSearcher interface {
+	SearchesUnits() bool
+}
+
+// PodSearcher is implemented by backends which can list the pods with logs
+// in the store without reading all of their logs.
+type PodSearcher interface {
+	// PodLogs returns one log, the newest, of every pod with logs matching
+	// the query. Limit is ignored.
+	PodLogs(ctx context.Context, query Query) ([]logs.LogOptions, error)
+}
+
+// Backend is a store historical logs are read from.
+type Backend interface {
+	// Query returns the newest logs matching the query. The logs are not
+	// sorted and backends querying one pod at a time may return up to Limit
+	// logs for every pod.
+	Query(ctx context.Context, query Query) ([]logs.LogOptions, error)
+	// Stream passes every log matching the query to fn, newest first unless
+	// the query is Ascending, one page of at most Limit logs at a time.
+	// Returning ErrStop from fn ends the stream without an error.
+	Stream(ctx context.Context, query Query, fn func(page []logs.LogOptions) error) error
+	// Fields lists the fields stored with the logs.
+	Fields(ctx context.Context) ([]string, error)
+	// Namespaces lists the namespaces logs are stored for.
+	Namespaces(ctx context.Context) ([]string, error)
+}
+
+// globExpression returns a shell pattern as an anchored regular expression.
+func globExpression(pattern string) string {
+
+	var expression strings.Builder
+	expression.WriteString("^")
+	for index := 0; index < len(pattern); index++ {
+		switch character := pattern[index]; character {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[index+1:], ']')
+			if end < 0 {
+				expression.WriteString(regexp.QuoteMeta(pattern[index:]))
+				index = len(pattern)
+				break
+			}
+			// classes share their syntax, ^ negates them in both
+			expression.WriteString(pattern[index : index+end+2])
+			index += end + 1
+		case '\\':
+			if index+1 < len(pattern) {
+				index++
+			}
+			expression.WriteString(regexp.QuoteMeta(pattern[index : index+1]))
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(character)))
+		}
+	}
+	expression.WriteString("$")
+	return expression.String()
+}
+
+// ErrStop ends a stream early when returned by the page function.
+var ErrStop = errors.New("stop streaming logs")
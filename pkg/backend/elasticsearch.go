@@ -0,0 +1,626 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// ElasticsearchBackend reads logs directly from the ViaQ indices of an
+// Elasticsearch cluster through its REST API.
+type ElasticsearchBackend struct {
+	HttpClient *http.Client
+	Url        string
+	// Indices is a comma separated list of indices, aliases or patterns.
+	Indices string
+}
+
+func NewElasticsearchBackend(httpClient *http.Client, url string, indices string) *ElasticsearchBackend {
+	if len(indices) == 0 {
+		indices = constants.ESIndices
+	}
+	return &ElasticsearchBackend{HttpClient: httpClient, Url: strings.TrimSuffix(url, "/"), Indices: indices}
+}
+
+type esHit struct {
+	logs.LogOptions
+	Sort []interface{} `json:"sort"`
+}
+
+// UnmarshalJSON reads the sort values of a hit besides the log, whose own
+// UnmarshalJSON would otherwise read the whole hit.
+func (h *esHit) UnmarshalJSON(data []byte) error {
+
+	err := json.Unmarshal(data, &h.LogOptions)
+	if err != nil {
+		return err
+	}
+	sort := struct {
+		Sort []interface{} `json:"sort"`
+	}{}
+	err = json.Unmarshal(data, &sort)
+	if err != nil {
+		return err
+	}
+	h.Sort = sort.Sort
+	return nil
+}
+
+type esSearchResponse struct {
+	Hits struct {
+		Hits []esHit `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		Namespaces struct {
+			Buckets []struct {
+				Key  string `json:"key"`
+				Pods struct {
+					Buckets []struct {
+						Key    string `json:"key"`
+						Newest struct {
+							Hits struct {
+								Hits []esHit `json:"hits"`
+							} `json:"hits"`
+						} `json:"newest"`
+					} `json:"buckets"`
+				} `json:"pods"`
+			} `json:"buckets"`
+		} `json:"namespaces"`
+	} `json:"aggregations"`
+}
+
+type esAuditHit struct {
+	logs.AuditEvent
+	Sort []interface{} `json:"sort"`
+}
+
+type esAuditSearchResponse struct {
+	Hits struct {
+		Hits []esAuditHit `json:"hits"`
+	} `json:"hits"`
+}
+
+type esErrorResponse struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+	Status int `json:"status"`
+}
+
+// Query runs a single search returning the newest Limit logs of all pods.
+func (b *ElasticsearchBackend) Query(ctx context.Context, query Query) ([]logs.LogOptions, error) {
+
+	response, err := b.search(ctx, b.searchBody(query, query.Limit, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	var logList []logs.LogOptions
+	for _, hit := range response.Hits.Hits {
+		logList = append(logList, hit.LogOptions)
+	}
+	return logList, nil
+}
+
+// Stream pages through the logs with search_after, using the timestamp and the
+// ViaQ message id of the last log of a page as the cursor for the next one.
+func (b *ElasticsearchBackend) Stream(ctx context.Context, query Query, fn func(page []logs.LogOptions) error) error {
+
+	pageSize := query.Limit
+	if pageSize <= 0 {
+		pageSize = constants.LimitUpperBound
+	}
+
+	var searchAfter []interface{}
+	for {
+		response, err := b.search(ctx, b.searchBody(query, pageSize, searchAfter))
+		if err != nil {
+			return err
+		}
+
+		hits := response.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+
+		page := make([]logs.LogOptions, 0, len(hits))
+		for _, hit := range hits {
+			page = append(page, hit.LogOptions)
+		}
+		err = fn(page)
+		if err == ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(hits) < pageSize {
+			return nil
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// StreamAudit pages through the audit events of the audit indices with
+// search_after, like Stream.
+func (b *ElasticsearchBackend) StreamAudit(ctx context.Context, query AuditQuery, fn func(page []logs.AuditEvent) error) error {
+
+	pageSize := query.Limit
+	if pageSize <= 0 {
+		pageSize = constants.LimitUpperBound
+	}
+
+	var searchAfter []interface{}
+	for {
+		response := &esAuditSearchResponse{}
+		err := b.searchInto(ctx, b.auditSearchBody(query, pageSize, searchAfter), response)
+		if err != nil {
+			return err
+		}
+
+		hits := response.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+
+		page := make([]logs.AuditEvent, 0, len(hits))
+		for _, hit := range hits {
+			page = append(page, hit.AuditEvent)
+		}
+		err = fn(page)
+		if err == ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(hits) < pageSize {
+			return nil
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// Fields lists the fields of the index mappings as dotted paths.
+func (b *ElasticsearchBackend) Fields(ctx context.Context) ([]string, error) {
+
+	responseBody, err := b.do(ctx, "GET", "/"+b.Indices+"/_mapping?ignore_unavailable=true&allow_no_indices=true", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}{}
+	err = json.Unmarshal(responseBody, &mappings)
+	if err != nil {
+		return nil, newError(ErrorParse, "an error occurred while unmarshalling Elasticsearch mappings: %v", err)
+	}
+
+	fieldSet := map[string]bool{}
+	for _, index := range mappings {
+		properties, ok := index.Mappings["properties"].(map[string]interface{})
+		if !ok {
+			// mappings of Elasticsearch 6 are nested in a document type
+			for _, documentType := range index.Mappings {
+				if typeMapping, ok := documentType.(map[string]interface{}); ok {
+					if typeProperties, ok := typeMapping["properties"].(map[string]interface{}); ok {
+						collectFields(typeProperties, "", fieldSet)
+					}
+				}
+			}
+			continue
+		}
+		collectFields(properties, "", fieldSet)
+	}
+
+	var fields []string
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// Namespaces lists the namespaces found in the indices.
+func (b *ElasticsearchBackend) Namespaces(ctx context.Context) ([]string, error) {
+
+	body := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"namespaces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "kubernetes.namespace_name",
+					"size":  10000,
+				},
+			},
+		},
+	}
+	response, err := b.search(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []string
+	for _, bucket := range response.Aggregations.Namespaces.Buckets {
+		namespaces = append(namespaces, bucket.Key)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// PodLogs returns the newest log of every pod with logs matching the query,
+// found by aggregating the logs on their namespace and pod name.
+func (b *ElasticsearchBackend) PodLogs(ctx context.Context, query Query) ([]logs.LogOptions, error) {
+
+	body := b.searchBody(query, 0, nil)
+	delete(body, "sort")
+	body["aggs"] = map[string]interface{}{
+		"namespaces": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "kubernetes.namespace_name",
+				"size":  10000,
+			},
+			"aggs": map[string]interface{}{
+				"pods": map[string]interface{}{
+					"terms": map[string]interface{}{
+						"field": "kubernetes.pod_name",
+						"size":  10000,
+					},
+					"aggs": map[string]interface{}{
+						"newest": map[string]interface{}{
+							"top_hits": map[string]interface{}{
+								"size": 1,
+								"sort": []interface{}{
+									map[string]interface{}{"@timestamp": "desc"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	response, err := b.search(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var logList []logs.LogOptions
+	for _, namespace := range response.Aggregations.Namespaces.Buckets {
+		for _, pod := range namespace.Pods.Buckets {
+			for _, hit := range pod.Newest.Hits.Hits {
+				logList = append(logList, hit.LogOptions)
+			}
+		}
+	}
+	return logList, nil
+}
+
+// SearchesLevels reports true, any set of levels can be searched for.
+func (b *ElasticsearchBackend) SearchesLevels(levels []string) bool {
+	return true
+}
+
+// PrefiltersMessages reports whether the search can be narrowed down to the
+// logs whose analyzed message field holds the words of the substring. The
+// terms of the field are lowercased and split on punctuation, so the exact
+// substring is matched in the returned logs. Regular expressions and inverted
+// filters are only applied to the returned logs.
+func (b *ElasticsearchBackend) PrefiltersMessages(filter MessageFilter) bool {
+	return !filter.Regexp && !filter.Invert && len(messageWords(filter.Pattern)) > 0
+}
+
+// SearchesContainers reports whether the patterns can be searched for as
+// wildcard queries, which do not support character classes.
+func (b *ElasticsearchBackend) SearchesContainers(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "[") {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchesHosts reports true, hosts are searched for in the node name stored
+// with the logs.
+func (b *ElasticsearchBackend) SearchesHosts() bool {
+	return true
+}
+
+// SearchesLogTypes reports true, the type of a log is the prefix of the index
+// it is stored in.
+func (b *ElasticsearchBackend) SearchesLogTypes() bool {
+	return true
+}
+
+// SearchesUnits reports true, journal logs are stored in the infrastructure
+// indices with their systemd fields.
+func (b *ElasticsearchBackend) SearchesUnits() bool {
+	return true
+}
+
+// searchBody turns the query into a search, with the filters of other fields
+// added.
+func (b *ElasticsearchBackend) searchBody(query Query, size int, searchAfter []interface{}, filters ...interface{}) map[string]interface{} {
+
+	if len(query.Namespace) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"kubernetes.namespace_name": query.Namespace},
+		})
+	}
+	if len(query.Pods) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"kubernetes.pod_name": query.Pods},
+		})
+	}
+	if len(query.Hosts) > 0 {
+		// journal logs record the node as hostname only
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{"terms": map[string]interface{}{"kubernetes.host": query.Hosts}},
+					map[string]interface{}{"terms": map[string]interface{}{"hostname": query.Hosts}},
+				},
+				"minimum_should_match": 1,
+			},
+		})
+	}
+	if len(query.LogType) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"prefix": map[string]interface{}{"_index": logs.LogTypeIndexPrefix(query.LogType)},
+		})
+	}
+	if len(query.Units) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"systemd.t.SYSTEMD_UNIT": query.Units},
+		})
+	}
+	if len(query.Levels) > 0 {
+		filters = append(filters, levelFilter(query.Levels))
+	}
+	if len(query.Containers) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               containerWildcards(query.Containers),
+				"minimum_should_match": 1,
+			},
+		})
+	}
+	mustNot := containerWildcards(query.ExcludeContainers)
+	for _, messageFilter := range query.Messages {
+		if messageFilter.Regexp || messageFilter.Invert {
+			continue
+		}
+		for _, word := range messageWords(messageFilter.Pattern) {
+			filters = append(filters, map[string]interface{}{
+				"wildcard": map[string]interface{}{"message": "*" + word + "*"},
+			})
+		}
+	}
+	timeRange := map[string]interface{}{}
+	if !query.StartTime.IsZero() {
+		timeRange["gte"] = formatTime(query.StartTime)
+	}
+	if !query.EndTime.IsZero() {
+		timeRange["lte"] = formatTime(query.EndTime)
+	}
+	if len(timeRange) > 0 {
+		timeRange["format"] = "strict_date_optional_time"
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"@timestamp": timeRange},
+		})
+	}
+
+	boolQuery := map[string]interface{}{"filter": filters}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
+	}
+
+	order := "desc"
+	if query.Ascending {
+		order = "asc"
+	}
+
+	body := map[string]interface{}{
+		"size": size,
+		"sort": []interface{}{
+			map[string]interface{}{"@timestamp": order},
+			map[string]interface{}{"viaq_msg_id": order},
+		},
+		"query": map[string]interface{}{"bool": boolQuery},
+	}
+	if searchAfter != nil {
+		body["search_after"] = searchAfter
+	}
+	return body
+}
+
+func (b *ElasticsearchBackend) auditSearchBody(query AuditQuery, size int, searchAfter []interface{}) map[string]interface{} {
+
+	filters := []interface{}{
+		map[string]interface{}{
+			"prefix": map[string]interface{}{"_index": logs.LogTypeIndexPrefix(logs.LogTypeAudit)},
+		},
+	}
+	for _, field := range []struct {
+		name   string
+		values []string
+	}{
+		{"user.username", query.Users},
+		{"verb", query.Verbs},
+		{"objectRef.resource", query.Resources},
+		{"objectRef.namespace", query.Namespaces},
+		{"objectRef.name", query.Names},
+	} {
+		if len(field.values) > 0 {
+			filters = append(filters, map[string]interface{}{
+				"terms": map[string]interface{}{field.name: field.values},
+			})
+		}
+	}
+	if len(query.Codes) > 0 {
+		var codes []interface{}
+		for _, code := range query.Codes {
+			if class := auditCodeClass(code); len(class) > 0 {
+				codes = append(codes, map[string]interface{}{
+					"range": map[string]interface{}{
+						"responseStatus.code": map[string]interface{}{"gte": class + "00", "lte": class + "99"},
+					},
+				})
+				continue
+			}
+			codes = append(codes, map[string]interface{}{
+				"term": map[string]interface{}{"responseStatus.code": code},
+			})
+		}
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               codes,
+				"minimum_should_match": 1,
+			},
+		})
+	}
+
+	return b.searchBody(Query{
+		StartTime: query.StartTime,
+		EndTime:   query.EndTime,
+		Ascending: query.Ascending,
+	}, size, searchAfter, filters...)
+}
+
+// containerWildcards matches the container names with the patterns.
+func containerWildcards(patterns []string) []interface{} {
+
+	var wildcards []interface{}
+	for _, pattern := range patterns {
+		wildcards = append(wildcards, map[string]interface{}{
+			"wildcard": map[string]interface{}{"kubernetes.container_name": pattern},
+		})
+	}
+	return wildcards
+}
+
+// messageWords returns the lowercased runs of ASCII letters and digits of a
+// substring. The standard analyzer never splits such a run, so every log
+// containing the substring has a term of its message containing each run.
+func messageWords(pattern string) []string {
+
+	return strings.FieldsFunc(strings.ToLower(pattern), func(character rune) bool {
+		return !(character >= 'a' && character <= 'z' || character >= '0' && character <= '9')
+	})
+}
+
+// levelFilter matches the values stored for the levels in the usual cases.
+// Unknown also matches logs without a level.
+func levelFilter(levels []string) map[string]interface{} {
+
+	var values []string
+	unknown := false
+	for _, level := range levels {
+		unknown = unknown || level == logs.LevelUnknown
+		for _, alias := range logs.LevelAliases(level) {
+			values = append(values, alias, strings.ToUpper(alias), strings.ToUpper(alias[:1])+alias[1:])
+		}
+	}
+
+	terms := map[string]interface{}{
+		"terms": map[string]interface{}{"level": values},
+	}
+	if !unknown {
+		return terms
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []interface{}{
+				terms,
+				map[string]interface{}{
+					"bool": map[string]interface{}{
+						"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "level"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *ElasticsearchBackend) search(ctx context.Context, body map[string]interface{}) (*esSearchResponse, error) {
+	response := &esSearchResponse{}
+	return response, b.searchInto(ctx, body, response)
+}
+
+// searchInto runs a search and unmarshals its response into response.
+func (b *ElasticsearchBackend) searchInto(ctx context.Context, body map[string]interface{}, response interface{}) error {
+
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("an error occurred while marshalling Elasticsearch query: %v", err)
+	}
+
+	responseBody, err := b.do(ctx, "POST", "/"+b.Indices+"/_search?ignore_unavailable=true&allow_no_indices=true", requestBody)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(responseBody, response)
+	if err != nil {
+		return newError(ErrorParse, "an error occurred while unmarshalling Elasticsearch response: %v", err)
+	}
+	return nil
+}
+
+func (b *ElasticsearchBackend) do(ctx context.Context, method string, path string, requestBody []byte) ([]byte, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, b.Url+path, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, newError(ErrorUnknown, "unable to query Elasticsearch - http request failed: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// authentication headers are added by the transport of HttpClient
+	response, err := b.HttpClient.Do(req)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to query Elasticsearch - failed to get http response %v", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to query Elasticsearch - failed to read response: %v", err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		errorResponse := &esErrorResponse{}
+		if json.Unmarshal(responseBody, errorResponse) == nil && len(errorResponse.Error.Reason) > 0 {
+			return nil, newError(statusErrorKind(response.StatusCode), "unable to query Elasticsearch - a server-side error occurred: %s: %s", errorResponse.Error.Type, errorResponse.Error.Reason)
+		}
+		return nil, newError(statusErrorKind(response.StatusCode), "unable to query Elasticsearch - a server-side error occurred: %s", response.Status)
+	}
+	return responseBody, nil
+}
+
+func collectFields(properties map[string]interface{}, prefix string, fieldSet map[string]bool) {
+
+	for name, property := range properties {
+		field, ok := property.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if nested, ok := field["properties"].(map[string]interface{}); ok {
+			collectFields(nested, prefix+name+".", fieldSet)
+			continue
+		}
+		fieldSet[prefix+name] = true
+	}
+}
@@ -0,0 +1,281 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/jarcoal/httpmock"
+)
+
+const esSearchUrl = "http://localhost:9200/app-*,infra-*,audit-*/_search?ignore_unavailable=true&allow_no_indices=true"
+
+func TestElasticsearchBackendQuery(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", esSearchUrl,
+		func(req *http.Request) (*http.Response, error) {
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			for _, expected := range []string{
+				`{"term":{"kubernetes.namespace_name":"openshift-logging"}}`,
+				`{"terms":{"kubernetes.pod_name":["pod-1","pod-2"]}}`,
+				`{"bool":{"minimum_should_match":1,"should":[{"terms":{"kubernetes.host":["worker-0"]}},{"terms":{"hostname":["worker-0"]}}]}}`,
+				`{"prefix":{"_index":"infra-"}}`,
+				`{"terms":{"systemd.t.SYSTEMD_UNIT":["kubelet.service"]}}`,
+				`{"terms":{"level":["err","ERR","Err","error","ERROR","Error","unknown","UNKNOWN","Unknown"]}}`,
+				`{"bool":{"must_not":{"exists":{"field":"level"}}}}`,
+				`{"range":{"@timestamp":{"format":"strict_date_optional_time","gte":"2021-03-18T06:00:00Z"}}}`,
+				`"size":5`,
+				`{"wildcard":{"message":"*configmaps*"}},{"wildcard":{"message":"*kube*"}},{"wildcard":{"message":"*root*"}}`,
+				`{"bool":{"minimum_should_match":1,"should":[{"wildcard":{"kubernetes.container_name":"app"}},{"wildcard":{"kubernetes.container_name":"web-*"}}]}}`,
+				`"must_not":[{"wildcard":{"kubernetes.container_name":"istio-proxy"}}]}`,
+			} {
+				if !strings.Contains(string(requestBody), expected) {
+					t.Errorf("Expected %s in search %s", expected, requestBody)
+				}
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[
+				{"_id":"2","_source":{"message":"second","kubernetes":{"pod_name":"pod-2"}},"sort":[2,"2"]},
+				{"_id":"1","_source":{"message":"first","kubernetes":{"pod_name":"pod-1"}},"sort":[1,"1"]}
+			]}}`), nil
+		})
+
+	esBackend := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200/", "")
+	logList, err := esBackend.Query(context.Background(), Query{
+		Namespace:         "openshift-logging",
+		Pods:              []string{"pod-1", "pod-2"},
+		StartTime:         time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC),
+		Levels:            []string{"err", "unknown"},
+		Limit:             5,
+		Messages:          []MessageFilter{{Pattern: "ConfigMaps/kube-root"}, {Pattern: "healthz", Invert: true}},
+		Containers:        []string{"app", "web-*"},
+		ExcludeContainers: []string{"istio-proxy"},
+		Hosts:             []string{"worker-0"},
+		LogType:           logs.LogTypeInfrastructure,
+		Units:             []string{"kubelet.service"},
+	})
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(logList) != 2 || logList[0].ID != "2" || logList[1].Source.Kubernetes.PodName != "pod-1" {
+		t.Errorf("Expected logs %v found %v", []string{"2", "1"}, logList)
+	}
+}
+
+func TestElasticsearchBackendStream(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", esSearchUrl,
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Size        int           `json:"size"`
+				SearchAfter []interface{} `json:"search_after"`
+			}{}
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(requestBody, &body)
+
+			// five logs with descending sort values, paged by search_after
+			next := 5
+			if len(body.SearchAfter) > 0 {
+				next = int(body.SearchAfter[0].(float64)) - 1
+			}
+			var hits []string
+			for value := next; value > 0 && len(hits) < body.Size; value-- {
+				hits = append(hits, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%d"},"sort":[%d,"%d"]}`, value, value, value, value))
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[`+strings.Join(hits, ",")+`]}}`), nil
+		})
+
+	esBackend := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	var pages [][]string
+	err := esBackend.Stream(context.Background(), Query{Limit: 2}, func(page []logs.LogOptions) error {
+		var messages []string
+		for _, log := range page {
+			messages = append(messages, log.Source.Message)
+		}
+		pages = append(pages, messages)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	expected := [][]string{{"5", "4"}, {"3", "2"}, {"1"}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("Expected pages %v found %v", expected, pages)
+	}
+}
+
+func TestElasticsearchBackendStreamAudit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", esSearchUrl,
+		func(req *http.Request) (*http.Response, error) {
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			for _, expected := range []string{
+				`{"prefix":{"_index":"audit-"}}`,
+				`{"terms":{"user.username":["system:admin"]}}`,
+				`{"terms":{"verb":["delete","patch"]}}`,
+				`{"terms":{"objectRef.resource":["configmaps"]}}`,
+				`{"terms":{"objectRef.namespace":["project-a"]}}`,
+				`{"bool":{"minimum_should_match":1,"should":[{"term":{"responseStatus.code":"403"}},{"range":{"responseStatus.code":{"gte":"500","lte":"599"}}}]}}`,
+				`"size":10`,
+			} {
+				if !strings.Contains(string(requestBody), expected) {
+					t.Errorf("Expected %s in search %s", expected, requestBody)
+				}
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[
+				{"_id":"1","_index":"audit-000001","_source":{"@timestamp":"2021-03-18T06:00:00Z","verb":"delete","user":{"username":"system:admin"},"objectRef":{"resource":"configmaps","namespace":"project-a","name":"settings"},"responseStatus":{"code":403}},"sort":[1,"1"]}
+			]}}`), nil
+		})
+
+	esBackend := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	var events []logs.AuditEvent
+	err := esBackend.StreamAudit(context.Background(), AuditQuery{
+		Limit:      10,
+		Users:      []string{"system:admin"},
+		Verbs:      []string{"delete", "patch"},
+		Resources:  []string{"configmaps"},
+		Namespaces: []string{"project-a"},
+		Codes:      []string{"403", "5xx"},
+	}, func(page []logs.AuditEvent) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(events) != 1 || events[0].Username() != "system:admin" || events[0].Name() != "settings" || events[0].Code() != "403" {
+		t.Errorf("Expected the event deleting configmap settings, found %+v", events)
+	}
+}
+
+func TestElasticsearchBackendNamespaces(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", esSearchUrl,
+		httpmock.NewStringResponder(200, `{"hits":{"hits":[]},"aggregations":{"namespaces":{"buckets":[{"key":"openshift-logging"},{"key":"default"}]}}}`))
+
+	namespaces, err := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "").Namespaces(context.Background())
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if !reflect.DeepEqual(namespaces, []string{"default", "openshift-logging"}) {
+		t.Errorf("Expected namespaces %v found %v", []string{"default", "openshift-logging"}, namespaces)
+	}
+}
+
+func TestElasticsearchBackendPodLogs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", esSearchUrl,
+		func(req *http.Request) (*http.Response, error) {
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			for _, expected := range []string{
+				`"size":0`,
+				`"terms":{"field":"kubernetes.pod_name","size":10000}`,
+				`"top_hits":{"size":1,"sort":[{"@timestamp":"desc"}]}`,
+				`{"term":{"kubernetes.namespace_name":"openshift-logging"}}`,
+			} {
+				if !strings.Contains(string(requestBody), expected) {
+					t.Errorf("Expected %s in search %s", expected, requestBody)
+				}
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[]},"aggregations":{"namespaces":{"buckets":[{"key":"openshift-logging","pods":{"buckets":[`+
+				`{"key":"kibana-1","newest":{"hits":{"hits":[{"_id":"1","_source":{"kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-1"}}}]}}},`+
+				`{"key":"kibana-2","newest":{"hits":{"hits":[{"_id":"2","_source":{"kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-2"}}}]}}}]}}]}}}`), nil
+		})
+
+	logList, err := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "").PodLogs(context.Background(), Query{Namespace: "openshift-logging", Limit: 10})
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	var pods []string
+	for _, log := range logList {
+		pods = append(pods, log.Source.Kubernetes.PodName)
+	}
+	if !reflect.DeepEqual(pods, []string{"kibana-1", "kibana-2"}) {
+		t.Errorf("Expected pods %v found %v", []string{"kibana-1", "kibana-2"}, pods)
+	}
+}
+
+func TestElasticsearchBackendFields(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:9200/app-*/_mapping?ignore_unavailable=true&allow_no_indices=true",
+		httpmock.NewStringResponder(200, `{
+			"app-000001":{"mappings":{"properties":{"message":{"type":"text"},"kubernetes":{"properties":{"pod_name":{"type":"keyword"}}}}}},
+			"app-000002":{"mappings":{"_doc":{"properties":{"level":{"type":"keyword"},"message":{"type":"text"}}}}}
+		}`))
+
+	fields, err := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "app-*").Fields(context.Background())
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	expected := []string{"kubernetes.pod_name", "level", "message"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields %v found %v", expected, fields)
+	}
+}
+
+func TestElasticsearchBackendError(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Status   int
+		Body     string
+		Error    string
+		Kind     ErrorKind
+	}{
+		{
+			"Invalid query",
+			400,
+			`{"error":{"type":"search_phase_execution_exception","reason":"all shards failed"},"status":400}`,
+			"unable to query Elasticsearch - a server-side error occurred: search_phase_execution_exception: all shards failed",
+			ErrorUnknown,
+		},
+		{
+			"Missing permission",
+			403,
+			`{"error":{"type":"security_exception","reason":"no permissions for [indices:data/read/search]"},"status":403}`,
+			"unable to query Elasticsearch - a server-side error occurred: security_exception: no permissions for [indices:data/read/search]",
+			ErrorAuth,
+		},
+		{
+			"Cluster unavailable",
+			503,
+			``,
+			"unable to query Elasticsearch - a server-side error occurred: 503",
+			ErrorUnavailable,
+		},
+		{
+			"Invalid response",
+			200,
+			`<html>`,
+			"an error occurred while unmarshalling Elasticsearch response: invalid character '<' looking for beginning of value",
+			ErrorParse,
+		},
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		httpmock.RegisterResponder("POST", esSearchUrl, httpmock.NewStringResponder(tt.Status, tt.Body))
+
+		_, err := NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "").Query(context.Background(), Query{Limit: 5})
+		if err == nil || err.Error() != tt.Error {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if ErrorKindOf(err) != tt.Kind {
+			t.Errorf("Expected error kind %s found %s", tt.Kind, ErrorKindOf(err))
+		}
+	}
+}
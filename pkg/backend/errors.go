@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrorKind classifies why a backend failed.
+type ErrorKind string
+
+const (
+	ErrorAuth        ErrorKind = "auth"
+	ErrorNotFound    ErrorKind = "not-found"
+	ErrorUnavailable ErrorKind = "backend-unavailable"
+	ErrorParse       ErrorKind = "parse"
+	ErrorTimeout     ErrorKind = "timeout"
+	ErrorUnknown     ErrorKind = "unknown"
+)
+
+// Error is an error returned by a backend together with its kind.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf returns the kind of an error returned by a backend. Expired
+// deadlines are timeouts, other errors are of unknown kind.
+func ErrorKindOf(err error) ErrorKind {
+	var backendErr *Error
+	if errors.As(err, &backendErr) {
+		return backendErr.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	return ErrorUnknown
+}
+
+func newError(kind ErrorKind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// requestErrorKind classifies an error sending a request or reading its
+// response.
+func requestErrorKind(err error) ErrorKind {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTimeout
+	}
+	return ErrorUnavailable
+}
+
+// statusErrorKind classifies an error status of a response.
+func statusErrorKind(statusCode int) ErrorKind {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorAuth
+	case statusCode == http.StatusNotFound:
+		return ErrorNotFound
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return ErrorTimeout
+	case statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError:
+		return ErrorUnavailable
+	}
+	return ErrorUnknown
+}
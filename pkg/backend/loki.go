@@ -0,0 +1,563 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// Stream labels set on the logs by the OpenShift Logging collector.
+const (
+	lokiNamespaceLabel = "kubernetes_namespace_name"
+	lokiPodLabel       = "kubernetes_pod_name"
+	lokiContainerLabel = "kubernetes_container_name"
+	lokiHostLabel      = "kubernetes_host"
+	// lokiUnitLabel is the systemd unit parsed from the JSON line of a
+	// journal log.
+	lokiUnitLabel = "systemd_t_SYSTEMD_UNIT"
+)
+
+// LokiBackend reads logs from a LokiStack through its gateway, which serves
+// the Loki HTTP API of every tenant below /api/logs/v1/<tenant>.
+type LokiBackend struct {
+	HttpClient *http.Client
+	Url        string
+	// Tenant is one of application, infrastructure or audit. When empty the
+	// tenant is chosen from the log type, or the namespace, of the query.
+	Tenant string
+}
+
+func NewLokiBackend(httpClient *http.Client, url string, tenant string) *LokiBackend {
+	return &LokiBackend{HttpClient: httpClient, Url: strings.TrimSuffix(url, "/"), Tenant: tenant}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiQueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string       `json:"resultType"`
+		Result     []lokiStream `json:"result"`
+	} `json:"data"`
+}
+
+type lokiLabelsResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+}
+
+// Query runs a single query_range request returning the newest Limit logs of
+// all pods. Without a limit the whole range is paged through like Stream does,
+// as Loki would apply a default limit of its own.
+func (b *LokiBackend) Query(ctx context.Context, query Query) ([]logs.LogOptions, error) {
+
+	start, end := b.timeRange(query.StartTime, query.EndTime)
+	if query.Limit <= 0 {
+		var entries []lokiEntry
+		err := b.streamRange(ctx, b.tenant(query), LogQL(query), start, end, constants.LimitUpperBound, false, func(page []lokiEntry) error {
+			entries = append(entries, page...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return lokiLogs(entries), nil
+	}
+
+	entries, err := b.queryRange(ctx, b.tenant(query), LogQL(query), start, end, query.Limit, false)
+	if err != nil {
+		return nil, err
+	}
+	return lokiLogs(entries), nil
+}
+
+// Stream pages through the logs like streamRange.
+func (b *LokiBackend) Stream(ctx context.Context, query Query, fn func(page []logs.LogOptions) error) error {
+
+	start, end := b.timeRange(query.StartTime, query.EndTime)
+	return b.streamRange(ctx, b.tenant(query), LogQL(query), start, end, query.Limit, query.Ascending, func(page []lokiEntry) error {
+		return fn(lokiLogs(page))
+	})
+}
+
+// StreamAudit pages through the audit events of the audit tenant like
+// streamRange.
+func (b *LokiBackend) StreamAudit(ctx context.Context, query AuditQuery, fn func(page []logs.AuditEvent) error) error {
+
+	start, end := b.timeRange(query.StartTime, query.EndTime)
+	return b.streamRange(ctx, logs.LogTypeAudit, AuditLogQL(query), start, end, query.Limit, query.Ascending, func(page []lokiEntry) error {
+		events := make([]logs.AuditEvent, 0, len(page))
+		for _, entry := range page {
+			events = append(events, lokiAuditEvent(entry))
+		}
+		return fn(events)
+	})
+}
+
+// streamRange passes the entries of a range to fn one page at a time, using the
+// timestamp of the last entry of a page as the bound of the next one. Loki
+// includes the start and excludes the end of a range, so a page ends one
+// nanosecond after the oldest entry of the previous one, or starts at the
+// newest, and the entries of that instant which were already passed on are
+// skipped.
+func (b *LokiBackend) streamRange(ctx context.Context, tenant string, logQL string, start time.Time, end time.Time, pageSize int, ascending bool, fn func(page []lokiEntry) error) error {
+
+	if pageSize <= 0 {
+		pageSize = constants.LimitUpperBound
+	}
+
+	var boundary time.Time
+	boundaryKeys := map[string]bool{}
+	for {
+		entries, err := b.queryRange(ctx, tenant, logQL, start, end, pageSize, ascending)
+		if err != nil {
+			return err
+		}
+
+		var page []lokiEntry
+		for _, entry := range entries {
+			if entry.timestamp.Equal(boundary) && boundaryKeys[entry.key()] {
+				continue
+			}
+			page = append(page, entry)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+
+		last := page[len(page)-1].timestamp
+		if !last.Equal(boundary) {
+			boundary = last
+			boundaryKeys = map[string]bool{}
+		}
+		for _, entry := range page {
+			if entry.timestamp.Equal(boundary) {
+				boundaryKeys[entry.key()] = true
+			}
+		}
+
+		err = fn(page)
+		if err == ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(entries) < pageSize {
+			return nil
+		}
+		if ascending {
+			start = boundary
+		} else {
+			end = boundary.Add(time.Nanosecond)
+		}
+	}
+}
+
+// Fields lists the stream labels of the tenant.
+func (b *LokiBackend) Fields(ctx context.Context) ([]string, error) {
+	return b.labels(ctx, "/loki/api/v1/labels")
+}
+
+// Namespaces lists the values of the namespace stream label of the tenant.
+func (b *LokiBackend) Namespaces(ctx context.Context) ([]string, error) {
+	return b.labels(ctx, "/loki/api/v1/label/"+lokiNamespaceLabel+"/values")
+}
+
+// SearchesLevels reports true, any set of levels can be searched for.
+func (b *LokiBackend) SearchesLevels(levels []string) bool {
+	return true
+}
+
+// SearchesMessages reports true, every filter is matched against the message
+// parsed from the JSON log lines.
+func (b *LokiBackend) SearchesMessages(filter MessageFilter) bool {
+	return true
+}
+
+// SearchesContainers reports true, patterns are matched against the container
+// stream label.
+func (b *LokiBackend) SearchesContainers(patterns []string) bool {
+	return true
+}
+
+// SearchesHosts reports true, hosts are matched against the node stream label.
+func (b *LokiBackend) SearchesHosts() bool {
+	return true
+}
+
+// SearchesLogTypes reports true, every log type is stored in a tenant of its
+// own.
+func (b *LokiBackend) SearchesLogTypes() bool {
+	return true
+}
+
+// SearchesUnits reports true, units are matched against the systemd unit
+// parsed from the JSON lines of the journal logs.
+func (b *LokiBackend) SearchesUnits() bool {
+	return true
+}
+
+// LogQL turns the query into a stream selector for the namespace, the pods,
+// the containers and the hosts, followed by line filters narrowing the JSON
+// log lines down to those which may hold a matching message, and by filters
+// on the unit, the level and the message parsed from the lines.
+func LogQL(query Query) string {
+
+	var matchers []string
+	if len(query.Namespace) > 0 {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", lokiNamespaceLabel, query.Namespace))
+	}
+	if len(query.Pods) > 0 {
+		var pods []string
+		for _, pod := range query.Pods {
+			pods = append(pods, regexp.QuoteMeta(pod))
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", lokiPodLabel, strings.Join(pods, "|")))
+	}
+	if len(query.Containers) > 0 {
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", lokiContainerLabel, globsExpression(query.Containers)))
+	}
+	if len(query.ExcludeContainers) > 0 {
+		matchers = append(matchers, fmt.Sprintf("%s!~%q", lokiContainerLabel, globsExpression(query.ExcludeContainers)))
+	}
+	if len(query.Hosts) > 0 {
+		var hosts []string
+		for _, host := range query.Hosts {
+			hosts = append(hosts, regexp.QuoteMeta(host))
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", lokiHostLabel, strings.Join(hosts, "|")))
+	}
+	if len(matchers) == 0 && len(query.Units) > 0 {
+		// journal logs have no namespace but the node they were written on
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", lokiHostLabel, ".+"))
+	}
+	if len(matchers) == 0 {
+		// Loki rejects selectors without a matcher which requires a value
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", lokiNamespaceLabel, ".+"))
+	}
+
+	logQL := "{" + strings.Join(matchers, ", ") + "}"
+	for _, messageFilter := range query.Messages {
+		if lineFilter, ok := lokiLineFilter(messageFilter); ok {
+			logQL += lineFilter
+		}
+	}
+	if len(query.Units) > 0 || len(query.Levels) > 0 || len(query.Messages) > 0 {
+		logQL += " | json"
+	}
+	if len(query.Units) > 0 {
+		var units []string
+		for _, unit := range query.Units {
+			units = append(units, regexp.QuoteMeta(unit))
+		}
+		logQL += fmt.Sprintf(" | %s=~%q", lokiUnitLabel, strings.Join(units, "|"))
+	}
+	if len(query.Levels) > 0 {
+		var values []string
+		for _, level := range query.Levels {
+			for _, alias := range logs.LevelAliases(level) {
+				values = append(values, regexp.QuoteMeta(alias))
+			}
+			if level == logs.LevelUnknown {
+				// logs without a level have an empty level label
+				values = append(values, "")
+			}
+		}
+		logQL += fmt.Sprintf(" | level=~%q", "(?i)(?:"+strings.Join(values, "|")+")")
+	}
+	for _, messageFilter := range query.Messages {
+		operator := "=~"
+		if messageFilter.Invert {
+			operator = "!~"
+		}
+		// label filters are anchored, the expression may match anywhere in the message
+		logQL += fmt.Sprintf(" | message%s%q", operator, "(?s).*(?:"+messageFilter.Expression()+").*")
+	}
+	return logQL
+}
+
+// lokiLineFilter returns the line filter searching the JSON log lines for the
+// substring of a message filter, which Loki applies before parsing the lines.
+// The message filter is still applied to the parsed message: only substrings
+// JSON encodes as they are can be searched for in the lines, and inverted
+// filters and regular expressions, which may match other fields of a line or
+// be anchored to the message, are not searched for in them.
+func lokiLineFilter(filter MessageFilter) (string, bool) {
+
+	if filter.Regexp || filter.Invert || len(filter.Pattern) == 0 {
+		return "", false
+	}
+	for _, character := range filter.Pattern {
+		if character < 0x20 || character > 0x7e || strings.ContainsRune("\"\\<>&", character) {
+			return "", false
+		}
+	}
+	if filter.IgnoreCase {
+		return fmt.Sprintf(" |~ %q", filter.Expression()), true
+	}
+	return fmt.Sprintf(" |= %q", filter.Pattern), true
+}
+
+// AuditLogQL returns the LogQL query selecting the audit events of a query.
+// The fields of the events are only known once the lines are parsed, the
+// collector labels every stream with the node though.
+func AuditLogQL(query AuditQuery) string {
+
+	logQL := fmt.Sprintf("{%s=~%q} | json", lokiHostLabel, ".+")
+	for _, filter := range []struct {
+		label  string
+		values []string
+	}{
+		{"user_username", query.Users},
+		{"verb", query.Verbs},
+		{"objectRef_resource", query.Resources},
+		{"objectRef_namespace", query.Namespaces},
+		{"objectRef_name", query.Names},
+	} {
+		if len(filter.values) == 0 {
+			continue
+		}
+		var values []string
+		for _, value := range filter.values {
+			values = append(values, regexp.QuoteMeta(value))
+		}
+		logQL += fmt.Sprintf(" | %s=~%q", filter.label, strings.Join(values, "|"))
+	}
+	if len(query.Codes) > 0 {
+		var codes []string
+		for _, code := range query.Codes {
+			if class := auditCodeClass(code); len(class) > 0 {
+				codes = append(codes, class+"[0-9]{2}")
+			} else {
+				codes = append(codes, code)
+			}
+		}
+		logQL += fmt.Sprintf(" | responseStatus_code=~%q", strings.Join(codes, "|"))
+	}
+	return logQL
+}
+
+// tenant returns the tenant of the log type of the query, the configured
+// tenant or the one the collector forwards the logs of the namespace to.
+func (b *LokiBackend) tenant(query Query) string {
+	if len(query.LogType) > 0 {
+		return query.LogType
+	}
+	if len(b.Tenant) > 0 {
+		return b.Tenant
+	}
+	namespace := query.Namespace
+	if namespace == "default" || strings.HasPrefix(namespace, "openshift") || strings.HasPrefix(namespace, "kube") {
+		return constants.LokiTenantInfrastructure
+	}
+	return constants.LokiTenantApplication
+}
+
+// timeRange returns the range of a query as Loki expects it, with an
+// exclusive end. Without a start the range reaches constants.LokiLookback
+// back from the end.
+func (b *LokiBackend) timeRange(start time.Time, end time.Time) (time.Time, time.Time) {
+
+	if end.IsZero() {
+		end = time.Now().UTC()
+	}
+	if start.IsZero() {
+		start = end.Add(-constants.LokiLookback)
+	}
+	return start, end.Add(time.Nanosecond)
+}
+
+// queryRange returns at most limit entries of a range in the direction of the
+// query. The limit is always sent, Loki applies a default of its own otherwise.
+func (b *LokiBackend) queryRange(ctx context.Context, tenant string, logQL string, start time.Time, end time.Time, limit int, ascending bool) ([]lokiEntry, error) {
+
+	direction := "backward"
+	if ascending {
+		direction = "forward"
+	}
+
+	parameters := url.Values{}
+	parameters.Set("query", logQL)
+	parameters.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	parameters.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	parameters.Set("direction", direction)
+	parameters.Set("limit", strconv.Itoa(limit))
+
+	responseBody, err := b.do(ctx, tenant, "/loki/api/v1/query_range", parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &lokiQueryResponse{}
+	err = json.Unmarshal(responseBody, response)
+	if err != nil {
+		return nil, newError(ErrorParse, "an error occurred while unmarshalling Loki response: %v", err)
+	}
+	if response.Data.ResultType != "streams" {
+		return nil, newError(ErrorParse, "unable to query Loki - unexpected result type \"%s\"", response.Data.ResultType)
+	}
+
+	var entries []lokiEntry
+	for _, stream := range response.Data.Result {
+		for _, value := range stream.Values {
+			nanoseconds, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, newError(ErrorParse, "unable to query Loki - invalid log timestamp \"%s\": %v", value[0], err)
+			}
+			entries = append(entries, lokiEntry{labels: stream.Stream, timestamp: time.Unix(0, nanoseconds).UTC(), line: value[1]})
+		}
+	}
+
+	// every stream is sorted on its own, the entries of all streams are
+	// sorted in the direction of the query
+	sort.SliceStable(entries, func(index1, index2 int) bool {
+		if ascending {
+			return entries[index1].timestamp.Before(entries[index2].timestamp)
+		}
+		return entries[index1].timestamp.After(entries[index2].timestamp)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (b *LokiBackend) labels(ctx context.Context, path string) ([]string, error) {
+
+	responseBody, err := b.do(ctx, b.tenant(Query{}), path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &lokiLabelsResponse{}
+	err = json.Unmarshal(responseBody, response)
+	if err != nil {
+		return nil, newError(ErrorParse, "an error occurred while unmarshalling Loki response: %v", err)
+	}
+	sort.Strings(response.Data)
+	return response.Data, nil
+}
+
+func (b *LokiBackend) do(ctx context.Context, tenant string, path string, parameters url.Values) ([]byte, error) {
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.Url+"/api/logs/v1/"+tenant+path, nil)
+	if err != nil {
+		return nil, newError(ErrorUnknown, "unable to query Loki - http request failed: %v", err)
+	}
+	req.URL.RawQuery = parameters.Encode()
+
+	// the bearer token is added by the transport of HttpClient
+	response, err := b.HttpClient.Do(req)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to query Loki - failed to get http response %v", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, newError(requestErrorKind(err), "unable to query Loki - failed to read response: %v", err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		message := strings.TrimSpace(string(responseBody))
+		if len(message) == 0 {
+			message = response.Status
+		}
+		return nil, newError(statusErrorKind(response.StatusCode), "unable to query Loki - a server-side error occurred: %s", message)
+	}
+	return responseBody, nil
+}
+
+// lokiEntry is a log line of a stream.
+type lokiEntry struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+}
+
+// key identifies an entry among the entries of the same instant.
+func (e lokiEntry) key() string {
+	// maps are printed sorted by key
+	return fmt.Sprint(e.labels) + "\n" + e.line
+}
+
+// lokiLog maps a log line to the ViaQ data model. The collector forwards the
+// whole ViaQ record as the line, lines which are not JSON become the message.
+// The stream labels fill in the Kubernetes metadata missing from the record.
+func lokiLog(entry lokiEntry) logs.LogOptions {
+
+	log, err := logs.ParseSource([]byte(entry.line))
+	if err != nil {
+		log.Source.Message = entry.line
+	}
+	log.Source.Timestamp = entry.timestamp
+
+	kubernetes := &log.Source.Kubernetes
+	if len(kubernetes.NamespaceName) == 0 {
+		kubernetes.NamespaceName = entry.labels[lokiNamespaceLabel]
+	}
+	if len(kubernetes.PodName) == 0 {
+		kubernetes.PodName = entry.labels[lokiPodLabel]
+	}
+	if len(kubernetes.ContainerName) == 0 {
+		kubernetes.ContainerName = entry.labels[lokiContainerLabel]
+	}
+	if len(kubernetes.Host) == 0 {
+		kubernetes.Host = entry.labels[lokiHostLabel]
+	}
+	if len(log.Source.Level) == 0 {
+		log.Source.Level = entry.labels["level"]
+	}
+	return log
+}
+
+func lokiLogs(entries []lokiEntry) []logs.LogOptions {
+	var logList []logs.LogOptions
+	for _, entry := range entries {
+		logList = append(logList, lokiLog(entry))
+	}
+	return logList
+}
+
+// lokiAuditEvent maps a line of the audit tenant to an audit event, lines
+// which are not JSON become the message.
+func lokiAuditEvent(entry lokiEntry) logs.AuditEvent {
+
+	event := logs.AuditEvent{}
+	err := json.Unmarshal([]byte(entry.line), &event.Source)
+	if err != nil {
+		event = logs.AuditEvent{}
+		event.Source.Message = entry.line
+	}
+	event.Source.Timestamp = entry.timestamp
+	if len(event.Source.Hostname) == 0 {
+		event.Source.Hostname = entry.labels[lokiHostLabel]
+	}
+	return event
+}
+
+// globsExpression returns a regular expression matching any of the patterns.
+func globsExpression(patterns []string) string {
+
+	var expressions []string
+	for _, pattern := range patterns {
+		expressions = append(expressions, globExpression(pattern))
+	}
+	return strings.Join(expressions, "|")
+}
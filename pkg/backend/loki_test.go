@@ -0,0 +1,418 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+type fakeLokiEntry struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+}
+
+// fakeLoki serves the query_range endpoint of a LokiStack gateway from the
+// entries, matching the pods of the stream selector of the LogQL query only.
+func fakeLoki(entries []fakeLokiEntry, requests *[]*http.Request) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/api/logs/v1/application/loki/api/v1/label/kubernetes_namespace_name/values" {
+			fmt.Fprint(w, `{"status":"success","data":["project-b","project-a"]}`)
+			return
+		}
+		if r.URL.Path != "/api/logs/v1/application/loki/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		start, _ := strconv.ParseInt(query.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(query.Get("end"), 10, 64)
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		var matched []fakeLokiEntry
+		for _, entry := range entries {
+			nanoseconds := entry.timestamp.UnixNano()
+			if nanoseconds < start || nanoseconds >= end {
+				continue
+			}
+			if !strings.Contains(query.Get("query"), entry.labels["kubernetes_pod_name"]) {
+				continue
+			}
+			matched = append(matched, entry)
+		}
+		sort.SliceStable(matched, func(index1, index2 int) bool {
+			if query.Get("direction") == "forward" {
+				return matched[index1].timestamp.Before(matched[index2].timestamp)
+			}
+			return matched[index1].timestamp.After(matched[index2].timestamp)
+		})
+		if len(matched) > limit {
+			matched = matched[:limit]
+		}
+
+		var result []lokiStream
+		for _, entry := range matched {
+			result = append(result, lokiStream{
+				Stream: entry.labels,
+				Values: [][2]string{{strconv.FormatInt(entry.timestamp.UnixNano(), 10), entry.line}},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "streams", "result": result},
+		})
+	}))
+}
+
+type bearerTransport struct{}
+
+func (bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer token")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestLogQL(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Query    Query
+		LogQL    string
+	}{
+		{
+			"Namespace",
+			Query{Namespace: "project-a"},
+			`{kubernetes_namespace_name="project-a"}`,
+		},
+		{
+			"Namespace and pods",
+			Query{Namespace: "project-a", Pods: []string{"web-1", "web.2"}},
+			`{kubernetes_namespace_name="project-a", kubernetes_pod_name=~"web-1|web\\.2"}`,
+		},
+		{
+			"Level",
+			Query{Namespace: "project-a", Levels: []string{"crit", "err"}},
+			`{kubernetes_namespace_name="project-a"} | json | level=~"(?i)(?:crit|critical|fatal|err|error)"`,
+		},
+		{
+			"Unknown level",
+			Query{Namespace: "project-a", Levels: []string{"unknown"}},
+			`{kubernetes_namespace_name="project-a"} | json | level=~"(?i)(?:unknown|)"`,
+		},
+		{
+			"Message filters",
+			Query{Namespace: "project-a", Messages: []MessageFilter{{Pattern: "a.b"}, {Pattern: "^GET", Regexp: true, IgnoreCase: true, Invert: true}}},
+			`{kubernetes_namespace_name="project-a"} |= "a.b" | json | message=~"(?s).*(?:a\\.b).*" | message!~"(?s).*(?:(?i)^GET).*"`,
+		},
+		{
+			"Message filters searched for in the lines",
+			Query{Namespace: "project-a", Messages: []MessageFilter{{Pattern: "timeout", IgnoreCase: true}, {Pattern: `say "hi"`}, {Pattern: "probe", Invert: true}}},
+			`{kubernetes_namespace_name="project-a"} |~ "(?i)timeout" | json | message=~"(?s).*(?:(?i)timeout).*" | message=~"(?s).*(?:say \"hi\").*" | message!~"(?s).*(?:probe).*"`,
+		},
+		{
+			"Containers",
+			Query{Namespace: "project-a", Containers: []string{"app", "*-proxy"}, ExcludeContainers: []string{"istio-?roxy", "[^a]*"}},
+			`{kubernetes_namespace_name="project-a", kubernetes_container_name=~"^app$|^.*-proxy$", kubernetes_container_name!~"^istio-.roxy$|^[^a].*$"}`,
+		},
+		{
+			"Hosts",
+			Query{Pods: []string{"web-1"}, Hosts: []string{"worker-0.example.com"}},
+			`{kubernetes_pod_name=~"web-1", kubernetes_host=~"worker-0\\.example\\.com"}`,
+		},
+		{
+			"Units",
+			Query{Units: []string{"kubelet.service", "crio.service"}, Levels: []string{"err"}},
+			`{kubernetes_host=~".+"} | json | systemd_t_SYSTEMD_UNIT=~"kubelet\\.service|crio\\.service" | level=~"(?i)(?:err|error)"`,
+		},
+		{
+			"Units of a host",
+			Query{Units: []string{"kubelet.service"}, Hosts: []string{"worker-0"}},
+			`{kubernetes_host=~"worker-0"} | json | systemd_t_SYSTEMD_UNIT=~"kubelet\\.service"`,
+		},
+		{
+			"No selector",
+			Query{},
+			`{kubernetes_namespace_name=~".+"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		if logQL := LogQL(tt.Query); logQL != tt.LogQL {
+			t.Errorf("Expected LogQL %s found %s", tt.LogQL, logQL)
+		}
+	}
+}
+
+func TestAuditLogQL(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Query    AuditQuery
+		Expected string
+	}{
+		{
+			"All events",
+			AuditQuery{},
+			`{kubernetes_host=~".+"} | json`,
+		},
+		{
+			"Users, resources and codes",
+			AuditQuery{Users: []string{"system:admin", "dev"}, Resources: []string{"secrets"}, Names: []string{"db.credentials"}, Codes: []string{"403", "5xx"}},
+			`{kubernetes_host=~".+"} | json | user_username=~"system:admin|dev" | objectRef_resource=~"secrets" | objectRef_name=~"db\\.credentials" | responseStatus_code=~"403|5[0-9]{2}"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		logQL := AuditLogQL(tt.Query)
+		if logQL != tt.Expected {
+			t.Errorf("Expected LogQL %s found %s", tt.Expected, logQL)
+		}
+	}
+}
+
+func TestLokiBackendQuery(t *testing.T) {
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	web := map[string]string{"kubernetes_namespace_name": "project-a", "kubernetes_pod_name": "web-1", "kubernetes_container_name": "web"}
+	entries := []fakeLokiEntry{
+		{web, base, `{"message":"first","level":"info","kubernetes":{"namespace_name":"project-a","pod_name":"web-1"}}`},
+		{web, base.Add(time.Second), "second"},
+		{web, base.Add(2 * time.Second), `{"message":"third","level":"error"}`},
+		{map[string]string{"kubernetes_namespace_name": "project-a", "kubernetes_pod_name": "db-1"}, base.Add(3 * time.Second), "other pod"},
+	}
+
+	var requests []*http.Request
+	server := fakeLoki(entries, &requests)
+	defer server.Close()
+
+	lokiBackend := NewLokiBackend(&http.Client{Transport: bearerTransport{}}, server.URL+"/", "")
+	logList, err := lokiBackend.Query(context.Background(), Query{
+		Namespace: "project-a",
+		Pods:      []string{"web-1"},
+		StartTime: base,
+		EndTime:   base.Add(2 * time.Second),
+		Limit:     2,
+	})
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+
+	query := requests[0].URL.Query()
+	if query.Get("query") != `{kubernetes_namespace_name="project-a", kubernetes_pod_name=~"web-1"}` || query.Get("direction") != "backward" || query.Get("limit") != "2" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if query.Get("start") != strconv.FormatInt(base.UnixNano(), 10) {
+		t.Errorf("Expected start %d found %s", base.UnixNano(), query.Get("start"))
+	}
+
+	var messages []string
+	for _, log := range logList {
+		messages = append(messages, log.Source.Message)
+		if log.Source.Kubernetes.PodName != "web-1" || log.Source.Kubernetes.ContainerName != "web" || log.Source.Kubernetes.NamespaceName != "project-a" {
+			t.Errorf("Expected Kubernetes metadata from the stream labels, found %+v", log.Source.Kubernetes)
+		}
+	}
+	if !reflect.DeepEqual(messages, []string{"third", "second"}) {
+		t.Errorf("Expected logs %v found %v", []string{"third", "second"}, messages)
+	}
+	if len(logList) == 2 && (logList[0].Source.Level != "error" || !logList[1].Source.Timestamp.Equal(base.Add(time.Second))) {
+		t.Errorf("Unexpected logs %+v", logList)
+	}
+}
+
+func TestLokiBackendQueryWithoutLimit(t *testing.T) {
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	web := map[string]string{"kubernetes_namespace_name": "project-a", "kubernetes_pod_name": "web-1"}
+	var entries []fakeLokiEntry
+	for index := 0; index < constants.LimitUpperBound+5; index++ {
+		entries = append(entries, fakeLokiEntry{web, base.Add(time.Duration(index) * time.Millisecond), fmt.Sprintf(`{"message":"%d"}`, index)})
+	}
+
+	var requests []*http.Request
+	server := fakeLoki(entries, &requests)
+	defer server.Close()
+
+	lokiBackend := NewLokiBackend(&http.Client{Transport: bearerTransport{}}, server.URL, "")
+	logList, err := lokiBackend.Query(context.Background(), Query{
+		Namespace: "project-a",
+		Pods:      []string{"web-1"},
+		StartTime: base,
+		EndTime:   base.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if len(logList) != len(entries) {
+		t.Errorf("Expected %d logs found %d", len(entries), len(logList))
+	}
+	for _, request := range requests {
+		if request.URL.Query().Get("limit") != strconv.Itoa(constants.LimitUpperBound) {
+			t.Errorf("Expected limit %d found %q", constants.LimitUpperBound, request.URL.Query().Get("limit"))
+		}
+	}
+}
+
+func TestLokiBackendStream(t *testing.T) {
+	// five logs, two of which share a timestamp across a page boundary
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	web := map[string]string{"kubernetes_namespace_name": "project-a", "kubernetes_pod_name": "web-1"}
+	entries := []fakeLokiEntry{
+		{web, base, "1"},
+		{web, base.Add(time.Second), "2"},
+		{web, base.Add(2 * time.Second), "3"},
+		{web, base.Add(2 * time.Second), "4"},
+		{web, base.Add(3 * time.Second), "5"},
+	}
+
+	var requests []*http.Request
+	server := fakeLoki(entries, &requests)
+	defer server.Close()
+
+	tests := []struct {
+		TestName  string
+		Ascending bool
+		Tied      int
+		Expected  []string
+	}{
+		{
+			"Newest first",
+			false,
+			1,
+			[]string{"5", "4", "3", "2", "1"},
+		},
+		{
+			"Oldest first",
+			true,
+			2,
+			[]string{"1", "2", "3", "4", "5"},
+		},
+	}
+
+	lokiBackend := NewLokiBackend(&http.Client{Transport: bearerTransport{}}, server.URL, "")
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		var streamed []string
+		err := lokiBackend.Stream(context.Background(), Query{Namespace: "project-a", Pods: []string{"web-1"}, StartTime: base, Limit: 3, Ascending: tt.Ascending}, func(page []logs.LogOptions) error {
+			for _, log := range page {
+				streamed = append(streamed, log.Source.Message)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		// logs sharing a timestamp may be passed on in any order
+		if len(streamed) == 5 {
+			if (streamed[tt.Tied] < streamed[tt.Tied+1]) != tt.Ascending {
+				streamed[tt.Tied], streamed[tt.Tied+1] = streamed[tt.Tied+1], streamed[tt.Tied]
+			}
+		}
+		if !reflect.DeepEqual(streamed, tt.Expected) {
+			t.Errorf("Expected logs %v found %v", tt.Expected, streamed)
+		}
+	}
+}
+
+func TestLokiBackendStreamAudit(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path != "/api/logs/v1/audit/loki/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"kubernetes_host":"master-0"},"values":[
+				["1616047200000000000","{\"verb\":\"delete\",\"user\":{\"username\":\"system:admin\"},\"objectRef\":{\"resource\":\"configmaps\",\"name\":\"settings\"}}"],
+				["1616047199000000000","type=SYSCALL msg=audit(1616047199.000:12): arch=c000003e"]
+			]}
+		]}}`)
+	}))
+	defer server.Close()
+
+	var events []logs.AuditEvent
+	err := NewLokiBackend(&http.Client{Transport: bearerTransport{}}, server.URL, "").StreamAudit(context.Background(), AuditQuery{Limit: 5}, func(page []logs.AuditEvent) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(events) != 2 || events[0].Username() != "system:admin" || events[0].Resource() != "configmaps" || !strings.HasPrefix(events[1].Source.Message, "type=SYSCALL") {
+		t.Errorf("Expected an API server event and a node audit record, found %+v", events)
+	}
+	if len(events) == 2 && (events[1].Source.Hostname != "master-0" || !events[0].Source.Timestamp.Equal(time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC))) {
+		t.Errorf("Expected events of master-0 with stream timestamps, found %+v", events)
+	}
+	if len(requests) != 1 {
+		t.Errorf("Expected a single request found %d", len(requests))
+	}
+}
+
+func TestLokiBackendNamespaces(t *testing.T) {
+	var requests []*http.Request
+	server := fakeLoki(nil, &requests)
+	defer server.Close()
+
+	namespaces, err := NewLokiBackend(&http.Client{Transport: bearerTransport{}}, server.URL, "").Namespaces(context.Background())
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if !reflect.DeepEqual(namespaces, []string{"project-a", "project-b"}) {
+		t.Errorf("Expected namespaces %v found %v", []string{"project-a", "project-b"}, namespaces)
+	}
+}
+
+func TestLokiBackendError(t *testing.T) {
+	var requests []*http.Request
+	server := fakeLoki(nil, &requests)
+	defer server.Close()
+
+	_, err := NewLokiBackend(http.DefaultClient, server.URL, "").Query(context.Background(), Query{Namespace: "project-a", Limit: 5})
+	expected := "unable to query Loki - a server-side error occurred: authentication required"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error is %v, found %v", expected, err)
+	}
+	if ErrorKindOf(err) != ErrorAuth {
+		t.Errorf("Expected error kind %s found %s", ErrorAuth, ErrorKindOf(err))
+	}
+}
+
+func TestLokiTenant(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Tenant   string
+		Query    Query
+		Expected string
+	}{
+		{"Application namespace", "", Query{Namespace: "project-a"}, "application"},
+		{"Infrastructure namespace", "", Query{Namespace: "openshift-logging"}, "infrastructure"},
+		{"Configured tenant", "audit", Query{Namespace: "project-a"}, "audit"},
+		{"Log type", "application", Query{LogType: logs.LogTypeInfrastructure}, "infrastructure"},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		lokiBackend := NewLokiBackend(http.DefaultClient, "http://localhost:8080", tt.Tenant)
+		if tenant := lokiBackend.tenant(tt.Query); tenant != tt.Expected {
+			t.Errorf("Expected tenant %s found %s", tt.Expected, tenant)
+		}
+	}
+}
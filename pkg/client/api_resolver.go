@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+var routeResource = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+type APIOptions struct {
+	Url       string
+	Namespace string
+	Route     string
+	Service   string
+}
+
+// APIEndpoint is the resolved location of the log-exploration API. Close must
+// be called once the API is no longer needed to release a port-forward tunnel.
+type APIEndpoint struct {
+	BaseUrl string
+	stopCh  chan struct{}
+}
+
+func (e *APIEndpoint) Close() {
+	if e.stopCh != nil {
+		close(e.stopCh)
+		e.stopCh = nil
+	}
+}
+
+// ResolveAPIEndpoint finds the log-exploration API. An explicitly configured URL
+// wins over the LOG_EXPLORATION_API_URL environment variable, which wins over the
+// OpenShift Route. When no Route exists the Service is used directly inside the
+// cluster and through a port-forward tunnel outside of it.
+func ResolveAPIEndpoint(kubernetesOptions *KubernetesOptions, apiOptions APIOptions) (*APIEndpoint, error) {
+
+	if len(apiOptions.Url) > 0 {
+		return &APIEndpoint{BaseUrl: logsUrl(apiOptions.Url)}, nil
+	}
+
+	if envUrl := os.Getenv(constants.APIUrlEnv); len(envUrl) > 0 {
+		return &APIEndpoint{BaseUrl: logsUrl(envUrl)}, nil
+	}
+
+	if len(apiOptions.Namespace) == 0 {
+		apiOptions.Namespace = constants.APINamespace
+	}
+	if len(apiOptions.Route) == 0 {
+		apiOptions.Route = constants.APIRoute
+	}
+	if len(apiOptions.Service) == 0 {
+		apiOptions.Service = constants.APIService
+	}
+
+	routeUrl, err := getRouteUrl(kubernetesOptions, apiOptions)
+	if err != nil {
+		return nil, err
+	}
+	if len(routeUrl) > 0 {
+		return &APIEndpoint{BaseUrl: logsUrl(routeUrl)}, nil
+	}
+
+	service, err := kubernetesOptions.Clientset.CoreV1().Services(apiOptions.Namespace).Get(context.Background(), apiOptions.Service, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return nil, fmt.Errorf("log-exploration API not found: neither route \"%s\" nor service \"%s\" is accessible in namespace \"%s\", use --api-url or %s to set the API URL", apiOptions.Route, apiOptions.Service, apiOptions.Namespace, constants.APIUrlEnv)
+		}
+		return nil, fmt.Errorf("an error occurred while fetching log-exploration API service: %v", err)
+	}
+
+	servicePort, err := getServicePort(service)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(os.Getenv("KUBERNETES_SERVICE_HOST")) > 0 {
+		serviceUrl := fmt.Sprintf("%s://%s.%s.svc:%d", portScheme(servicePort.Name, servicePort.Port), service.Name, service.Namespace, servicePort.Port)
+		return &APIEndpoint{BaseUrl: logsUrl(serviceUrl)}, nil
+	}
+
+	return portForwardService(kubernetesOptions, service, servicePort)
+}
+
+func logsUrl(apiUrl string) string {
+	return strings.TrimSuffix(apiUrl, "/") + constants.APILogsPath
+}
+
+func getRouteUrl(kubernetesOptions *KubernetesOptions, apiOptions APIOptions) (string, error) {
+
+	if kubernetesOptions.DynamicClient == nil {
+		return "", nil
+	}
+
+	route, err := kubernetesOptions.DynamicClient.Resource(routeResource).Namespace(apiOptions.Namespace).Get(context.Background(), apiOptions.Route, metav1.GetOptions{})
+	if err != nil {
+		// Clusters without the route API answer with NotFound as well
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("an error occurred while fetching log-exploration API route: %v", err)
+	}
+
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	if len(host) == 0 {
+		ingresses, _, _ := unstructured.NestedSlice(route.Object, "status", "ingress")
+		if len(ingresses) > 0 {
+			if ingress, ok := ingresses[0].(map[string]interface{}); ok {
+				host, _, _ = unstructured.NestedString(ingress, "host")
+			}
+		}
+	}
+	if len(host) == 0 {
+		return "", fmt.Errorf("log-exploration API route \"%s\" in namespace \"%s\" has no host", apiOptions.Route, apiOptions.Namespace)
+	}
+
+	scheme := "http"
+	if tls, found, _ := unstructured.NestedMap(route.Object, "spec", "tls"); found && tls != nil {
+		scheme = "https"
+	}
+	path, _, _ := unstructured.NestedString(route.Object, "spec", "path")
+
+	return scheme + "://" + host + strings.TrimSuffix(path, "/"), nil
+}
+
+func getServicePort(service *corev1.Service) (corev1.ServicePort, error) {
+
+	if len(service.Spec.Ports) == 0 {
+		return corev1.ServicePort{}, fmt.Errorf("log-exploration API service \"%s\" in namespace \"%s\" exposes no ports", service.Name, service.Namespace)
+	}
+	for _, port := range service.Spec.Ports {
+		if port.Name == "http" || port.Name == "https" {
+			return port, nil
+		}
+	}
+	return service.Spec.Ports[0], nil
+}
+
+func portScheme(name string, port int32) string {
+	if name == "https" || port == 443 {
+		return "https"
+	}
+	return "http"
+}
+
+// portForwardService opens a tunnel to one running pod backing the service,
+// the way "kubectl port-forward service/NAME" does.
+func portForwardService(kubernetesOptions *KubernetesOptions, service *corev1.Service, servicePort corev1.ServicePort) (*APIEndpoint, error) {
+
+	if kubernetesOptions.RestConfig == nil {
+		return nil, fmt.Errorf("unable to port-forward to log-exploration API service \"%s\": no cluster configuration available", service.Name)
+	}
+
+	options := metav1.ListOptions{
+		LabelSelector: labels.Set(service.Spec.Selector).AsSelector().String(),
+	}
+	pods, err := kubernetesOptions.Clientset.CoreV1().Pods(service.Namespace).List(context.Background(), options)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching log-exploration API pods: %v", err)
+	}
+
+	var targetPod *corev1.Pod
+	for index := range pods.Items {
+		if pods.Items[index].Status.Phase == corev1.PodRunning {
+			targetPod = &pods.Items[index]
+			break
+		}
+	}
+	if targetPod == nil {
+		return nil, fmt.Errorf("no running pod found for log-exploration API service \"%s\" in namespace \"%s\"", service.Name, service.Namespace)
+	}
+
+	targetPort, err := getTargetPort(targetPod, servicePort)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(kubernetesOptions.RestConfig)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while creating port-forward transport: %v", err)
+	}
+	portForwardUrl := kubernetesOptions.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(targetPod.Namespace).
+		Name(targetPod.Name).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", portForwardUrl)
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{"0:" + strconv.Itoa(targetPort)}, stopCh, readyCh, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while creating port-forward to pod \"%s\": %v", targetPod.Name, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case err = <-errCh:
+		return nil, fmt.Errorf("an error occurred while port-forwarding to pod \"%s\": %v", targetPod.Name, err)
+	case <-readyCh:
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopCh)
+		return nil, fmt.Errorf("an error occurred while port-forwarding to pod \"%s\": %v", targetPod.Name, err)
+	}
+
+	localUrl := fmt.Sprintf("%s://127.0.0.1:%d", portScheme(servicePort.Name, servicePort.Port), ports[0].Local)
+	return &APIEndpoint{BaseUrl: logsUrl(localUrl), stopCh: stopCh}, nil
+}
+
+func getTargetPort(pod *corev1.Pod, servicePort corev1.ServicePort) (int, error) {
+
+	if servicePort.TargetPort.IntValue() > 0 {
+		return servicePort.TargetPort.IntValue(), nil
+	}
+
+	portName := servicePort.TargetPort.String()
+	if len(portName) > 0 && portName != "0" {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == portName {
+					return int(port.ContainerPort), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("port \"%s\" not found in pod \"%s\"", portName, pod.Name)
+	}
+	return int(servicePort.Port), nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testRoute(name string, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "route.openshift.io/v1",
+		"kind":       "Route",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+}
+
+func TestResolveAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		APIOptions APIOptions
+		EnvUrl     string
+		InCluster  bool
+		Routes     []runtime.Object
+		BaseUrl    string
+		Error      error
+	}{
+		{
+			"URL from flag",
+			false,
+			APIOptions{Url: "https://logs.example.com/"},
+			"https://env.example.com",
+			false,
+			nil,
+			"https://logs.example.com/logs",
+			nil,
+		},
+		{
+			"URL from environment",
+			false,
+			APIOptions{},
+			"https://env.example.com",
+			false,
+			nil,
+			"https://env.example.com/logs",
+			nil,
+		},
+		{
+			"URL from route",
+			false,
+			APIOptions{},
+			"",
+			false,
+			[]runtime.Object{testRoute("log-exploration-api-route", "openshift-logging", map[string]interface{}{"host": "api.apps.example.com"})},
+			"http://api.apps.example.com/logs",
+			nil,
+		},
+		{
+			"URL from TLS route in custom namespace",
+			false,
+			APIOptions{Namespace: "logging"},
+			"",
+			false,
+			[]runtime.Object{testRoute("log-exploration-api-route", "logging", map[string]interface{}{"host": "api.example.org", "path": "/explore/", "tls": map[string]interface{}{"termination": "reencrypt"}})},
+			"https://api.example.org/explore/logs",
+			nil,
+		},
+		{
+			"URL from service inside the cluster",
+			false,
+			APIOptions{Namespace: "logging"},
+			"",
+			true,
+			nil,
+			"http://log-exploration-api.logging.svc:8080/logs",
+			nil,
+		},
+		{
+			"Service outside the cluster without configuration",
+			false,
+			APIOptions{Namespace: "logging"},
+			"",
+			false,
+			nil,
+			"",
+			fmt.Errorf("unable to port-forward to log-exploration API service \"log-exploration-api\": no cluster configuration available"),
+		},
+		{
+			"Neither route nor service",
+			false,
+			APIOptions{Namespace: "dummy"},
+			"",
+			false,
+			nil,
+			"",
+			fmt.Errorf("log-exploration API not found: neither route \"log-exploration-api-route\" nor service \"log-exploration-api\" is accessible in namespace \"dummy\", use --api-url or LOG_EXPLORATION_API_URL to set the API URL"),
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "log-exploration-api",
+				Namespace: "logging",
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "log-exploration-api"},
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8080)},
+				},
+			},
+		})
+
+	defer os.Unsetenv(constants.APIUrlEnv)
+	defer os.Setenv("KUBERNETES_SERVICE_HOST", os.Getenv("KUBERNETES_SERVICE_HOST"))
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		os.Setenv(constants.APIUrlEnv, tt.EnvUrl)
+		if tt.InCluster {
+			os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		} else {
+			os.Unsetenv("KUBERNETES_SERVICE_HOST")
+		}
+
+		kubernetesOptions := &KubernetesOptions{
+			Clientset:     clientset,
+			DynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tt.Routes...),
+		}
+		apiEndpoint, err := ResolveAPIEndpoint(kubernetesOptions, tt.APIOptions)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err == nil && apiEndpoint.BaseUrl != tt.BaseUrl {
+			t.Errorf("Expected URL %v found %v", tt.BaseUrl, apiEndpoint.BaseUrl)
+		}
+	}
+}
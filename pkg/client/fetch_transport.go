@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FetchOptions bound the requests sent to the log store.
+type FetchOptions struct {
+	// MaxConcurrency limits the requests in flight, 0 leaves them unlimited.
+	MaxConcurrency int
+	// RequestTimeout limits every attempt of a request, from sending it to
+	// reading the response. 0 leaves attempts unlimited.
+	RequestTimeout time.Duration
+	// MaxRetries is the number of times a request answered with 429 Too Many
+	// Requests or a server error, or a GET request which failed on the
+	// network, is sent again.
+	MaxRetries int
+	// Backoff is the longest delay before the first retry, doubled for every
+	// further retry up to MaxBackoff. MaxBackoff bounds the delays asked for
+	// by Retry-After too.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// FetchHttpClient returns a client sending requests through the transport of
+// httpClient, at most MaxConcurrency at a time. A request holds its slot until
+// its response body is closed. Requests answered with 429 Too Many Requests or
+// a server error are retried after the delay in the Retry-After header, or an
+// exponential backoff with full jitter so that concurrent requests do not
+// retry in lockstep. GET and HEAD requests, which are idempotent, are retried
+// after network errors too. Cancelling the context of a request ends its
+// retries.
+func FetchHttpClient(httpClient *http.Client, fetchOptions FetchOptions) *http.Client {
+
+	baseTransport := httpClient.Transport
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+
+	transport := &fetchTransport{transport: baseTransport, options: fetchOptions}
+	if fetchOptions.MaxConcurrency > 0 {
+		transport.slots = make(chan struct{}, fetchOptions.MaxConcurrency)
+	}
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: httpClient.CheckRedirect,
+		Jar:           httpClient.Jar,
+		Timeout:       httpClient.Timeout,
+	}
+}
+
+type fetchTransport struct {
+	transport http.RoundTripper
+	options   FetchOptions
+	slots     chan struct{}
+}
+
+func (t *fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		response, err := t.roundTrip(attemptReq)
+		if attempt >= t.options.MaxRetries || (req.Body != nil && req.GetBody == nil) {
+			return response, err
+		}
+		if err != nil {
+			if ctx.Err() != nil || !idempotent(req.Method) || !retryableError(err) {
+				return nil, err
+			}
+		} else if !retryable(response.StatusCode) {
+			return response, nil
+		}
+
+		delay := t.backoff(attempt, response)
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip sends a single attempt of the request once a slot is free.
+func (t *fetchTransport) roundTrip(req *http.Request) (*http.Response, error) {
+
+	ctx := req.Context()
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.options.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.options.RequestTimeout)
+		req = req.WithContext(ctx)
+	}
+	release := func() {
+		cancel()
+		if t.slots != nil {
+			<-t.slots
+		}
+	}
+
+	response, err := t.transport.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	response.Body = &releasingBody{ReadCloser: response.Body, release: release}
+	return response, nil
+}
+
+// backoff returns the delay before the retry following attempt, the response
+// being nil when the attempt failed on the network.
+func (t *fetchTransport) backoff(attempt int, response *http.Response) time.Duration {
+
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			retryAfter := time.Duration(seconds) * time.Second
+			if t.options.MaxBackoff > 0 && (retryAfter > t.options.MaxBackoff || retryAfter < 0) {
+				return t.options.MaxBackoff
+			}
+			return retryAfter
+		}
+	}
+
+	backoff := t.options.Backoff << uint(attempt)
+	if t.options.MaxBackoff > 0 && (backoff > t.options.MaxBackoff || backoff <= 0) {
+		backoff = t.options.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// retryableError reports whether an attempt failed on the network, like a
+// refused or reset connection or one closed before the response was sent.
+func retryableError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func idempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// releasingBody frees the slot and the deadline of a request once its
+// response has been read.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchHttpClientRetries(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Statuses   []int
+		RetryAfter string
+		Method     string
+		StatusCode int
+		Attempts   int
+	}{
+		{
+			"Server errors until success",
+			[]int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			"",
+			"GET",
+			http.StatusOK,
+			3,
+		},
+		{
+			"Too many requests with Retry-After",
+			[]int{http.StatusTooManyRequests, http.StatusOK},
+			"0",
+			"GET",
+			http.StatusOK,
+			2,
+		},
+		{
+			"Retry-After longer than the longest backoff",
+			[]int{http.StatusTooManyRequests, http.StatusOK},
+			"3600",
+			"GET",
+			http.StatusOK,
+			2,
+		},
+		{
+			"Request body sent again",
+			[]int{http.StatusInternalServerError, http.StatusOK},
+			"",
+			"POST",
+			http.StatusOK,
+			2,
+		},
+		{
+			"Retries exhausted",
+			[]int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			"",
+			"GET",
+			http.StatusInternalServerError,
+			3,
+		},
+		{
+			"Client error not retried",
+			[]int{http.StatusNotFound, http.StatusOK},
+			"",
+			"GET",
+			http.StatusNotFound,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempt := atomic.AddInt32(&attempts, 1)
+			body, _ := ioutil.ReadAll(r.Body)
+			if r.Method == "POST" && string(body) != "query" {
+				t.Errorf("Expected request body %s found %s", "query", body)
+			}
+			if len(tt.RetryAfter) > 0 {
+				w.Header().Set("Retry-After", tt.RetryAfter)
+			}
+			w.WriteHeader(tt.Statuses[attempt-1])
+		}))
+
+		httpClient := FetchHttpClient(http.DefaultClient, FetchOptions{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+		req, _ := http.NewRequest(tt.Method, server.URL, bytes.NewReader([]byte("query")))
+		response, err := httpClient.Do(req)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		} else {
+			response.Body.Close()
+			if response.StatusCode != tt.StatusCode {
+				t.Errorf("Expected status %d found %d", tt.StatusCode, response.StatusCode)
+			}
+		}
+		if int(attempts) != tt.Attempts {
+			t.Errorf("Expected %d attempts found %d", tt.Attempts, attempts)
+		}
+		server.Close()
+	}
+}
+
+func TestFetchHttpClientRetriesNetworkErrors(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Method   string
+		Error    bool
+		Attempts int
+	}{
+		{
+			"Connection closed before the response of a GET request",
+			"GET",
+			false,
+			2,
+		},
+		{
+			"Connection closed before the response of a POST request",
+			"POST",
+			true,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				connection, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					connection.Close()
+				}
+				return
+			}
+			fmt.Fprint(w, "ok")
+		}))
+
+		httpClient := FetchHttpClient(&http.Client{Transport: &http.Transport{DisableKeepAlives: true}}, FetchOptions{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+		req, _ := http.NewRequest(tt.Method, server.URL, nil)
+		response, err := httpClient.Do(req)
+		if (err != nil) != tt.Error {
+			t.Errorf("Expected error %v, found %v", tt.Error, err)
+		}
+		if err == nil {
+			response.Body.Close()
+		}
+		if int(attempts) != tt.Attempts {
+			t.Errorf("Expected %d attempts found %d", tt.Attempts, attempts)
+		}
+		server.Close()
+	}
+}
+
+func TestFetchHttpClientConcurrency(t *testing.T) {
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	httpClient := FetchHttpClient(http.DefaultClient, FetchOptions{MaxConcurrency: 3})
+	var requests sync.WaitGroup
+	for index := 0; index < 20; index++ {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			response, err := httpClient.Get(server.URL)
+			if err != nil {
+				t.Errorf("Expected error is %v, found %v", nil, err)
+				return
+			}
+			ioutil.ReadAll(response.Body)
+			response.Body.Close()
+		}()
+	}
+	requests.Wait()
+
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most %d requests in flight found %d", 3, maxInFlight)
+	}
+}
+
+func TestFetchHttpClientTimeouts(t *testing.T) {
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Log("Running:", "Request timeout")
+	httpClient := FetchHttpClient(http.DefaultClient, FetchOptions{RequestTimeout: 20 * time.Millisecond})
+	_, err := httpClient.Get(server.URL)
+	if err == nil {
+		t.Errorf("Expected a timeout error found %v", err)
+	}
+
+	t.Log("Running:", "Cancelled during backoff")
+	retryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer retryServer.Close()
+
+	httpClient = FetchHttpClient(http.DefaultClient, FetchOptions{MaxRetries: 5, Backoff: time.Hour, MaxBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", retryServer.URL, nil)
+	started := time.Now()
+	_, err = httpClient.Do(req)
+	if err == nil {
+		t.Errorf("Expected error is %v, found %v", context.DeadlineExceeded, err)
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Errorf("Expected the backoff to end with the context, it took %v", time.Since(started))
+	}
+}
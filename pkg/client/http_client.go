@@ -0,0 +1,169 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// APITLSOptions override the TLS settings taken from the kubeconfig for
+// requests to the log-exploration API.
+type APITLSOptions struct {
+	CAFile         string
+	ClientCertFile string
+	ClientKeyFile  string
+	Insecure       bool
+}
+
+// APIHttpClient returns an HTTP client for the log-exploration API which
+// authenticates the same way client-go authenticates against the cluster:
+// bearer tokens, token files, exec and auth provider plugins, client
+// certificates and impersonation are all taken from the REST config. The
+// kubeconfig CA bundle is trusted in addition to the system roots, since the
+// API is usually served by the cluster ingress rather than the API server.
+func APIHttpClient(restConfig *rest.Config, tlsOptions APITLSOptions) (*http.Client, error) {
+
+	if len(tlsOptions.CAFile) > 0 && tlsOptions.Insecure {
+		return nil, fmt.Errorf("a CA file cannot be used together with insecure TLS for the log-exploration API")
+	}
+	if (len(tlsOptions.ClientCertFile) > 0) != (len(tlsOptions.ClientKeyFile) > 0) {
+		return nil, fmt.Errorf("both a client certificate and a client key are required for the log-exploration API")
+	}
+
+	transportConfig := &transport.Config{}
+	if restConfig != nil {
+		var err error
+		transportConfig, err = restConfig.TransportConfig()
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while reading kubeconfig transport settings: %v", err)
+		}
+	}
+
+	tlsConfig, err := transport.TLSConfigFor(transportConfig)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading kubeconfig TLS settings: %v", err)
+	}
+
+	if tlsConfig != nil {
+		// The server name and the bare CA pool only apply to the API server
+		tlsConfig.ServerName = ""
+		if tlsConfig.RootCAs != nil {
+			tlsConfig.RootCAs = systemCertPool()
+			tlsConfig.RootCAs.AppendCertsFromPEM(transportConfig.TLS.CAData)
+		}
+	}
+
+	tlsConfig, err = applyTLSOptions(tlsConfig, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if restConfig == nil && tlsConfig == nil {
+		return http.DefaultClient, nil
+	}
+
+	baseTransport := http.DefaultTransport.(*http.Transport).Clone()
+	baseTransport.TLSClientConfig = tlsConfig
+
+	roundTripper, err := transport.HTTPWrappersForConfig(transportConfig, baseTransport)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while creating authenticated transport: %v", err)
+	}
+
+	// the timeout of the REST config limits every attempt of a request, see
+	// FetchHttpClient
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+// StoreOptions configure the client of a log store reached at a URL given by
+// the user, like Elasticsearch or a LokiStack gateway.
+type StoreOptions struct {
+	TLS APITLSOptions
+	// BearerToken is sent in the Authorization header of every request. The
+	// token in BearerTokenFile is read again when it changes, BearerToken
+	// takes precedence over it.
+	BearerToken     string
+	BearerTokenFile string
+}
+
+// StoreHttpClient returns an HTTP client for a log store given by its URL.
+// Unlike APIHttpClient it does not carry the credentials of the kubeconfig,
+// which are only sent to the cluster and the log-exploration API it serves.
+func StoreHttpClient(storeOptions StoreOptions) (*http.Client, error) {
+
+	tlsOptions := storeOptions.TLS
+	if len(tlsOptions.CAFile) > 0 && tlsOptions.Insecure {
+		return nil, fmt.Errorf("a CA file cannot be used together with insecure TLS for the log store")
+	}
+	if (len(tlsOptions.ClientCertFile) > 0) != (len(tlsOptions.ClientKeyFile) > 0) {
+		return nil, fmt.Errorf("both a client certificate and a client key are required for the log store")
+	}
+
+	tlsConfig, err := applyTLSOptions(nil, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTransport := http.DefaultTransport.(*http.Transport).Clone()
+	baseTransport.TLSClientConfig = tlsConfig
+
+	var roundTripper http.RoundTripper = baseTransport
+	if len(storeOptions.BearerToken) > 0 || len(storeOptions.BearerTokenFile) > 0 {
+		roundTripper, err = transport.NewBearerAuthWithRefreshRoundTripper(storeOptions.BearerToken, storeOptions.BearerTokenFile, roundTripper)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while reading the bearer token of the log store: %v", err)
+		}
+	}
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+func applyTLSOptions(tlsConfig *tls.Config, tlsOptions APITLSOptions) (*tls.Config, error) {
+
+	if len(tlsOptions.CAFile) == 0 && len(tlsOptions.ClientCertFile) == 0 && !tlsOptions.Insecure {
+		return tlsConfig, nil
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if len(tlsOptions.CAFile) > 0 {
+		caData, err := ioutil.ReadFile(tlsOptions.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while reading CA file: %v", err)
+		}
+		rootCAs := systemCertPool()
+		if !rootCAs.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in CA file \"%s\"", tlsOptions.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+		tlsConfig.InsecureSkipVerify = false
+	}
+
+	if len(tlsOptions.ClientCertFile) > 0 {
+		certificate, err := tls.LoadX509KeyPair(tlsOptions.ClientCertFile, tlsOptions.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		tlsConfig.GetClientCertificate = nil
+	}
+
+	if tlsOptions.Insecure {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.RootCAs = nil
+	}
+	return tlsConfig, nil
+}
+
+func systemCertPool() *x509.CertPool {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		return x509.NewCertPool()
+	}
+	return rootCAs
+}
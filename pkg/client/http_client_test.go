@@ -0,0 +1,362 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestAPIHttpClient(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		ShouldFail    bool
+		RestConfig    *rest.Config
+		Authorization string
+		Impersonate   string
+	}{
+		{
+			"No cluster configuration",
+			false,
+			nil,
+			"",
+			"",
+		},
+		{
+			"Bearer token",
+			false,
+			&rest.Config{BearerToken: "sha256~token"},
+			"Bearer sha256~token",
+			"",
+		},
+		{
+			"Basic authentication and impersonation",
+			false,
+			&rest.Config{Username: "admin", Password: "secret", Impersonate: rest.ImpersonationConfig{UserName: "developer"}},
+			"Basic YWRtaW46c2VjcmV0",
+			"developer",
+		},
+		{
+			"Token with kubeconfig server name",
+			false,
+			&rest.Config{BearerToken: "token", TLSClientConfig: rest.TLSClientConfig{ServerName: "api.example.com"}},
+			"Bearer token",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		var authorization, impersonate string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			impersonate = r.Header.Get("Impersonate-User")
+		}))
+
+		httpClient, err := APIHttpClient(tt.RestConfig, APITLSOptions{})
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+			server.Close()
+			continue
+		}
+		response, err := httpClient.Get(server.URL)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		} else {
+			response.Body.Close()
+		}
+		server.Close()
+
+		if authorization != tt.Authorization {
+			t.Errorf("Expected authorization %q found %q", tt.Authorization, authorization)
+		}
+		if impersonate != tt.Impersonate {
+			t.Errorf("Expected impersonated user %q found %q", tt.Impersonate, impersonate)
+		}
+	}
+}
+
+// writeTestClientCert writes a self-signed client certificate and its key to directory.
+func writeTestClientCert(directory string) (string, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "developer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", err
+	}
+	keyData, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	certFile := filepath.Join(directory, "client.crt")
+	keyFile := filepath.Join(directory, "client.key")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)
+	if err != nil {
+		return "", "", err
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}), 0600)
+	if err != nil {
+		return "", "", err
+	}
+	return certFile, keyFile, nil
+}
+
+func TestAPIHttpClientTLS(t *testing.T) {
+
+	var clientName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientName = ""
+		if len(r.TLS.PeerCertificates) > 0 {
+			clientName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	directory, err := ioutil.TempDir("", "api-tls")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caFile := filepath.Join(directory, "ca.crt")
+	err = ioutil.WriteFile(caFile, serverCA, 0600)
+	if err != nil {
+		t.Fatalf("unable to write CA file: %v", err)
+	}
+	invalidCAFile := filepath.Join(directory, "invalid.crt")
+	err = ioutil.WriteFile(invalidCAFile, []byte("not a certificate"), 0600)
+	if err != nil {
+		t.Fatalf("unable to write CA file: %v", err)
+	}
+	certFile, keyFile, err := writeTestClientCert(directory)
+	if err != nil {
+		t.Fatalf("unable to write client certificate: %v", err)
+	}
+
+	tests := []struct {
+		TestName    string
+		ShouldFail  bool
+		RestConfig  *rest.Config
+		TLSOptions  APITLSOptions
+		ClientName  string
+		ClientError error
+		Error       string
+	}{
+		{
+			"Unknown certificate authority",
+			true,
+			nil,
+			APITLSOptions{},
+			"",
+			nil,
+			"certificate",
+		},
+		{
+			"CA file",
+			false,
+			nil,
+			APITLSOptions{CAFile: caFile},
+			"",
+			nil,
+			"",
+		},
+		{
+			"CA from kubeconfig",
+			false,
+			&rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: serverCA}},
+			APITLSOptions{},
+			"",
+			nil,
+			"",
+		},
+		{
+			"Insecure from kubeconfig",
+			false,
+			&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}},
+			APITLSOptions{},
+			"",
+			nil,
+			"",
+		},
+		{
+			"Insecure",
+			false,
+			&rest.Config{BearerToken: "token"},
+			APITLSOptions{Insecure: true},
+			"",
+			nil,
+			"",
+		},
+		{
+			"Client certificate",
+			false,
+			nil,
+			APITLSOptions{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile},
+			"developer",
+			nil,
+			"",
+		},
+		{
+			"Client certificate without key",
+			true,
+			nil,
+			APITLSOptions{ClientCertFile: certFile},
+			"",
+			fmt.Errorf("both a client certificate and a client key are required for the log-exploration API"),
+			"",
+		},
+		{
+			"CA file with insecure",
+			true,
+			nil,
+			APITLSOptions{CAFile: caFile, Insecure: true},
+			"",
+			fmt.Errorf("a CA file cannot be used together with insecure TLS for the log-exploration API"),
+			"",
+		},
+		{
+			"Invalid CA file",
+			true,
+			nil,
+			APITLSOptions{CAFile: invalidCAFile},
+			"",
+			fmt.Errorf("no PEM encoded certificates found in CA file \"%s\"", invalidCAFile),
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		clientName = ""
+		httpClient, err := APIHttpClient(tt.RestConfig, tt.TLSOptions)
+		if err == nil && tt.ClientError != nil {
+			t.Errorf("Expected error is %v, found %v", tt.ClientError, err)
+		}
+		if err != nil && tt.ClientError == nil {
+			t.Errorf("Expected error is %v, found %v", tt.ClientError, err)
+		}
+		if err != nil && tt.ClientError != nil && err.Error() != tt.ClientError.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.ClientError, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		response, err := httpClient.Get(server.URL)
+		if err == nil {
+			response.Body.Close()
+		}
+		if err == nil && len(tt.Error) > 0 {
+			t.Errorf("Expected error containing %q, found %v", tt.Error, err)
+		}
+		if err != nil && (len(tt.Error) == 0 || !strings.Contains(err.Error(), tt.Error)) {
+			t.Errorf("Expected error containing %q, found %v", tt.Error, err)
+		}
+		if clientName != tt.ClientName {
+			t.Errorf("Expected client certificate %q found %q", tt.ClientName, clientName)
+		}
+	}
+}
+
+func TestStoreHttpClient(t *testing.T) {
+
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(tokenFile, []byte("sha256~file"), 0600)
+	if err != nil {
+		t.Fatalf("unable to write test token: %v", err)
+	}
+
+	tests := []struct {
+		TestName      string
+		ShouldFail    bool
+		StoreOptions  StoreOptions
+		Authorization string
+		Error         string
+	}{
+		{
+			"Unknown certificate authority",
+			true,
+			StoreOptions{},
+			"",
+			"certificate",
+		},
+		{
+			"No token",
+			false,
+			StoreOptions{TLS: APITLSOptions{Insecure: true}},
+			"",
+			"",
+		},
+		{
+			"Store token",
+			false,
+			StoreOptions{TLS: APITLSOptions{Insecure: true}, BearerToken: "sha256~store"},
+			"Bearer sha256~store",
+			"",
+		},
+		{
+			"Store token file",
+			false,
+			StoreOptions{TLS: APITLSOptions{Insecure: true}, BearerTokenFile: tokenFile},
+			"Bearer sha256~file",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		authorization = ""
+		httpClient, err := StoreHttpClient(tt.StoreOptions)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		response, err := httpClient.Get(server.URL)
+		if tt.ShouldFail {
+			if err == nil || !strings.Contains(err.Error(), tt.Error) {
+				t.Errorf("Expected error containing %q, found %v", tt.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		response.Body.Close()
+		if authorization != tt.Authorization {
+			t.Errorf("Expected authorization %q found %q", tt.Authorization, authorization)
+		}
+	}
+}
@@ -2,26 +2,28 @@ package client
 
 import (
 	"fmt"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	"os"
-	"path/filepath"
+	"k8s.io/client-go/rest"
 )
 
 type KubernetesOptions struct {
 	Clientset        kubernetes.Interface
+	DynamicClient    dynamic.Interface
+	RestConfig       *rest.Config
 	ClusterUrl       string
 	CurrentNamespace string
-	ClusterToken	 string
 }
 
-func KubernetesClient() (*KubernetesOptions, error) {
+// KubernetesClient builds the cluster clients from the standard kubectl flags, so
+// --kubeconfig, --context, --cluster, --user, impersonation and $KUBECONFIG are
+// honored exactly as they are by oc and kubectl.
+func KubernetesClient(restClientGetter genericclioptions.RESTClientGetter) (*KubernetesOptions, error) {
 
 	kubernetesOptions := &KubernetesOptions{}
-	kubeconfig := filepath.Join(
-		os.Getenv("HOME"), ".kube", "config",
-	)
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := restClientGetter.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("kubeconfig Error: %v", err)
 	}
@@ -31,12 +33,20 @@ func KubernetesClient() (*KubernetesOptions, error) {
 		return nil, fmt.Errorf("an error occurred while creating kubernetes client: %v", err)
 	}
 
-	clientCfg, _ := clientcmd.NewDefaultClientConfigLoadingRules().Load()
-	namespace := clientCfg.Contexts[clientCfg.CurrentContext].Namespace
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while creating kubernetes dynamic client: %v", err)
+	}
+
+	namespace, _, err := restClientGetter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig Error: %v", err)
+	}
 
-	kubernetesOptions.ClusterToken = config.BearerToken
 	kubernetesOptions.CurrentNamespace = namespace
 	kubernetesOptions.Clientset = clientset
+	kubernetesOptions.DynamicClient = dynamicClient
+	kubernetesOptions.RestConfig = config
 	kubernetesOptions.ClusterUrl = config.Host
 	return kubernetesOptions, nil
 }
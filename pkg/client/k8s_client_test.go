@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://api.dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://api.prod.example.com:6443
+users:
+- name: developer
+  user:
+    token: dev-token
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: developer
+    namespace: openshift-logging
+- name: prod
+  context:
+    cluster: prod
+    user: developer
+current-context: dev
+`
+
+func TestKubernetesClient(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Context    string
+		Cluster    string
+		Namespace  string
+		ClusterUrl string
+		TestNs     string
+		Error      error
+	}{
+		{
+			"Current context",
+			false,
+			"",
+			"",
+			"",
+			"https://api.dev.example.com:6443",
+			"openshift-logging",
+			nil,
+		},
+		{
+			"Context from flag",
+			false,
+			"prod",
+			"",
+			"",
+			"https://api.prod.example.com:6443",
+			"default",
+			nil,
+		},
+		{
+			"Cluster and namespace from flags",
+			false,
+			"",
+			"prod",
+			"app",
+			"https://api.prod.example.com:6443",
+			"app",
+			nil,
+		},
+		{
+			"Unknown context",
+			false,
+			"dummy",
+			"",
+			"",
+			"",
+			"",
+			fmt.Errorf("kubeconfig Error: context \"dummy\" does not exist"),
+		},
+	}
+
+	directory, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+	kubeconfig := filepath.Join(directory, "config")
+	err = ioutil.WriteFile(kubeconfig, []byte(testKubeconfig), 0600)
+	if err != nil {
+		t.Fatalf("unable to write kubeconfig: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		configFlags := genericclioptions.NewConfigFlags(false)
+		configFlags.KubeConfig = &kubeconfig
+		configFlags.Context = &tt.Context
+		configFlags.ClusterName = &tt.Cluster
+		configFlags.Namespace = &tt.Namespace
+
+		kubernetesOptions, err := KubernetesClient(configFlags)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		if kubernetesOptions.ClusterUrl != tt.ClusterUrl {
+			t.Errorf("Expected cluster URL %v found %v", tt.ClusterUrl, kubernetesOptions.ClusterUrl)
+		}
+		if kubernetesOptions.CurrentNamespace != tt.TestNs {
+			t.Errorf("Expected namespace %v found %v", tt.TestNs, kubernetesOptions.CurrentNamespace)
+		}
+		if kubernetesOptions.RestConfig.BearerToken != "dev-token" {
+			t.Errorf("Expected token %v found %v", "dev-token", kubernetesOptions.RestConfig.BearerToken)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	auditExample = templates.Examples(i18n.T(`
+		# Return the requests which deleted config maps in namespace project-a during the last day
+		oc historical-logs audit --verb=delete --resource=configmaps --namespace=project-a --since=1d --es-url=https://elasticsearch.example.com:9200
+
+		# Return the requests of user alice which were denied, oldest first
+		oc historical-logs audit --username=alice --code=403 --reverse --es-url=https://elasticsearch.example.com:9200
+
+		# Return every request answered with a server error between 08:00 and 09:00 UTC on 18 March 2021
+		oc historical-logs audit --code=5xx --since-time='2021-03-18 08:00' --until-time='2021-03-18 09:00' --timezone=UTC --all --es-url=https://elasticsearch.example.com:9200
+
+		# Return who changed secret db-credentials, with the request URI, from the LokiStack of the cluster
+		oc historical-logs audit --resource=secrets --name=db-credentials --verb=update,patch,delete -o template --template='{{._source.user.username}} {{._source.verb}} {{._source.requestURI}}' --backend=loki --loki-url=https://logging-loki-openshift-logging.apps.example.com
+
+		# Return the audit events of the last hour as JSON lines
+		oc historical-logs audit --since=1h -o jsonl --es-url=https://elasticsearch.example.com:9200`))
+)
+
+// AuditParameters are the parameters of the audit subcommand. The time range,
+// limit, order and log store are given like those of logs.
+type AuditParameters struct {
+	LogParameters
+	Users           []string
+	Verbs           []string
+	ObjectResources []string
+	ObjectNames     []string
+	Codes           []string
+}
+
+// NewCmdAudit returns the audit subcommand, which reads the audit logs of the
+// API servers and the nodes.
+func NewCmdAudit(streams genericclioptions.IOStreams) *cobra.Command {
+
+	o := &AuditParameters{}
+	configFlags := genericclioptions.NewConfigFlags(true)
+
+	cmd := &cobra.Command{
+		Use:     "audit [flags]",
+		Short:   "View audit events filtered on user, verb, object and response code",
+		Long:    "View the audit events of the API servers and nodes stored in the audit logs, filtered on the user who sent a request, its verb, the object it was sent for and the response code. Without --namespace the events of every namespace and of cluster scoped objects are returned.",
+		Example: auditExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubernetesOptions, err := client.KubernetesClient(configFlags)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("namespace") {
+				o.Namespace = *configFlags.Namespace
+			}
+			return o.Execute(kubernetesOptions, streams)
+		},
+	}
+
+	o.AddFlags(cmd)
+	configFlags.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *AuditParameters) AddFlags(cmd *cobra.Command) {
+
+	o.addTimeRangeFlags(cmd)
+	o.addStoreFlags(cmd, constants.BackendElasticsearch, []string{constants.BackendElasticsearch, constants.BackendLoki})
+	cmd.Flags().StringSliceVar(&o.Users, "username", nil, "Fetch audit events of requests sent by these comma separated users, e.g. --username=system:admin. --user selects the kubeconfig user")
+	cmd.Flags().StringSliceVar(&o.Verbs, "verb", nil, "Fetch audit events of requests with these comma separated verbs, e.g. --verb=delete,patch")
+	cmd.Flags().StringSliceVar(&o.ObjectResources, "resource", nil, "Fetch audit events of requests for objects of these comma separated resources, without API group, e.g. --resource=configmaps")
+	cmd.Flags().StringSliceVar(&o.ObjectNames, "name", nil, "Fetch audit events of requests for objects of these comma separated names")
+	cmd.Flags().StringSliceVar(&o.Codes, "code", nil, "Fetch audit events of requests answered with these comma separated response codes or classes of codes, e.g. --code=403,5xx")
+	cmd.Flags().IntVar(&o.Limit, "limit", constants.LimitUpperBound, "Specify number of audit events to be fetched, 0 fetches every event")
+	cmd.Flags().BoolVar(&o.All, "all", false, "Fetch every audit event in the time range, same as --limit=0")
+	cmd.Flags().StringVar(&o.Order, "order", constants.OrderDescending, "Order audit events are printed in, by timestamp. One of: asc|desc. With a limit the newest events are printed in either order")
+	cmd.Flags().BoolVar(&o.Reverse, "reverse", false, "Print audit events in the opposite of --order, oldest first by default")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", printers.Table, "Output format. One of: "+strings.Join(printers.AuditFormats(), "|")+". Template formats also accept the template inline, e.g. -o jsonpath={._source.requestURI}")
+	cmd.Flags().StringVar(&o.Template, "template", "", "Template string or JSONPath expression used with -o template, go-template or jsonpath")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "Maximum time to fetch the audit events, e.g. 30s or 2m. Every single request is limited by --request-timeout. 0 waits until all events are fetched")
+}
+
+// ProcessAuditParameters validates the parameters and sets the time range.
+func (o *AuditParameters) ProcessAuditParameters() error {
+
+	switch o.Backend {
+	case constants.BackendElasticsearch, constants.BackendLoki:
+	case constants.BackendAPI:
+		return fmt.Errorf("audit logs cannot be read through the \"%s\" backend, use --backend=%s or --backend=%s", o.Backend, constants.BackendElasticsearch, constants.BackendLoki)
+	default:
+		return fmt.Errorf("invalid \"backend\" value \"%s\" entered, one of %v is required", o.Backend, []string{constants.BackendElasticsearch, constants.BackendLoki})
+	}
+
+	err := o.processTimeRange(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if o.All {
+		o.Limit = 0
+	}
+
+	if o.Limit < constants.LimitLowerBound {
+		return fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required")
+	}
+
+	switch o.Order {
+	case "", constants.OrderAscending, constants.OrderDescending:
+	default:
+		return fmt.Errorf("invalid \"order\" value \"%s\" entered, one of %v is required", o.Order, []string{constants.OrderAscending, constants.OrderDescending})
+	}
+
+	if o.Timeout < 0 {
+		return fmt.Errorf("incorrect \"timeout\" value entered, a non-negative duration is required")
+	}
+
+	err = backend.ValidateAuditCodes(o.Codes)
+	if err != nil {
+		return fmt.Errorf("incorrect \"code\" value entered: %v", err)
+	}
+	return nil
+}
+
+func (o *AuditParameters) Execute(kubernetesOptions *client.KubernetesOptions, streams genericclioptions.IOStreams) error {
+	o.errOut = streams.ErrOut
+	err := o.ProcessAuditParameters()
+	if err != nil {
+		return err
+	}
+
+	printer, err := printers.NewAuditPrinter(streams.Out, printers.PrintOptions{
+		Output:   o.Output,
+		Template: o.Template,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := o.httpClient(kubernetesOptions)
+	if err != nil {
+		return err
+	}
+
+	logBackend, closeBackend, err := o.newBackend(kubernetesOptions, httpClient, streams.ErrOut)
+	if err != nil {
+		return err
+	}
+	defer closeBackend()
+
+	auditSearcher, ok := logBackend.(backend.AuditSearcher)
+	if !ok {
+		return fmt.Errorf("audit logs cannot be read through the \"%s\" backend, use --backend=%s or --backend=%s", o.Backend, constants.BackendElasticsearch, constants.BackendLoki)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	err = o.printAuditEvents(ctx, auditSearcher, printer)
+	if ctx.Err() == context.DeadlineExceeded {
+		return &exitError{code: ExitTotalFailure, err: fmt.Errorf("unable to fetch every audit event within %v, use --timeout to allow more time", o.Timeout)}
+	}
+	return err
+}
+
+// auditQuery builds the backend query of the audit events from the parameters.
+func (o *AuditParameters) auditQuery() (backend.AuditQuery, error) {
+
+	query := backend.AuditQuery{
+		Limit:     o.Limit,
+		Users:     o.Users,
+		Verbs:     o.Verbs,
+		Resources: o.ObjectResources,
+		Names:     o.ObjectNames,
+		Codes:     o.Codes,
+	}
+	if len(o.Namespace) > 0 {
+		query.Namespaces = []string{o.Namespace}
+	}
+	var err error
+	query.StartTime, query.EndTime, err = o.timeRange()
+	return query, err
+}
+
+// printAuditEvents prints the audit events matching the parameters. Like logs,
+// the newest events up to the limit are printed in either order, a limited
+// ascending listing is therefore fetched newest first and printed once
+// complete.
+func (o *AuditParameters) printAuditEvents(ctx context.Context, auditSearcher backend.AuditSearcher, printer printers.AuditPrinter) error {
+
+	query, err := o.auditQuery()
+	if err != nil {
+		return err
+	}
+	collect := o.ascending() && o.Limit > 0
+	query.Ascending = o.ascending() && !collect
+
+	var collected []logs.AuditEvent
+	var printErr error
+	printed := 0
+	err = auditSearcher.StreamAudit(ctx, query, func(page []logs.AuditEvent) error {
+		for _, event := range page {
+			if collect {
+				collected = append(collected, event)
+			} else {
+				printErr = printer.PrintEvent(event)
+				if printErr != nil {
+					return printErr
+				}
+			}
+			printed++
+			if o.Limit > 0 && printed >= o.Limit {
+				return backend.ErrStop
+			}
+		}
+		return nil
+	})
+	if printErr != nil {
+		return printErr
+	}
+	if err != nil {
+		return &exitError{code: ExitTotalFailure, err: err}
+	}
+
+	for index := len(collected) - 1; index >= 0; index-- {
+		err := printer.PrintEvent(collected[index])
+		if err != nil {
+			return err
+		}
+	}
+	err = printer.Flush()
+	if err != nil {
+		return err
+	}
+
+	if printed == 0 {
+		return &exitError{code: ExitNoLogs, err: fmt.Errorf("no audit events present, or input parameters were invalid")}
+	}
+	return nil
+}
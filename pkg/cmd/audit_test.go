@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestProcessAuditParameters(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Parameters AuditParameters
+		Error      error
+	}{
+		{
+			"Elasticsearch with codes",
+			AuditParameters{LogParameters: LogParameters{Backend: constants.BackendElasticsearch, Limit: 10}, Codes: []string{"403", "5xx"}},
+			nil,
+		},
+		{
+			"API backend",
+			AuditParameters{LogParameters: LogParameters{Backend: constants.BackendAPI, Limit: 10}},
+			fmt.Errorf("audit logs cannot be read through the \"api\" backend, use --backend=elasticsearch or --backend=loki"),
+		},
+		{
+			"Invalid code",
+			AuditParameters{LogParameters: LogParameters{Backend: constants.BackendLoki, Limit: 10}, Codes: []string{"forbidden"}},
+			fmt.Errorf("incorrect \"code\" value entered: invalid response code \"forbidden\", a status code like 404 or a class like 5xx is required"),
+		},
+		{
+			"Invalid order",
+			AuditParameters{LogParameters: LogParameters{Backend: constants.BackendLoki, Limit: 10, Order: "newest"}},
+			fmt.Errorf("invalid \"order\" value \"newest\" entered, one of [asc desc] is required"),
+		},
+		{
+			"Invalid time range",
+			AuditParameters{LogParameters: LogParameters{Backend: constants.BackendElasticsearch, Since: "1h", SinceTime: "today"}},
+			fmt.Errorf("only one of \"since\" and \"since-time\" can be used"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		err := tt.Parameters.ProcessAuditParameters()
+		if fmt.Sprint(err) != fmt.Sprint(tt.Error) {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+	}
+}
+
+func TestPrintAuditEvents(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:9200/app-*,infra-*,audit-*/_search",
+		func(req *http.Request) (*http.Response, error) {
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			if !strings.Contains(string(requestBody), `{"terms":{"objectRef.namespace":["project-a"]}}`) {
+				return httpmock.NewStringResponse(200, `{"hits":{"hits":[]}}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[
+				{"_id":"3","_source":{"@timestamp":"2021-03-18T06:00:03Z","verb":"delete","user":{"username":"bob"},"objectRef":{"resource":"configmaps","namespace":"project-a","name":"c"},"responseStatus":{"code":200}},"sort":[3,"3"]},
+				{"_id":"2","_source":{"@timestamp":"2021-03-18T06:00:02Z","verb":"delete","user":{"username":"alice"},"objectRef":{"resource":"configmaps","namespace":"project-a","name":"b"},"responseStatus":{"code":200}},"sort":[2,"2"]},
+				{"_id":"1","_source":{"@timestamp":"2021-03-18T06:00:01Z","verb":"delete","user":{"username":"alice"},"objectRef":{"resource":"configmaps","namespace":"project-a","name":"a"},"responseStatus":{"code":403}},"sort":[1,"1"]}
+			]}}`), nil
+		})
+
+	tests := []struct {
+		TestName   string
+		Parameters AuditParameters
+		Expected   string
+		ExitCode   int
+	}{
+		{
+			"Newest events",
+			AuditParameters{LogParameters: LogParameters{Namespace: "project-a", Limit: 2}},
+			"c,b",
+			0,
+		},
+		{
+			"Newest events oldest first",
+			AuditParameters{LogParameters: LogParameters{Namespace: "project-a", Limit: 2, Reverse: true}},
+			"b,c",
+			0,
+		},
+		{
+			"No events",
+			AuditParameters{LogParameters: LogParameters{Namespace: "project-b", Limit: 2}},
+			"",
+			ExitNoLogs,
+		},
+	}
+
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, _ := printers.NewAuditPrinter(out, printers.PrintOptions{Output: "template={{._source.objectRef.name}}"})
+		err := tt.Parameters.printAuditEvents(context.Background(), esBackend, printer)
+		if tt.ExitCode == 0 && err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if tt.ExitCode != 0 && (err == nil || ExitCode(err) != tt.ExitCode) {
+			t.Errorf("Expected exit code %d found error %v", tt.ExitCode, err)
+		}
+		names := strings.Join(strings.Fields(out.String()), ",")
+		if names != tt.Expected {
+			t.Errorf("Expected events %s found %s", tt.Expected, names)
+		}
+	}
+}
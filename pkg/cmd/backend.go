@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Backends lists the supported values of --backend.
+func Backends() []string {
+	return []string{constants.BackendAPI, constants.BackendElasticsearch, constants.BackendLoki}
+}
+
+// httpClient returns the client requests to the log store are sent with. It
+// limits the number of concurrent requests and retries failed ones. Requests
+// to the log-exploration API authenticate like the kubeconfig does, those to
+// the Elasticsearch or LokiStack URL given by the user only carry the store
+// TLS options and token. The LokiStack gateway authorizes the OpenShift token
+// of the user, which is sent unless another token is given.
+func (o *LogParameters) httpClient(kubernetesOptions *client.KubernetesOptions) (*http.Client, error) {
+
+	var httpClient *http.Client
+	var err error
+	switch o.Backend {
+	case constants.BackendElasticsearch, constants.BackendLoki:
+		storeOptions := client.StoreOptions{
+			TLS: client.APITLSOptions{
+				CAFile:         o.StoreCAFile,
+				ClientCertFile: o.StoreClientCert,
+				ClientKeyFile:  o.StoreClientKey,
+				Insecure:       o.StoreInsecure,
+			},
+			BearerToken: o.StoreToken,
+		}
+		if o.Backend == constants.BackendLoki && len(o.StoreToken) == 0 && kubernetesOptions.RestConfig != nil {
+			storeOptions.BearerToken = kubernetesOptions.RestConfig.BearerToken
+			storeOptions.BearerTokenFile = kubernetesOptions.RestConfig.BearerTokenFile
+		}
+		httpClient, err = client.StoreHttpClient(storeOptions)
+	default:
+		httpClient, err = client.APIHttpClient(kubernetesOptions.RestConfig, client.APITLSOptions{
+			CAFile:         o.APICAFile,
+			ClientCertFile: o.APIClientCert,
+			ClientKeyFile:  o.APIClientKey,
+			Insecure:       o.APIInsecure,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	var requestTimeout time.Duration
+	if kubernetesOptions.RestConfig != nil {
+		requestTimeout = kubernetesOptions.RestConfig.Timeout
+	}
+	return client.FetchHttpClient(httpClient, client.FetchOptions{
+		MaxConcurrency: o.MaxConcurrency,
+		RequestTimeout: requestTimeout,
+		MaxRetries:     constants.MaxRetries,
+		Backoff:        constants.RetryBackoff,
+		MaxBackoff:     constants.MaxRetryBackoff,
+	}), nil
+}
+
+// newBackend creates the backend logs are read from. The returned function
+// releases the resources held by the backend.
+func (o *LogParameters) newBackend(kubernetesOptions *client.KubernetesOptions, httpClient *http.Client, warnings io.Writer) (backend.Backend, func(), error) {
+
+	switch o.Backend {
+	case constants.BackendElasticsearch:
+		if len(o.ESUrl) == 0 {
+			return nil, nil, fmt.Errorf("an Elasticsearch URL is required by the \"%s\" backend, use --es-url to set it", constants.BackendElasticsearch)
+		}
+		return backend.NewElasticsearchBackend(httpClient, o.ESUrl, o.ESIndices), func() {}, nil
+	case constants.BackendLoki:
+		if len(o.LokiUrl) == 0 {
+			return nil, nil, fmt.Errorf("a LokiStack gateway URL is required by the \"%s\" backend, use --loki-url to set it", constants.BackendLoki)
+		}
+		switch o.LokiTenant {
+		case "", constants.LokiTenantApplication, constants.LokiTenantInfrastructure, constants.LokiTenantAudit:
+		default:
+			return nil, nil, fmt.Errorf("invalid \"loki-tenant\" value \"%s\" entered, one of %v is required", o.LokiTenant, []string{constants.LokiTenantApplication, constants.LokiTenantInfrastructure, constants.LokiTenantAudit})
+		}
+		return backend.NewLokiBackend(httpClient, o.LokiUrl, o.LokiTenant), func() {}, nil
+	case "", constants.BackendAPI:
+		apiEndpoint, err := client.ResolveAPIEndpoint(kubernetesOptions, client.APIOptions{
+			Url:       o.APIUrl,
+			Namespace: o.APINamespace,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		apiBackend := backend.NewAPIBackend(httpClient, apiEndpoint.BaseUrl)
+		apiBackend.Warnings = warnings
+		return apiBackend, apiEndpoint.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid \"backend\" value \"%s\" entered, one of %v is required", o.Backend, Backends())
+	}
+}
+
+// query builds the backend query for the pods of a namespace from the log
+// parameters. The filters the backend does not search for are returned
+// separately, to be applied to the logs it returns.
+func (o *LogParameters) query(logBackend backend.Backend, namespace string, pods []string) (backend.Query, localFilter, error) {
+
+	query := backend.Query{
+		Namespace: namespace,
+		Pods:      pods,
+		Limit:     o.Limit,
+	}
+	var err error
+	query.StartTime, query.EndTime, err = o.timeRange()
+	if err != nil {
+		return query, localFilter{}, err
+	}
+
+	var local localFilter
+	query.Levels, query.Messages, local = o.splitFilters(logBackend)
+	if searchesContainers(logBackend, o.Containers, o.ExcludeContainers) {
+		query.Containers = o.Containers
+		query.ExcludeContainers = o.ExcludeContainers
+	}
+	if searchesHosts(logBackend) {
+		query.Hosts = o.Nodes
+	}
+	if searchesLogTypes(logBackend) {
+		query.LogType = o.LogType
+	}
+	return query, local, nil
+}
+
+// timeRange returns the time range set by processTimeRange, zero times leave
+// the range open.
+func (o *LogParameters) timeRange() (time.Time, time.Time, error) {
+
+	var startTime, endTime time.Time
+	var err error
+	if len(o.StartTime) > 0 {
+		startTime, err = time.Parse(time.RFC3339Nano, o.StartTime)
+		if err != nil {
+			return startTime, endTime, fmt.Errorf("an invalid start time was entered: %v", err)
+		}
+	}
+	if len(o.EndTime) > 0 {
+		endTime, err = time.Parse(time.RFC3339Nano, o.EndTime)
+		if err != nil {
+			return startTime, endTime, fmt.Errorf("an invalid end time was entered: %v", err)
+		}
+	}
+	return startTime, endTime, nil
+}
+
+// unitsQuery builds the backend query for the journal logs of systemd units
+// from the log parameters. Journal logs are infrastructure logs of no
+// container, container patterns do not apply to them.
+func (o *LogParameters) unitsQuery(logBackend backend.Backend, units []string) (backend.Query, localFilter, error) {
+
+	unitParameters := *o
+	unitParameters.LogType = logs.LogTypeInfrastructure
+	unitParameters.Containers = nil
+	unitParameters.ExcludeContainers = nil
+	query, local, err := unitParameters.query(logBackend, "", nil)
+	query.Units = units
+	return query, local, err
+}
+
+// podUnits separates the units requested in the pod list from the pods.
+func podUnits(podList []k8sresources.Pod) ([]string, []k8sresources.Pod) {
+
+	var units []string
+	var pods []k8sresources.Pod
+	for _, pod := range podList {
+		if len(pod.Unit) > 0 {
+			units = append(units, pod.Unit)
+		} else {
+			pods = append(pods, pod)
+		}
+	}
+	return units, pods
+}
+
+// fetchPodsLogs fetches every log of the pods and of the units in the time
+// range, paging forward from its start, and keeps only the containers selected
+// for each pod. Pods whose logs cannot be fetched are reported and skipped,
+// nothing is reported once ctx is canceled.
+func fetchPodsLogs(ctx context.Context, logBackend backend.Backend, logParameters *LogParameters, podList []k8sresources.Pod) []logs.LogOptions {
+
+	var logList []logs.LogOptions
+	units, podList := podUnits(podList)
+	if len(units) > 0 {
+		query, local, err := logParameters.unitsQuery(logBackend, units)
+		if err != nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+			return nil
+		}
+		unitLogs, err := streamLogs(ctx, logBackend, query)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+		}
+		unitLogs, err = local.apply(unitLogs)
+		if err != nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+			return nil
+		}
+		logList = append(logList, unitLogs...)
+	}
+
+	namespaces, namespacePods := podsByNamespace(podList, logParameters.Namespace)
+	for _, namespace := range namespaces {
+		pods := namespacePods[namespace]
+		query, local, err := logParameters.query(logBackend, namespace, podNames(pods))
+		if err != nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+			return nil
+		}
+
+		namespaceLogs, err := streamLogs(ctx, logBackend, query)
+		if err != nil && ctx.Err() == nil {
+			if aggregate, ok := err.(utilerrors.Aggregate); ok {
+				for _, podErr := range aggregate.Errors() {
+					fmt.Fprintln(logParameters.warnings(), podErr)
+				}
+			} else {
+				fmt.Fprintln(logParameters.warnings(), err)
+			}
+		}
+		namespaceLogs, err = local.apply(filterPodsContainers(namespaceLogs, pods))
+		if err != nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+			return nil
+		}
+		logList = append(logList, namespaceLogs...)
+	}
+	return logList
+}
+
+// streamLogs returns every log matching the query, oldest first, fetched one
+// page of query.Limit logs at a time. The logs of the pages fetched before an
+// error are returned with it.
+func streamLogs(ctx context.Context, logBackend backend.Backend, query backend.Query) ([]logs.LogOptions, error) {
+
+	query.Ascending = true
+	var logList []logs.LogOptions
+	err := logBackend.Stream(ctx, query, func(page []logs.LogOptions) error {
+		logList = append(logList, page...)
+		return nil
+	})
+	return logList, err
+}
+
+func podNames(podList []k8sresources.Pod) []string {
+	var names []string
+	for _, pod := range podList {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+// podsByNamespace groups the pods by namespace, in the order the namespaces
+// first appear. Pods without a namespace belong to namespace.
+func podsByNamespace(podList []k8sresources.Pod, namespace string) ([]string, map[string][]k8sresources.Pod) {
+
+	var namespaces []string
+	namespacePods := map[string][]k8sresources.Pod{}
+	for _, pod := range podList {
+		podNamespace := pod.Namespace
+		if len(podNamespace) == 0 {
+			podNamespace = namespace
+		}
+		if _, ok := namespacePods[podNamespace]; !ok {
+			namespaces = append(namespaces, podNamespace)
+		}
+		namespacePods[podNamespace] = append(namespacePods[podNamespace], pod)
+	}
+	return namespaces, namespacePods
+}
+
+// filterPodsContainers keeps the logs of the containers selected for each pod.
+func filterPodsContainers(logList []logs.LogOptions, podList []k8sresources.Pod) []logs.LogOptions {
+
+	containers := map[string][]string{}
+	for _, pod := range podList {
+		containers[pod.Name] = pod.Containers
+	}
+
+	var filtered []logs.LogOptions
+	for _, log := range logList {
+		podContainers := containers[log.Source.Kubernetes.PodName]
+		if len(filterContainers([]logs.LogOptions{log}, podContainers)) > 0 {
+			filtered = append(filtered, log)
+		}
+	}
+	return filtered
+}
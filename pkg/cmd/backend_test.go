@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		LogParameters LogParameters
+		Error         error
+	}{
+		{
+			"Default backend",
+			LogParameters{APIUrl: "http://localhost:8080"},
+			nil,
+		},
+		{
+			"Elasticsearch backend",
+			LogParameters{Backend: "elasticsearch", ESUrl: "http://localhost:9200"},
+			nil,
+		},
+		{
+			"Elasticsearch backend without URL",
+			LogParameters{Backend: "elasticsearch"},
+			fmt.Errorf("an Elasticsearch URL is required by the \"elasticsearch\" backend, use --es-url to set it"),
+		},
+		{
+			"Loki backend",
+			LogParameters{Backend: "loki", LokiUrl: "https://logging-loki.apps.example.com"},
+			nil,
+		},
+		{
+			"Loki backend with invalid tenant",
+			LogParameters{Backend: "loki", LokiUrl: "https://logging-loki.apps.example.com", LokiTenant: "network"},
+			fmt.Errorf("invalid \"loki-tenant\" value \"network\" entered, one of [application infrastructure audit] is required"),
+		},
+		{
+			"Invalid backend",
+			LogParameters{Backend: "splunk"},
+			fmt.Errorf("invalid \"backend\" value \"splunk\" entered, one of [api elasticsearch loki] is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		logBackend, closeBackend, err := tt.LogParameters.newBackend(&client.KubernetesOptions{}, http.DefaultClient, ioutil.Discard)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil {
+			continue
+		}
+		closeBackend()
+
+		switch tt.LogParameters.Backend {
+		case "elasticsearch":
+			if _, ok := logBackend.(*backend.ElasticsearchBackend); !ok {
+				t.Errorf("Expected an Elasticsearch backend, found %T", logBackend)
+			}
+		case "loki":
+			if _, ok := logBackend.(*backend.LokiBackend); !ok {
+				t.Errorf("Expected a Loki backend, found %T", logBackend)
+			}
+		default:
+			if apiBackend, ok := logBackend.(*backend.APIBackend); !ok || apiBackend.BaseUrl != "http://localhost:8080/logs" {
+				t.Errorf("Expected an API backend for %s, found %#v", "http://localhost:8080/logs", logBackend)
+			}
+		}
+	}
+}
+
+func TestHttpClientAuthorization(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		LogParameters LogParameters
+		Authorization string
+	}{
+		{
+			"Loki backend with the kubeconfig token",
+			LogParameters{Backend: "loki"},
+			"Bearer sha256~user",
+		},
+		{
+			"Loki backend with a store token",
+			LogParameters{Backend: "loki", StoreToken: "sha256~store"},
+			"Bearer sha256~store",
+		},
+		{
+			"Elasticsearch backend without a store token",
+			LogParameters{Backend: "elasticsearch"},
+			"",
+		},
+	}
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"status":"success","data":["project-a"]}`)
+	}))
+	defer server.Close()
+
+	kubernetesOptions := &client.KubernetesOptions{RestConfig: &rest.Config{BearerToken: "sha256~user"}}
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		authorization = ""
+
+		logParameters := tt.LogParameters
+		logParameters.LokiUrl = server.URL
+		logParameters.ESUrl = server.URL
+		httpClient, err := logParameters.httpClient(kubernetesOptions)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		if logParameters.Backend == "loki" {
+			logBackend, _, err := logParameters.newBackend(kubernetesOptions, httpClient, ioutil.Discard)
+			if err != nil {
+				t.Fatalf("Expected error is %v, found %v", nil, err)
+			}
+			_, err = logBackend.Namespaces(context.Background())
+			if err != nil {
+				t.Errorf("Expected error is %v, found %v", nil, err)
+			}
+		} else {
+			response, err := httpClient.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Expected error is %v, found %v", nil, err)
+			}
+			response.Body.Close()
+		}
+		if authorization != tt.Authorization {
+			t.Errorf("Expected authorization %q found %q", tt.Authorization, authorization)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+)
+
+// contextLines returns the number of logs printed before and after every
+// match. --before-context and --after-context override --context-lines.
+func (o *LogParameters) contextLines() (int, int) {
+	before, after := o.BeforeContext, o.AfterContext
+	if before == 0 {
+		before = o.Context
+	}
+	if after == 0 {
+		after = o.Context
+	}
+	return before, after
+}
+
+// contextPrinter prints every log it is given together with the logs of the
+// same container or unit stream around it, in the order the logs are printed in. When
+// printed newest first the logs after a match come above it. Hunks of the same stream which overlap are
+// merged, the others are separated like grep does.
+type contextPrinter struct {
+	ctx        context.Context
+	logBackend backend.Backend
+	printer    printers.LogPrinter
+	before     int
+	after      int
+	ascending  bool
+	rangeStart time.Time
+	rangeEnd   time.Time
+	parser     *logs.MessageParser
+
+	hunks          int
+	previousStream string
+	previousKeys   map[string]bool
+}
+
+func (o *LogParameters) newContextPrinter(ctx context.Context, logBackend backend.Backend, printer printers.LogPrinter) (*contextPrinter, error) {
+
+	before, after := o.contextLines()
+	contextPrinter := &contextPrinter{
+		ctx:        ctx,
+		logBackend: logBackend,
+		printer:    printer,
+		before:     before,
+		after:      after,
+		ascending:  o.ascending(),
+		parser:     o.messageParser(),
+	}
+	if len(o.StartTime) > 0 {
+		startTime, err := time.Parse(time.RFC3339Nano, o.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("an invalid start time was entered: %v", err)
+		}
+		contextPrinter.rangeStart = startTime
+	}
+	if len(o.EndTime) > 0 {
+		endTime, err := time.Parse(time.RFC3339Nano, o.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("an invalid end time was entered: %v", err)
+		}
+		contextPrinter.rangeEnd = endTime
+	}
+	return contextPrinter, nil
+}
+
+func (p *contextPrinter) PrintLog(log logs.LogOptions) error {
+
+	newer, err := p.contextLogs(log, p.after, true)
+	if err != nil {
+		return err
+	}
+	older, err := p.contextLogs(log, p.before, false)
+	if err != nil {
+		return err
+	}
+
+	// newer and older come nearest first
+	hunk := make([]logs.LogOptions, 0, len(newer)+len(older)+1)
+	if p.ascending {
+		for index := len(older) - 1; index >= 0; index-- {
+			hunk = append(hunk, older[index])
+		}
+		hunk = append(hunk, log)
+		hunk = append(hunk, newer...)
+	} else {
+		for index := len(newer) - 1; index >= 0; index-- {
+			hunk = append(hunk, newer[index])
+		}
+		hunk = append(hunk, log)
+		hunk = append(hunk, older...)
+	}
+
+	stream := logStream(log)
+	overlaps := false
+	if stream == p.previousStream {
+		for _, hunkLog := range hunk {
+			if p.previousKeys[logKey(hunkLog)] {
+				overlaps = true
+				break
+			}
+		}
+	}
+
+	if p.hunks > 0 && !overlaps {
+		if separatorPrinter, ok := p.printer.(printers.SeparatorPrinter); ok {
+			err = separatorPrinter.PrintSeparator()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	keys := map[string]bool{}
+	for _, hunkLog := range hunk {
+		key := logKey(hunkLog)
+		keys[key] = true
+		if overlaps && p.previousKeys[key] {
+			continue
+		}
+		err = p.printer.PrintLog(hunkLog)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.hunks++
+	p.previousStream = stream
+	p.previousKeys = keys
+	return nil
+}
+
+func (p *contextPrinter) Flush() error {
+	return p.printer.Flush()
+}
+
+// contextLogs returns up to count logs of the stream of log which are newer,
+// or older, than it, nearest first. The logs are looked up in a window next
+// to the log which doubles until enough logs are found or it reaches the end
+// of the time range, or constants.ContextLookback without one.
+func (p *contextPrinter) contextLogs(log logs.LogOptions, count int, newer bool) ([]logs.LogOptions, error) {
+
+	if count <= 0 {
+		return nil, nil
+	}
+
+	timestamp := log.Source.Timestamp
+	limit := p.rangeStart
+	if newer {
+		limit = p.rangeEnd
+	}
+	if limit.IsZero() {
+		if newer {
+			limit = timestamp.Add(constants.ContextLookback)
+		} else {
+			limit = timestamp.Add(-constants.ContextLookback)
+		}
+	}
+
+	if newer && !limit.After(timestamp) || !newer && !limit.Before(timestamp) {
+		return nil, nil
+	}
+
+	key := logKey(log)
+	stream := logStream(log)
+	for window := time.Second; ; window *= 2 {
+		query := p.streamQuery(log)
+		// the nearest logs come first, in the first pages
+		query.Ascending = newer
+		if newer {
+			query.StartTime, query.EndTime = timestamp, timestamp.Add(window)
+			if query.EndTime.After(limit) {
+				query.EndTime = limit
+			}
+		} else {
+			query.StartTime, query.EndTime = timestamp.Add(-window), timestamp
+			if query.StartTime.Before(limit) {
+				query.StartTime = limit
+			}
+		}
+
+		var found []logs.LogOptions
+		err := p.logBackend.Stream(p.ctx, query, func(page []logs.LogOptions) error {
+			for _, pageLog := range page {
+				if logKey(pageLog) == key || logStream(pageLog) != stream {
+					continue
+				}
+				// logs logged at the same time as the match are printed after it
+				if newer && pageLog.Source.Timestamp.Before(timestamp) || !newer && !pageLog.Source.Timestamp.Before(timestamp) {
+					continue
+				}
+				if p.parser != nil {
+					pageLog = p.parser.Parse(pageLog)
+				}
+				found = append(found, pageLog)
+				if len(found) >= count {
+					return backend.ErrStop
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch context of log - %v", err)
+		}
+
+		exhausted := (newer && !query.EndTime.Before(limit)) || (!newer && !query.StartTime.After(limit))
+		if len(found) >= count || exhausted {
+			return found, nil
+		}
+	}
+}
+
+// streamQuery selects the logs of the stream of log, the container or the
+// unit on a node which wrote it.
+func (p *contextPrinter) streamQuery(log logs.LogOptions) backend.Query {
+
+	query := backend.Query{Limit: constants.LimitUpperBound}
+	if unit := log.Unit(); len(unit) > 0 {
+		query.Units = []string{unit}
+		query.LogType = logs.LogTypeInfrastructure
+		if host := logHost(log); len(host) > 0 && searchesHosts(p.logBackend) {
+			query.Hosts = []string{host}
+		}
+		return query
+	}
+
+	query.Namespace = log.Source.Kubernetes.NamespaceName
+	query.Pods = []string{log.Source.Kubernetes.PodName}
+	if container := log.Source.Kubernetes.ContainerName; len(container) > 0 && searchesContainers(p.logBackend, []string{container}, nil) {
+		query.Containers = []string{container}
+	}
+	return query
+}
+
+// logStream identifies the container, or the unit on a node, a log was
+// written by.
+func logStream(log logs.LogOptions) string {
+	if unit := log.Unit(); len(unit) > 0 {
+		return logHost(log) + "/" + constants.Unit + "/" + unit
+	}
+	kubernetes := log.Source.Kubernetes
+	return kubernetes.NamespaceName + "/" + kubernetes.PodName + "/" + kubernetes.ContainerName
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestContextPrinter(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		LogParameters LogParameters
+		Output        []string
+	}{
+		{
+			"Before and after context",
+			LogParameters{BeforeContext: 2, AfterContext: 1},
+			[]string{
+				"line 26", "error 25", "line 24", "line 23", "--",
+				"line 16", "error 15", "line 14", "line 13", "--",
+				"line 6", "error 5", "line 4", "line 3",
+			},
+		},
+		{
+			"Context overridden by after context",
+			LogParameters{Context: 2, AfterContext: 1},
+			[]string{
+				"line 26", "error 25", "line 24", "line 23", "--",
+				"line 16", "error 15", "line 14", "line 13", "--",
+				"line 6", "error 5", "line 4", "line 3",
+			},
+		},
+		{
+			"Before and after context oldest first",
+			LogParameters{BeforeContext: 2, AfterContext: 1, Order: "asc"},
+			[]string{
+				"line 3", "line 4", "error 5", "line 6", "--",
+				"line 13", "line 14", "error 15", "line 16", "--",
+				"line 23", "line 24", "error 25", "line 26",
+			},
+		},
+		{
+			"Overlapping context",
+			LogParameters{Context: 5},
+			[]string{
+				"line 29", "line 28", "line 27", "line 26", "error 25", "line 24", "line 23", "line 22", "line 21", "line 20",
+				"line 19", "line 18", "line 17", "line 16", "error 15", "line 14", "line 13", "line 12", "line 11", "line 10",
+				"line 9", "line 8", "line 7", "line 6", "error 5", "line 4", "line 3", "line 2", "line 1", "line 0",
+			},
+		},
+	}
+
+	// 30 logs of two containers one second apart, every tenth of them is an error
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			start, _ := time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+			maxLogs, _ := strconv.Atoi(query.Get("/maxlogs/"))
+
+			var page []string
+			for index := 29; index >= 0 && len(page) < maxLogs; index-- {
+				timestamp := base.Add(time.Duration(index) * time.Second)
+				if timestamp.Before(start) || timestamp.After(end) {
+					continue
+				}
+				message := fmt.Sprintf("line %d", index)
+				if index%10 == 5 {
+					message = fmt.Sprintf("error %d", index)
+				}
+				page = append(page,
+					fmt.Sprintf(`{"_id":"%d","_source":{"message":"%s","@timestamp":"%s","kubernetes":{"pod_name":"pod-1","container_name":"app"}}}`, index, message, timestamp.Format(time.RFC3339Nano)),
+					fmt.Sprintf(`{"_id":"proxy-%d","_source":{"message":"proxy %d","@timestamp":"%s","kubernetes":{"pod_name":"pod-1","container_name":"proxy"}}}`, index, index, timestamp.Format(time.RFC3339Nano)),
+				)
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		logParameters := tt.LogParameters
+		logParameters.Grep = "error"
+		logParameters.Limit = 10
+		logParameters.StartTime = base.Format(time.RFC3339Nano)
+		logParameters.EndTime = base.Add(time.Hour).Format(time.RFC3339Nano)
+
+		logBackend := backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+		out := &bytes.Buffer{}
+		printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+		contextPrinter, err := logParameters.newContextPrinter(context.Background(), logBackend, printer)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+
+		err = logParameters.printPodsLogs(context.Background(), logBackend, []k8sresources.Pod{{Name: "pod-1", Containers: []string{"app"}}}, contextPrinter)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if strings.Join(lines, ",") != strings.Join(tt.Output, ",") {
+			t.Errorf("Expected output %v found %v", tt.Output, lines)
+		}
+	}
+}
+
+// pagedBackend streams its logs in pages of two, in the order and the time
+// range of the query, counting the pages passed on.
+type pagedBackend struct {
+	logs  []logs.LogOptions
+	pages int
+}
+
+func (b *pagedBackend) Query(ctx context.Context, query backend.Query) ([]logs.LogOptions, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (b *pagedBackend) Stream(ctx context.Context, query backend.Query, fn func(page []logs.LogOptions) error) error {
+
+	var matched []logs.LogOptions
+	for _, log := range b.logs {
+		if !log.Source.Timestamp.Before(query.StartTime) && !log.Source.Timestamp.After(query.EndTime) {
+			matched = append(matched, log)
+		}
+	}
+	if !query.Ascending {
+		for index1, index2 := 0, len(matched)-1; index1 < index2; index1, index2 = index1+1, index2-1 {
+			matched[index1], matched[index2] = matched[index2], matched[index1]
+		}
+	}
+
+	for start := 0; start < len(matched); start += 2 {
+		end := start + 2
+		if end > len(matched) {
+			end = len(matched)
+		}
+		b.pages++
+		err := fn(matched[start:end])
+		if err == backend.ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *pagedBackend) Fields(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (b *pagedBackend) Namespaces(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestContextLogsStopsOnceFound(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Newer    bool
+		Messages []string
+	}{
+		{
+			"After context",
+			true,
+			[]string{"line 11", "line 12", "line 13"},
+		},
+		{
+			"Before context",
+			false,
+			[]string{"line 9", "line 8", "line 7"},
+		},
+	}
+
+	// 20 logs of a container 100 milliseconds apart, all within the first window
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	var logList []logs.LogOptions
+	for index := 0; index < 20; index++ {
+		log := logs.LogOptions{ID: strconv.Itoa(index)}
+		log.Source.Message = fmt.Sprintf("line %d", index)
+		log.Source.Timestamp = base.Add(time.Duration(index) * 100 * time.Millisecond)
+		log.Source.Kubernetes.PodName = "pod-1"
+		log.Source.Kubernetes.ContainerName = "app"
+		logList = append(logList, log)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		logBackend := &pagedBackend{logs: logList}
+		contextPrinter := &contextPrinter{ctx: context.Background(), logBackend: logBackend}
+		found, err := contextPrinter.contextLogs(logList[10], 3, tt.Newer)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		var messages []string
+		for _, log := range found {
+			messages = append(messages, log.Source.Message)
+		}
+		if strings.Join(messages, ",") != strings.Join(tt.Messages, ",") {
+			t.Errorf("Expected logs %v found %v", tt.Messages, messages)
+		}
+		// the third log is found on the second page
+		if logBackend.pages != 2 {
+			t.Errorf("Expected no page after the %d logs were found, %d pages were read", 3, logBackend.pages)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+)
+
+// Exit codes of the command. Invalid arguments and every other error exit
+// with ExitFailure.
+const (
+	ExitFailure = 1
+	// ExitTotalFailure is returned when the logs of no pod could be fetched.
+	ExitTotalFailure = 2
+	// ExitPartialFailure is returned with --fail-on-partial when the logs of
+	// some pods could not be fetched.
+	ExitPartialFailure = 3
+	// ExitNoLogs is returned when no log matched.
+	ExitNoLogs = 4
+)
+
+// exitError ends the command with a specific exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the exit code of the command for the error it failed with.
+func ExitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitFailure
+}
+
+// podError is the error which ended the logs of a pod.
+type podError struct {
+	pod string
+	err error
+}
+
+func (e *podError) Error() string {
+	return e.err.Error()
+}
+
+func (e *podError) Unwrap() error {
+	return e.err
+}
+
+// fetchFailures collects the errors of the pods whose logs could not be
+// fetched, in the order they occurred.
+type fetchFailures struct {
+	errs []*podError
+	pods map[string]bool
+}
+
+func (f *fetchFailures) add(err error) {
+	podErr, ok := err.(*podError)
+	if !ok {
+		podErr = &podError{err: err}
+	}
+	if f.pods == nil {
+		f.pods = map[string]bool{}
+	}
+	f.errs = append(f.errs, podErr)
+	f.pods[podErr.pod] = true
+}
+
+// failed returns the number of pods whose logs could not be fetched.
+func (f *fetchFailures) failed() int {
+	return len(f.pods)
+}
+
+// summarize writes the errors of every pod with their kind.
+func (f *fetchFailures) summarize(out io.Writer, pods int) {
+
+	if len(f.errs) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "error: unable to fetch the logs of %d of %d pods:\n", f.failed(), pods)
+	for _, podErr := range f.errs {
+		pod := podErr.pod
+		if len(pod) == 0 {
+			pod = "-"
+		}
+		fmt.Fprintf(out, "  %s [%s]: %v\n", pod, backend.ErrorKindOf(podErr.err), podErr.err)
+	}
+}
+
+// exitError returns the error the command ends with after printed logs of
+// the pods were printed, nil if it succeeded.
+func (f *fetchFailures) exitError(pods int, printed int, failOnPartial bool) error {
+
+	switch {
+	case pods > 0 && f.failed() >= pods:
+		return &exitError{code: ExitTotalFailure, err: fmt.Errorf("unable to fetch the logs of any pod")}
+	case f.failed() > 0 && failOnPartial:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("unable to fetch the logs of %d of %d pods", f.failed(), pods)}
+	case printed == 0:
+		return &exitError{code: ExitNoLogs, err: fmt.Errorf("no logs present, or input parameters were invalid")}
+	}
+	return nil
+}
+
+// warnings returns where errors which do not end the command are written.
+func (o *LogParameters) warnings() io.Writer {
+	if o.errOut == nil {
+		return os.Stderr
+	}
+	return o.errOut
+}
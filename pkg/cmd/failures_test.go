@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestPrintPodsLogsFailures(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Statuses      map[string]int
+		FailOnPartial bool
+		Output        string
+		Summary       string
+		Error         error
+		ExitCode      int
+	}{
+		{
+			"All pods fetched",
+			map[string]int{"pod-1": 200, "pod-2": 200},
+			false,
+			"pod-2\npod-1\n",
+			"",
+			nil,
+			0,
+		},
+		{
+			"Partial failure",
+			map[string]int{"pod-1": 200, "pod-2": 401},
+			false,
+			"pod-1\n",
+			"error: unable to fetch the logs of 1 of 2 pods:\n  pod-2 [auth]: unable to fetch logs of pod pod-2 - a server-side error occured: 401\n",
+			nil,
+			0,
+		},
+		{
+			"Partial failure failing the command",
+			map[string]int{"pod-1": 200, "pod-2": 503},
+			true,
+			"pod-1\n",
+			"error: unable to fetch the logs of 1 of 2 pods:\n  pod-2 [backend-unavailable]: unable to fetch logs of pod pod-2 - a server-side error occured: 503\n",
+			fmt.Errorf("unable to fetch the logs of 1 of 2 pods"),
+			ExitPartialFailure,
+		},
+		{
+			"Total failure",
+			map[string]int{"pod-1": 404, "pod-2": 403},
+			false,
+			"",
+			"error: unable to fetch the logs of 2 of 2 pods:\n  pod-1 [not-found]: unable to fetch logs of pod pod-1 - a server-side error occured: 404\n  pod-2 [auth]: unable to fetch logs of pod pod-2 - a server-side error occured: 403\n",
+			fmt.Errorf("unable to fetch the logs of any pod"),
+			ExitTotalFailure,
+		},
+		{
+			"No logs",
+			map[string]int{"pod-1": 204, "pod-2": 204},
+			false,
+			"",
+			"",
+			fmt.Errorf("no logs present, or input parameters were invalid"),
+			ExitNoLogs,
+		},
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		statuses := tt.Statuses
+		httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+			func(req *http.Request) (*http.Response, error) {
+				pod := req.URL.Query().Get("/pod/")
+				switch statuses[pod] {
+				case 200:
+					timestamp := map[string]string{"pod-1": "2021-03-18T06:00:00Z", "pod-2": "2021-03-18T06:00:01Z"}[pod]
+					return httpmock.NewJsonResponse(200, map[string][]string{"Logs": {
+						fmt.Sprintf(`{"_id":"%s","_source":{"message":"%s","@timestamp":"%s"}}`, pod, pod, timestamp),
+					}})
+				case 204:
+					return httpmock.NewJsonResponse(200, map[string][]string{"Logs": {}})
+				}
+				return httpmock.NewStringResponse(statuses[pod], ""), nil
+			})
+
+		out := &bytes.Buffer{}
+		errOut := &bytes.Buffer{}
+		printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+		logParameters := LogParameters{Limit: 10, FailOnPartial: tt.FailOnPartial, errOut: errOut}
+		podList := []k8sresources.Pod{{Name: "pod-1"}, {Name: "pod-2"}}
+		err := logParameters.printPodsLogs(context.Background(), backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), podList, printer)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && ExitCode(err) != tt.ExitCode {
+			t.Errorf("Expected exit code %d found %d", tt.ExitCode, ExitCode(err))
+		}
+		if out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+		if errOut.String() != tt.Summary {
+			t.Errorf("Expected summary %q found %q", tt.Summary, errOut.String())
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Error    error
+		ExitCode int
+	}{
+		{
+			"Invalid argument",
+			fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required"),
+			ExitFailure,
+		},
+		{
+			"No logs",
+			&exitError{code: ExitNoLogs, err: fmt.Errorf("no logs present, or input parameters were invalid")},
+			ExitNoLogs,
+		},
+		{
+			"Wrapped exit error",
+			fmt.Errorf("historical-logs: %w", &exitError{code: ExitTotalFailure, err: fmt.Errorf("unable to fetch the logs of any pod")}),
+			ExitTotalFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		if ExitCode(tt.Error) != tt.ExitCode {
+			t.Errorf("Expected exit code %d found %d", tt.ExitCode, ExitCode(tt.Error))
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// localFilter holds the level, message, container, host and log type filters
+// a backend leaves to the client, and the field filters, which the client
+// always applies. Messages are parsed by the parser, if any, before the logs
+// are filtered.
+type localFilter struct {
+	levels            []string
+	messages          []backend.MessageFilter
+	containers        []string
+	excludeContainers []string
+	hosts             []string
+	logType           string
+	fields            []logs.FieldFilter
+	parser            *logs.MessageParser
+}
+
+// messageFilters returns the filters selected by --grep and --regexp. A log
+// has to pass all of them.
+func (o *LogParameters) messageFilters() []backend.MessageFilter {
+
+	var filters []backend.MessageFilter
+	if len(o.Grep) > 0 {
+		filters = append(filters, backend.MessageFilter{Pattern: o.Grep, IgnoreCase: o.IgnoreCase, Invert: o.Invert})
+	}
+	if len(o.Regexp) > 0 {
+		filters = append(filters, backend.MessageFilter{Pattern: o.Regexp, Regexp: true, IgnoreCase: o.IgnoreCase, Invert: o.Invert})
+	}
+	return filters
+}
+
+// fieldFilters returns the filters selected by --where. A log has to pass all
+// of them. The filters are validated by ProcessLogParameters.
+func (o *LogParameters) fieldFilters() []logs.FieldFilter {
+
+	var filters []logs.FieldFilter
+	for _, where := range o.Where {
+		filter, err := logs.ParseFieldFilter(where)
+		if err == nil {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// messageParser returns the parser selected by --parse, or nil. The format is
+// validated by ProcessLogParameters.
+func (o *LogParameters) messageParser() *logs.MessageParser {
+	if len(o.Parse) == 0 {
+		return nil
+	}
+	parser, _ := logs.NewMessageParser(o.Parse, o.ParseRegexp)
+	return parser
+}
+
+// filteredLocally reports whether filters are left to the client, in which
+// case pages are fetched until enough logs match the filters.
+func (o *LogParameters) filteredLocally(logBackend backend.Backend) bool {
+	_, _, local := o.splitFilters(logBackend)
+	return !local.empty()
+}
+
+// splitFilters separates the levels and the message filters the backend
+// searches for in the store from those applied to the logs it returns.
+// Message filters the backend only narrows the search down with are both. The
+// level filter is validated by ProcessLogParameters.
+func (o *LogParameters) splitFilters(logBackend backend.Backend) ([]string, []backend.MessageFilter, localFilter) {
+
+	var searchedLevels []string
+	var searchedMessages []backend.MessageFilter
+	var local localFilter
+
+	levels, _ := logs.ParseLevels(o.Level)
+	if levelSearcher, ok := logBackend.(backend.LevelSearcher); ok && levelSearcher.SearchesLevels(levels) {
+		searchedLevels = levels
+	} else {
+		local.levels = levels
+	}
+
+	messageSearcher, _ := logBackend.(backend.MessageSearcher)
+	messagePrefilterer, _ := logBackend.(backend.MessagePrefilterer)
+	for _, filter := range o.messageFilters() {
+		switch {
+		case messageSearcher != nil && messageSearcher.SearchesMessages(filter):
+			searchedMessages = append(searchedMessages, filter)
+		case messagePrefilterer != nil && messagePrefilterer.PrefiltersMessages(filter):
+			searchedMessages = append(searchedMessages, filter)
+			local.messages = append(local.messages, filter)
+		default:
+			local.messages = append(local.messages, filter)
+		}
+	}
+
+	if !searchesContainers(logBackend, o.Containers, o.ExcludeContainers) {
+		local.containers = o.Containers
+		local.excludeContainers = o.ExcludeContainers
+	}
+	if !searchesHosts(logBackend) {
+		local.hosts = o.Nodes
+	}
+	if !searchesLogTypes(logBackend) {
+		local.logType = o.LogType
+	}
+	local.fields = o.fieldFilters()
+	local.parser = o.messageParser()
+	return searchedLevels, searchedMessages, local
+}
+
+// searchesHosts reports whether the backend selects the logs of nodes in the
+// store.
+func searchesHosts(logBackend backend.Backend) bool {
+	hostSearcher, ok := logBackend.(backend.HostSearcher)
+	return ok && hostSearcher.SearchesHosts()
+}
+
+// searchesLogTypes reports whether the backend selects the logs of a type in
+// the store.
+func searchesLogTypes(logBackend backend.Backend) bool {
+	logTypeSearcher, ok := logBackend.(backend.LogTypeSearcher)
+	return ok && logTypeSearcher.SearchesLogTypes()
+}
+
+// searchesUnits reports whether the backend stores journal logs and selects
+// them by unit.
+func searchesUnits(logBackend backend.Backend) bool {
+	unitSearcher, ok := logBackend.(backend.UnitSearcher)
+	return ok && unitSearcher.SearchesUnits()
+}
+
+// logHost returns the node a log was written on.
+func logHost(log logs.LogOptions) string {
+	if len(log.Source.Kubernetes.Host) > 0 {
+		return log.Source.Kubernetes.Host
+	}
+	return log.Source.Hostname
+}
+
+// searchesContainers reports whether the backend selects the containers
+// matching the patterns in the store.
+func searchesContainers(logBackend backend.Backend, containers []string, excludeContainers []string) bool {
+	containerSearcher, ok := logBackend.(backend.ContainerSearcher)
+	return ok && containerSearcher.SearchesContainers(containers) && containerSearcher.SearchesContainers(excludeContainers)
+}
+
+// containersFilteredLocally reports whether the logs of some pods are kept
+// for their containers by the client.
+func containersFilteredLocally(logBackend backend.Backend, podList []k8sresources.Pod) bool {
+	for _, pod := range podList {
+		if len(pod.Containers) > 0 && !searchesContainers(logBackend, pod.Containers, nil) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f localFilter) empty() bool {
+	return len(f.levels) == 0 && len(f.messages) == 0 && len(f.containers) == 0 && len(f.excludeContainers) == 0 && len(f.hosts) == 0 && len(f.logType) == 0 && len(f.fields) == 0
+}
+
+// apply keeps the logs whose level is one of the levels, whose message passes
+// every message filter, whose container matches the container patterns, which
+// were written on one of the hosts, which are of the log type and whose fields
+// pass every field filter.
+func (f localFilter) apply(logList []logs.LogOptions) ([]logs.LogOptions, error) {
+
+	if f.parser != nil {
+		parsed := make([]logs.LogOptions, 0, len(logList))
+		for _, log := range logList {
+			parsed = append(parsed, f.parser.Parse(log))
+		}
+		logList = parsed
+	}
+	if f.empty() {
+		return logList, nil
+	}
+
+	var matchers []func(message string) bool
+	for _, filter := range f.messages {
+		matcher, err := filter.Compile()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	levels := map[string]bool{}
+	for _, level := range f.levels {
+		levels[level] = true
+	}
+	hosts := map[string]bool{}
+	for _, host := range f.hosts {
+		hosts[host] = true
+	}
+
+	var filtered []logs.LogOptions
+	for _, log := range logList {
+		matches := len(levels) == 0 || levels[logs.NormalizeLevel(log.Source.Level)]
+		if matches && len(hosts) > 0 {
+			matches = hosts[logHost(log)]
+		}
+		if matches && len(f.logType) > 0 {
+			matches = log.LogType() == f.logType
+		}
+		if matches && (len(f.containers) > 0 || len(f.excludeContainers) > 0) {
+			matches = k8sresources.MatchesContainer(log.Source.Kubernetes.ContainerName, f.containers, f.excludeContainers)
+		}
+		for _, matcher := range matchers {
+			if !matches {
+				break
+			}
+			matches = matcher(log.Source.Message)
+		}
+		for _, fieldFilter := range f.fields {
+			if !matches {
+				break
+			}
+			matches = fieldFilter.Matches(log)
+		}
+		if matches {
+			filtered = append(filtered, log)
+		}
+	}
+	return filtered, nil
+}
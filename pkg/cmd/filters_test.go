@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestFilterMessages(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		LogParameters LogParameters
+		Messages      []string
+	}{
+		{
+			"No filter",
+			LogParameters{},
+			[]string{"GET /healthz 200", "Connection timeout (5s)", "connection TIMEOUT", "E0318 failed"},
+		},
+		{
+			"Substring",
+			LogParameters{Grep: "timeout ("},
+			[]string{"Connection timeout (5s)"},
+		},
+		{
+			"Substring ignoring case",
+			LogParameters{Grep: "TIMEOUT", IgnoreCase: true},
+			[]string{"Connection timeout (5s)", "connection TIMEOUT"},
+		},
+		{
+			"Regular expression",
+			LogParameters{Regexp: "^E[0-9]+ "},
+			[]string{"E0318 failed"},
+		},
+		{
+			"Inverted substring and regular expression",
+			LogParameters{Grep: "timeout", Regexp: "^GET", IgnoreCase: true, Invert: true},
+			[]string{"E0318 failed"},
+		},
+	}
+
+	var logList []logs.LogOptions
+	for _, message := range tests[0].Messages {
+		log := logs.LogOptions{}
+		log.Source.Message = message
+		logList = append(logList, log)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		filtered, err := localFilter{messages: tt.LogParameters.messageFilters()}.apply(logList)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		var messages []string
+		for _, log := range filtered {
+			messages = append(messages, log.Source.Message)
+		}
+		if strings.Join(messages, ",") != strings.Join(tt.Messages, ",") {
+			t.Errorf("Expected logs %v found %v", tt.Messages, messages)
+		}
+	}
+}
+
+func TestSplitMessageFilters(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		LogParameters LogParameters
+		Backend       backend.Backend
+		Searched      int
+		Local         int
+	}{
+		{
+			"Substring narrowing the elasticsearch search down",
+			LogParameters{Grep: "Timeout ("},
+			backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", ""),
+			1,
+			1,
+		},
+		{
+			"Inverted substring and regular expression with elasticsearch",
+			LogParameters{Grep: "timeout", Regexp: "^GET", Invert: true},
+			backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", ""),
+			0,
+			2,
+		},
+		{
+			"Substring searched for by loki",
+			LogParameters{Grep: "timeout", IgnoreCase: true},
+			backend.NewLokiBackend(http.DefaultClient, "http://localhost:8080", ""),
+			1,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		_, searched, local := tt.LogParameters.splitFilters(tt.Backend)
+		if len(searched) != tt.Searched || len(local.messages) != tt.Local {
+			t.Errorf("Expected %d searched and %d local filters found %v and %v", tt.Searched, tt.Local, searched, local.messages)
+		}
+	}
+}
+
+func TestFilterLevels(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, level := range []string{"Info", "WARN", "error", "", "critical", "debug"} {
+		log := logs.LogOptions{}
+		log.Source.Level = level
+		logList = append(logList, log)
+	}
+
+	for filter, expected := range map[string]string{
+		">=warning":     "WARN,error,critical",
+		"unknown,debug": ",debug",
+		"info":          "Info",
+	} {
+		levels, _ := logs.ParseLevels(filter)
+		filtered, err := localFilter{levels: levels}.apply(logList)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		var found []string
+		for _, log := range filtered {
+			found = append(found, log.Source.Level)
+		}
+		if strings.Join(found, ",") != expected {
+			t.Errorf("Expected levels %s for %s found %s", expected, filter, strings.Join(found, ","))
+		}
+	}
+
+	logParameters := LogParameters{Level: ">=warning"}
+	apiBackend := backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	if !logParameters.filteredLocally(apiBackend) {
+		t.Errorf("Expected several levels to be selected client-side for the API backend")
+	}
+	logParameters.Level = "error"
+	if logParameters.filteredLocally(apiBackend) {
+		t.Errorf("Expected a single level to be searched for by the API backend")
+	}
+}
+
+func TestFilterContainers(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, container := range []string{"app", "istio-proxy", "oauth-proxy", "setup"} {
+		log := logs.LogOptions{}
+		log.Source.Kubernetes.ContainerName = container
+		logList = append(logList, log)
+	}
+
+	tests := []struct {
+		TestName   string
+		Containers []string
+		Exclude    []string
+		Expected   string
+	}{
+		{"Containers", []string{"app", "set*"}, nil, "app,setup"},
+		{"Excluded containers", nil, []string{"*-proxy"}, "app,setup"},
+		{"Containers and excluded containers", []string{"*-proxy"}, []string{"istio-*"}, "oauth-proxy"},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		filtered, err := localFilter{containers: tt.Containers, excludeContainers: tt.Exclude}.apply(logList)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		var found []string
+		for _, log := range filtered {
+			found = append(found, log.Source.Kubernetes.ContainerName)
+		}
+		if strings.Join(found, ",") != tt.Expected {
+			t.Errorf("Expected containers %s found %s", tt.Expected, strings.Join(found, ","))
+		}
+	}
+
+	logParameters := LogParameters{Resources: k8sresources.Resources{ExcludeContainers: []string{"istio-proxy"}}}
+	apiBackend := backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	if !logParameters.filteredLocally(apiBackend) {
+		t.Errorf("Expected containers to be selected client-side for the API backend")
+	}
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	if logParameters.filteredLocally(esBackend) {
+		t.Errorf("Expected containers to be searched for by the elasticsearch backend")
+	}
+	query, _, _ := logParameters.query(esBackend, "openshift-logging", []string{"pod-1"})
+	if strings.Join(query.ExcludeContainers, ",") != "istio-proxy" {
+		t.Errorf("Expected excluded containers %v found %v", logParameters.ExcludeContainers, query.ExcludeContainers)
+	}
+}
+
+func TestFilterHosts(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, host := range []string{"worker-0", "worker-1", ""} {
+		log := logs.LogOptions{}
+		log.Source.Kubernetes.Host = host
+		log.Source.Hostname = "worker-1"
+		logList = append(logList, log)
+	}
+
+	filtered, err := localFilter{hosts: []string{"worker-1"}}.apply(logList)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(filtered) != 2 || filtered[0].Source.Kubernetes.Host != "worker-1" || filtered[1].Source.Kubernetes.Host != "" {
+		t.Errorf("Expected the logs of %s found %v", "worker-1", filtered)
+	}
+
+	logParameters := LogParameters{Resources: k8sresources.Resources{Nodes: []string{"worker-1"}}}
+	lokiBackend := backend.NewLokiBackend(http.DefaultClient, "http://localhost:3100", "")
+	query, local, _ := logParameters.query(lokiBackend, "openshift-logging", []string{"pod-1"})
+	if strings.Join(query.Hosts, ",") != "worker-1" || !local.empty() {
+		t.Errorf("Expected hosts %v to be searched for by the loki backend, found %v", logParameters.Nodes, query.Hosts)
+	}
+}
+
+func TestFilterLogTypes(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, index := range []string{"app-000001", "infra-000001", "audit-000001"} {
+		logList = append(logList, logs.LogOptions{Index: index})
+	}
+
+	filtered, err := localFilter{logType: logs.LogTypeInfrastructure}.apply(logList)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(filtered) != 1 || filtered[0].Index != "infra-000001" {
+		t.Errorf("Expected the logs of index %s found %v", "infra-000001", filtered)
+	}
+
+	logParameters := LogParameters{LogType: logs.LogTypeAudit, Resources: k8sresources.Resources{Containers: []string{"app"}}}
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	query, local, _ := logParameters.unitsQuery(esBackend, []string{"kubelet.service"})
+	if query.LogType != logs.LogTypeInfrastructure || strings.Join(query.Units, ",") != "kubelet.service" || len(query.Containers) > 0 || !local.empty() {
+		t.Errorf("Expected the infrastructure logs of unit %s to be searched for, found %+v", "kubelet.service", query)
+	}
+
+	apiBackend := backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080")
+	query, local, _ = logParameters.query(apiBackend, "openshift-logging", []string{"pod-1"})
+	if len(query.LogType) > 0 || local.logType != logs.LogTypeAudit {
+		t.Errorf("Expected log type %s to be filtered locally, found %+v", logs.LogTypeAudit, local)
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, document := range []string{
+		`{"_id":"1","_source":{"kubernetes":{"labels":{"app":"web"}},"structured":{"status":503}}}`,
+		`{"_id":"2","_source":{"kubernetes":{"labels":{"app":"web"}},"structured":{"status":200}}}`,
+		`{"_id":"3","_source":{"kubernetes":{"flat_labels":["app=api"]}}}`,
+	} {
+		log := logs.LogOptions{}
+		err := json.Unmarshal([]byte(document), &log)
+		if err != nil {
+			t.Fatalf("unable to unmarshal test log: %v", err)
+		}
+		logList = append(logList, log)
+	}
+
+	logParameters := LogParameters{Where: []string{"kubernetes.labels.app=web", "structured.status!~^2"}}
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	if !logParameters.filteredLocally(esBackend) {
+		t.Errorf("Expected field filters to be applied locally")
+	}
+	_, _, local := logParameters.splitFilters(esBackend)
+	filtered, err := local.apply(logList)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != "1" {
+		t.Errorf("Expected log %s found %v", "1", filtered)
+	}
+}
+
+func TestFilterParsedFields(t *testing.T) {
+	var logList []logs.LogOptions
+	for _, message := range []string{
+		`level=error msg="upstream unavailable" status=503`,
+		`level=info msg="request done" status=200`,
+		"plain text",
+	} {
+		log := logs.LogOptions{}
+		log.Source.Message = message
+		logList = append(logList, log)
+	}
+
+	logParameters := LogParameters{Parse: "logfmt", Where: []string{"structured.status=~^5"}}
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	_, _, local := logParameters.splitFilters(esBackend)
+	filtered, err := local.apply(logList)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(filtered) != 1 || filtered[0].Source.Structured["msg"] != "upstream unavailable" {
+		t.Errorf("Expected log %q found %v", "upstream unavailable", filtered)
+	}
+
+	logParameters = LogParameters{Parse: "logfmt"}
+	_, _, local = logParameters.splitFilters(esBackend)
+	parsed, err := local.apply(logList)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if len(parsed) != len(logList) || parsed[1].Source.Structured["status"] != "200" || parsed[2].Source.Structured != nil {
+		t.Errorf("Expected every log to be returned parsed, found %v", parsed)
+	}
+}
+
+func TestPageLogsWithMessageFilters(t *testing.T) {
+	// 2500 logs one second apart, every tenth of them is an error
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			start, _ := time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+			maxLogs, _ := strconv.Atoi(query.Get("/maxlogs/"))
+
+			var page []string
+			for index := 2499; index >= 0 && len(page) < maxLogs; index-- {
+				timestamp := base.Add(time.Duration(index) * time.Second)
+				if timestamp.Before(start) || timestamp.After(end) {
+					continue
+				}
+				message := fmt.Sprintf("info %d", index)
+				if index%10 == 0 {
+					message = fmt.Sprintf("error %d", index)
+				}
+				page = append(page, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%s","@timestamp":"%s"}}`, index, message, timestamp.Format(time.RFC3339Nano)))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	apiBackend := backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs")
+	logParameters := LogParameters{
+		Limit:      150,
+		Grep:       "ERROR",
+		IgnoreCase: true,
+		StartTime:  base.Format(time.RFC3339Nano),
+		EndTime:    base.Add(time.Hour).Format(time.RFC3339Nano),
+	}
+	if !logParameters.filteredLocally(apiBackend) {
+		t.Errorf("Expected message filters to be applied client-side for the API backend")
+	}
+
+	out := &bytes.Buffer{}
+	printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+	err := logParameters.printPodsLogs(context.Background(), apiBackend, []k8sresources.Pod{{Name: "pod-1"}}, printer)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 150 {
+		t.Fatalf("Expected %d logs found %d", 150, len(lines))
+	}
+	if lines[0] != "error 2490" || lines[149] != "error 1000" {
+		t.Errorf("Expected logs from %q to %q found %q to %q", "error 2490", "error 1000", lines[0], lines[149])
+	}
+}
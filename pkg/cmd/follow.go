@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+)
+
+// logFollower turns the overlapping results of successive polls into a single
+// stream of logs ordered by timestamp, printing every log only once.
+type logFollower struct {
+	cursor  time.Time
+	seen    map[string]time.Time
+	pending map[string]logs.LogOptions
+}
+
+func newLogFollower(cursor time.Time) *logFollower {
+	return &logFollower{
+		cursor:  cursor,
+		seen:    map[string]time.Time{},
+		pending: map[string]logs.LogOptions{},
+	}
+}
+
+// logKey identifies a log entry across polls. The document ID is preferred,
+// the ViaQ message ID is used for stores that do not return one.
+func logKey(log logs.LogOptions) string {
+	if len(log.ID) > 0 {
+		return log.ID
+	}
+	if len(log.Source.ViaqMsgID) > 0 {
+		return log.Source.ViaqMsgID
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", log.Source.Timestamp.Format(time.RFC3339Nano), log.Source.Kubernetes.PodName, log.Source.Kubernetes.ContainerName, log.Source.Message)
+}
+
+// add buffers logs that were neither printed nor buffered before. Logs older
+// than the cursor arrived after their reorder window was closed and are dropped.
+func (f *logFollower) add(logList []logs.LogOptions) {
+	for _, log := range logList {
+		if log.Source.Timestamp.Before(f.cursor) {
+			continue
+		}
+		key := logKey(log)
+		if _, ok := f.seen[key]; ok {
+			continue
+		}
+		f.pending[key] = log
+	}
+}
+
+// flush returns, in ascending timestamp order, every buffered log that is not
+// newer than watermark and moves the cursor forward to watermark.
+func (f *logFollower) flush(watermark time.Time) []logs.LogOptions {
+	var logList []logs.LogOptions
+	for key, log := range f.pending {
+		if log.Source.Timestamp.After(watermark) {
+			continue
+		}
+		logList = append(logList, log)
+		f.seen[key] = log.Source.Timestamp
+		delete(f.pending, key)
+	}
+
+	sort.SliceStable(logList, func(index1, index2 int) bool {
+		return logList[index1].Source.Timestamp.Before(logList[index2].Source.Timestamp)
+	})
+
+	if watermark.After(f.cursor) {
+		f.cursor = watermark
+	}
+	for key, timestamp := range f.seen {
+		if timestamp.Before(f.cursor) {
+			delete(f.seen, key)
+		}
+	}
+	return logList
+}
+
+// flushAll returns every buffered log regardless of the reorder window.
+func (f *logFollower) flushAll() []logs.LogOptions {
+	var latest time.Time
+	for _, log := range f.pending {
+		if log.Source.Timestamp.After(latest) {
+			latest = log.Source.Timestamp
+		}
+	}
+	return f.flush(latest)
+}
+
+func (o *LogParameters) followLogs(ctx context.Context, logBackend backend.Backend, podList []k8sresources.Pod, printer printers.LogPrinter) error {
+
+	cursor := time.Now().UTC()
+	if len(o.StartTime) > 0 {
+		startTime, err := time.Parse(time.RFC3339Nano, o.StartTime)
+		if err != nil {
+			return fmt.Errorf("an invalid start time was entered: %v", err)
+		}
+		cursor = startTime
+	}
+
+	follower := newLogFollower(cursor)
+	ticker := time.NewTicker(o.FollowInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		pollParameters := *o
+		pollParameters.StartTime = follower.cursor.UTC().Format(time.RFC3339Nano)
+		pollParameters.EndTime = now.Format(time.RFC3339Nano)
+		if pollParameters.paged() {
+			pollParameters.Limit = constants.LimitUpperBound
+		}
+
+		// every log since the cursor is fetched, Limit is the size of a page
+		follower.add(fetchPodsLogs(ctx, logBackend, &pollParameters, podList))
+
+		err := printLogStream(follower.flush(now.Add(-o.ReorderWindow)), printer)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			err = printLogStream(follower.flushAll(), printer)
+			if err != nil {
+				return err
+			}
+			return printer.Flush()
+		case <-ticker.C:
+		}
+	}
+}
+
+func printLogStream(logList []logs.LogOptions, printer printers.LogPrinter) error {
+	for _, log := range logList {
+		err := printer.PrintLog(log)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
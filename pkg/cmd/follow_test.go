@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func testFollowLog(id string, message string, timestamp time.Time) logs.LogOptions {
+	log := logs.LogOptions{ID: id}
+	log.Source.Message = message
+	log.Source.Timestamp = timestamp
+	return log
+}
+
+func TestLogFollower(t *testing.T) {
+	base := time.Date(2021, 3, 18, 6, 41, 0, 0, time.UTC)
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Polls      [][]logs.LogOptions
+		Watermarks []time.Time
+		Messages   []string
+	}{
+		{
+			"Logs are printed in timestamp order",
+			false,
+			[][]logs.LogOptions{{
+				testFollowLog("2", "second", base.Add(2*time.Second)),
+				testFollowLog("1", "first", base.Add(time.Second)),
+			}},
+			[]time.Time{base.Add(10 * time.Second)},
+			[]string{"first", "second"},
+		},
+		{
+			"Logs returned by overlapping polls are printed once",
+			false,
+			[][]logs.LogOptions{
+				{testFollowLog("1", "first", base.Add(time.Second))},
+				{testFollowLog("1", "first", base.Add(time.Second)), testFollowLog("2", "second", base.Add(2*time.Second))},
+			},
+			[]time.Time{base.Add(time.Second), base.Add(10 * time.Second)},
+			[]string{"first", "second"},
+		},
+		{
+			"Logs inside the reorder window are held back",
+			false,
+			[][]logs.LogOptions{
+				{testFollowLog("2", "second", base.Add(2*time.Second))},
+				{testFollowLog("1", "first", base.Add(time.Second))},
+			},
+			[]time.Time{base, base.Add(10 * time.Second)},
+			[]string{"first", "second"},
+		},
+		{
+			"Logs older than the cursor are dropped",
+			false,
+			[][]logs.LogOptions{
+				{testFollowLog("2", "second", base.Add(2*time.Second))},
+				{testFollowLog("1", "first", base.Add(time.Second))},
+			},
+			[]time.Time{base.Add(5 * time.Second), base.Add(10 * time.Second)},
+			[]string{"second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		follower := newLogFollower(base)
+		var messages []string
+		for index, poll := range tt.Polls {
+			follower.add(poll)
+			for _, log := range follower.flush(tt.Watermarks[index]) {
+				messages = append(messages, log.Source.Message)
+			}
+		}
+
+		if strings.Join(messages, ",") != strings.Join(tt.Messages, ",") {
+			t.Errorf("Expected logs %v found %v", tt.Messages, messages)
+		}
+	}
+}
+
+func TestFollowLogs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": {
+				`{"_id":"1","_source":{"message":"first","@timestamp":"2021-03-18T06:41:17.541712+00:00"}}`,
+				`{"_id":"2","_source":{"message":"second","@timestamp":"2021-03-18T06:41:18.541712+00:00"}}`,
+			}})
+		})
+
+	logParameters := LogParameters{
+		StartTime:      "2021-03-18T06:41:00Z",
+		Limit:          5,
+		Follow:         true,
+		FollowInterval: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+	err := logParameters.followLogs(ctx, backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), []k8sresources.Pod{{Name: "pod-1"}, {Name: "pod-2"}}, printer)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if out.String() != "first\nsecond\n" {
+		t.Errorf("Expected output %q found %q", "first\nsecond\n", out.String())
+	}
+	if httpmock.GetTotalCallCount() < 4 {
+		t.Errorf("Expected at least two polls for two pods, found %d requests", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestFollowLogsPagesForward(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:9200/app-*,infra-*,audit-*/_search?ignore_unavailable=true&allow_no_indices=true",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Size        int           `json:"size"`
+				SearchAfter []interface{} `json:"search_after"`
+			}{}
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(requestBody, &body)
+
+			// five logs indexed since the cursor, paged oldest first by search_after
+			next := 1
+			if len(body.SearchAfter) > 0 {
+				next = int(body.SearchAfter[0].(float64)) + 1
+			}
+			var hits []string
+			for value := next; value <= 5 && len(hits) < body.Size; value++ {
+				hits = append(hits, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%d","@timestamp":"2021-03-18T06:41:1%dZ"},"sort":[%d,"%d"]}`, value, value, value, value, value))
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[`+strings.Join(hits, ",")+`]}}`), nil
+		})
+
+	logParameters := LogParameters{
+		StartTime:      "2021-03-18T06:41:00Z",
+		Limit:          2,
+		Follow:         true,
+		FollowInterval: time.Hour,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	err := logParameters.followLogs(ctx, esBackend, []k8sresources.Pod{{Name: "pod-1"}}, printer)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if out.String() != "1\n2\n3\n4\n5\n" {
+		t.Errorf("Expected output %q found %q", "1\n2\n3\n4\n5\n", out.String())
+	}
+}
+
+func TestFollowLogsCanceled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	errOut := &bytes.Buffer{}
+	logParameters := LogParameters{
+		StartTime:      "2021-03-18T06:41:00Z",
+		Limit:          5,
+		Follow:         true,
+		FollowInterval: time.Hour,
+		errOut:         errOut,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := &bytes.Buffer{}
+	printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+	err := logParameters.followLogs(ctx, backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), []k8sresources.Pod{{Name: "pod-1"}, {Name: "pod-2"}}, printer)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+	if errOut.Len() > 0 {
+		t.Errorf("Expected no warnings found %q", errOut.String())
+	}
+}
@@ -1,18 +1,21 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"sort"
+	"io"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -22,56 +25,234 @@ import (
 var (
 	logsExample = templates.Examples(i18n.T(`
 		# Return snapshot historical-logs from pod openshift-apiserver-operator-849d7869ff-r94g8 with a maximum of 10 log extries
-		oc historical-logs podname=openshift-apiserver-operator-849d7869ff-r94g8 --limit=10
+		oc historical-logs pod/openshift-apiserver-operator-849d7869ff-r94g8 --limit=10
 		
 		# Return snapshot of historical-logs from pods of stateful set prometheus from namespace openshift-apiserver-operator and logging level info
-		oc historical-logs statefulset=prometheus --namespace=openshift-apiserver-operator --level=info
+		oc historical-logs statefulset/prometheus --namespace=openshift-apiserver-operator --level=info
+		
+		# Return snapshot of historical-logs from pods of deployment kibana of level warning and more severe levels
+		oc historical-logs deployment/kibana '--level>=warning'
+		
+		# Return snapshot of historical-logs from pods of deployment kibana of levels error and critical
+		oc historical-logs deployment/kibana --level=error,critical
 		
 		# Return snapshot of historical-logs from pods of stateful set nginx in the current namespace with pod name and container name as log prefix
-		oc historical-logs statefulset=nginx --prefix=true
+		oc historical-logs statefulset/nginx --prefix=true
 		
 		# Return snapshot of historical-logs from pods of deployment kibana in the namespace openshift-logging with a maximum of 100 log entries
-		oc historical-logs deployment=kibana --namespace=openshift-logging --limit=100
+		oc historical-logs deployment/kibana --namespace=openshift-logging --limit=100
 		
 		# Return snapshot of historical-logs from pods of daemon set fluentd in the current namespace
-		oc historical-logs daemonset=fluentd
+		oc historical-logs daemonset/fluentd
+		
+		# Return snapshot of historical-logs from pods of deployments kibana and log-exploration-api in the namespace openshift-logging
+		oc historical-logs deployment/kibana deployment/log-exploration-api --namespace=openshift-logging
+		
+		# Return snapshot of historical-logs from all containers of the pods labelled app=nginx in the current namespace
+		oc historical-logs -l app=nginx --all-containers
+		
+		# Return snapshot of historical-logs from pods of all jobs started by cron job backup in the current namespace
+		oc historical-logs cronjob/backup
+		
+		# Return snapshot of historical-logs from pods backing service log-exploration-api in the namespace openshift-logging
+		oc historical-logs svc/log-exploration-api --namespace=openshift-logging
+		
+		# Return snapshot of historical-logs from the containers of deployment payments whose name ends with -worker
+		oc historical-logs deployment/payments -c '*-worker'
+		
+		# Return snapshot of historical-logs from all containers of deployment payments except the service mesh sidecar
+		oc historical-logs deployment/payments --all-containers --exclude-container=istio-proxy
+		
+		# Return snapshot of historical-logs from the init container setup of pod payments-7d9f8-x2x4v
+		oc historical-logs pod/payments-7d9f8-x2x4v -c setup
+		
+		# Return every log of the last 30 minutes from the containers of node worker-0, including pods which no longer exist
+		oc historical-logs node/worker-0 --since=30m --all --historical-pods
+		
+		# Return snapshot of historical-logs from the pods of daemon set fluentd running on nodes worker-0 and worker-1
+		oc historical-logs daemonset/fluentd --node=worker-0,worker-1
+		
+		# Return the journal logs of the kubelet and CRI-O on node worker-0 from the last hour, prefixed with their unit
+		oc historical-logs unit/kubelet.service unit/crio --node=worker-0 --since=1h --prefix --backend=elasticsearch --es-url=https://elasticsearch.example.com:9200
+		
+		# Return the boot ID, PID and message of the kubelet journal logs as CSV
+		oc historical-logs unit/kubelet -o csv --columns=timestamp,hostname,bootid,pid,message --backend=loki --loki-url=https://logging-loki-openshift-logging.apps.example.com
+		
+		# Return snapshot of historical-logs of level error from pods of deployment kibana, application logs only
+		oc historical-logs deployment/kibana --level=error --log-type=application
+		
+		# Return snapshot of historical-logs from pods of deployment payments in the namespaces team-a and team-b
+		oc historical-logs deployment/payments --namespaces=team-a,team-b
+		
+		# Return snapshot of historical-logs from the pods labelled app=nginx in every namespace
+		oc historical-logs -l app=nginx --all-namespaces
+		
+		# Return snapshot of historical-logs from pods of deployment payments in the namespaces labelled team=payments
+		oc historical-logs deployment/payments --namespace-selector=team=payments --prefix
+		
+		# Return snapshot of historical-logs from pods of deployment config frontend and build pods of build config frontend
+		oc historical-logs dc/frontend bc/frontend
+		
+		# Return snapshot of historical-logs from pods of deployment kibana, including pods of older revisions which were replaced
+		oc historical-logs deployment/kibana --historical-pods --tail=1d
 		
 		# Return snapshot logs of pods in deployment cluster-logging-operator in a time range between current time - 5 minutes and current time
-		oc historical-logs deployment=cluster-logging-operator --tail=5m
+		oc historical-logs deployment/cluster-logging-operator --tail=5m
+		
+		# Return the last 20 logs of pods in deployment kibana mentioning a timeout, ignoring case
+		oc historical-logs deployment/kibana --grep=timeout --ignore-case --limit=20
+		
+		# Return snapshot logs of pods in deployment kibana whose message does not match a regular expression
+		oc historical-logs deployment/kibana --regexp='^(GET|POST) /healthz' --invert
+		
+		# Return the exceptions logged by pods of deployment kibana with the 3 logs before and the 10 logs after each of them
+		oc historical-logs deployment/kibana --grep=Exception -B 3 -a 10 --prefix
 		
 		# Return snapshot logs for pods in deployment log-exploration-api in the last 10 seconds
-		oc historical-logs deployment=log-exploration-api --tail=10s`))
+		oc historical-logs deployment/log-exploration-api --tail=10s
+		
+		# Return snapshot logs of pods in deployment kibana for the last one and a half hours
+		oc historical-logs deployment/kibana --since=1h30m
+		
+		# Return snapshot logs of pods in deployment kibana between 08:00 and 09:00 UTC on 18 March 2021
+		oc historical-logs deployment/kibana --since-time='2021-03-18 08:00' --until-time='2021-03-18 09:00' --timezone=UTC
+		
+		# Return snapshot logs of pods in deployment kibana from yesterday, up to two hours ago
+		oc historical-logs deployment/kibana --since-time=yesterday --until-time=now-2h
+		
+		# Return every log of pods in deployment kibana from the last day, paging through the API
+		oc historical-logs deployment/kibana --since=1d --all
+		
+		# Return the last 50 logs of pods in deployment kibana, oldest first
+		oc historical-logs deployment/kibana --limit=50 --reverse
+		
+		# Return every log of pods in daemon set fluentd from the last hour in chronological order
+		oc historical-logs daemonset/fluentd --since=1h --all --order=asc
+		
+		# Return snapshot logs of the pods of daemon set fluentd, with at most 5 requests at a time, each given up after 30 seconds
+		oc historical-logs daemonset/fluentd --max-concurrency=5 --request-timeout=30s --timeout=5m
+		
+		# Return snapshot logs of the pods of daemon set fluentd, failing when the logs of any pod could not be fetched
+		oc historical-logs daemonset/fluentd --fail-on-partial
+		
+		# Stream logs for pods in deployment log-exploration-api as they are indexed, starting 5 minutes ago
+		oc historical-logs deployment/log-exploration-api --tail=5m --follow
+		
+		# Return snapshot logs for pods in deployment kibana as JSON lines
+		oc historical-logs deployment/kibana --namespace=openshift-logging -o jsonl
+		
+		# Return snapshot logs for pods in daemon set fluentd as CSV with the selected columns
+		oc historical-logs daemonset/fluentd -o csv --columns=timestamp,pod,level,message
+		
+		# Return snapshot logs for pods in deployment kibana formatted with a Go template
+		oc historical-logs deployment/kibana -o template --template='{{._source.kubernetes.pod_name}}: {{._source.message}}'
+		
+		# Return snapshot logs of the pods labelled app=web in the current namespace which answered with a server error, with the version label as CSV
+		oc historical-logs -l app=web --where='structured.status=~^5' -o csv --columns=timestamp,pod,kubernetes.labels.app.kubernetes.io/version,message
+		
+		# Return snapshot logs for pods in deployment payments whose JSON messages carry a server error status, printed as readable lines
+		oc historical-logs deployment/payments --parse=json --where='structured.status=~^5' --pretty
+		
+		# Return snapshot logs for pods in deployment api with the duration of their logfmt messages as CSV
+		oc historical-logs deployment/api --parse=logfmt -o csv --columns=timestamp,pod,structured.duration,message
+		
+		# Return snapshot logs for pods in deployment router whose access logs are requests for /api, parsed with a regular expression
+		oc historical-logs deployment/router --parse=regex --parse-regexp='(?P<method>[A-Z]+) (?P<path>\S+) (?P<status>[0-9]{3})' --where='structured.path=~^/api'
+		
+		# Return the requests which deleted config maps in namespace project-a during the last day
+		oc historical-logs audit --verb=delete --resource=configmaps --namespace=project-a --since=1d --es-url=https://elasticsearch.example.com:9200
+		
+		# Return snapshot logs for pods in deployment kibana from a log-exploration API at a custom URL
+		oc historical-logs deployment/kibana --api-url=https://log-exploration-api.example.com
+		
+		# Return snapshot logs for pods in deployment kibana from a log-exploration API served with a custom CA
+		oc historical-logs deployment/kibana --api-ca-file=/etc/pki/ingress-ca.crt
+		
+		# Return snapshot logs for pods in deployment kibana directly from the application indices of an Elasticsearch cluster
+		oc historical-logs deployment/kibana --backend=elasticsearch --es-url=https://elasticsearch.example.com:9200 --es-indices=app-*
+		
+		# Return snapshot logs for pods in deployment kibana at level error from the LokiStack of the cluster
+		oc historical-logs deployment/kibana --namespace=openshift-logging --level=error --backend=loki --loki-url=https://logging-loki-openshift-logging.apps.example.com`))
 )
 
-type ResponseLogs struct {
-	Logs  []string
-	Error string
-}
-
 type LogParameters struct {
-	Namespace string
-	Tail      string
-	StartTime string
-	EndTime   string
-	Level     string
-	Limit     int
-	Prefix    bool
+	Namespace         string
+	AllNamespaces     bool
+	Namespaces        []string
+	NamespaceSelector string
+	Tail              string
+	StartTime         string
+	EndTime           string
+	Level             string
+	LogType           string
+	Limit             int
+	Prefix            bool
+	Output            string
+	Template          string
+	Columns           []string
+	APIUrl            string
+	APINamespace      string
+	APICAFile         string
+	APIClientCert     string
+	APIClientKey      string
+	APIInsecure       bool
+	StoreCAFile       string
+	StoreClientCert   string
+	StoreClientKey    string
+	StoreInsecure     bool
+	StoreToken        string
+	Follow            bool
+	FollowInterval    time.Duration
+	ReorderWindow     time.Duration
+	HistoricalPods    bool
+	All               bool
+	Since             string
+	SinceTime         string
+	UntilTime         string
+	Timezone          string
+	Backend           string
+	ESUrl             string
+	ESIndices         string
+	LokiUrl           string
+	LokiTenant        string
+	Grep              string
+	Regexp            string
+	IgnoreCase        bool
+	Invert            bool
+	BeforeContext     int
+	AfterContext      int
+	Context           int
+	Order             string
+	Reverse           bool
+	MaxConcurrency    int
+	Timeout           time.Duration
+	FailOnPartial     bool
+	Where             []string
+	Parse             string
+	ParseRegexp       string
+	Pretty            bool
+	namespaces        []string
+	errOut            io.Writer
 	k8sresources.Resources
 }
 
 func NewCmdLogFilter(streams genericclioptions.IOStreams) *cobra.Command {
 
 	o := &LogParameters{}
+	configFlags := genericclioptions.NewConfigFlags(true)
 
 	cmd := &cobra.Command{
-		Use:     "historical-logs [resource-type]=[resource-name] [flags]",
+		Use:     "historical-logs [resource-type]/[resource-name]... [flags]",
 		Short:   "View logs filtered on various parameters",
 		Example: logsExample,
+		// resources are arguments of the command, not names of subcommands
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			kubernetesOptions, err := client.KubernetesClient()
+			kubernetesOptions, err := client.KubernetesClient(configFlags)
 			if err != nil {
 				return err
 			}
+			o.Namespace = *configFlags.Namespace
 			err = o.Execute(kubernetesOptions, streams, args)
 			if err != nil {
 				return err
@@ -81,141 +262,192 @@ func NewCmdLogFilter(streams genericclioptions.IOStreams) *cobra.Command {
 	}
 
 	o.AddFlags(cmd)
+	configFlags.AddFlags(cmd.Flags())
+	cmd.AddCommand(NewCmdAudit(streams))
 	return cmd
 }
 
 func (o *LogParameters) AddFlags(cmd *cobra.Command) {
 
-	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Extract Historical logs from a specific namespace")
-	cmd.Flags().StringVar(&o.Tail, "tail", "", "Fetch Historical logs for the last N seconds, minutes, hours, or days")
-	cmd.Flags().StringVar(&o.Level, "level", "", "Fetch Historical logs from different logging level, Example: Info,debug,Error,Unknown, etc")
-	cmd.Flags().IntVar(&o.Limit, "limit", constants.LimitUpperBound, "Specify number of documents [logs] to be fetched")
-	cmd.Flags().BoolVar(&o.Prefix, "prefix", false, "Prefix each log with the log source (pod name and container name)")
+	o.addTimeRangeFlags(cmd)
+	o.addStoreFlags(cmd, constants.BackendAPI, Backends())
+
+	cmd.Flags().StringVar(&o.Level, "level", "", "Fetch Historical logs of a comma separated list of levels, e.g. error,critical, or of a threshold, e.g. '>=warning' or '<info'. One of: "+strings.Join(logs.Levels(), ",")+", aliases like error, warn and critical are accepted")
+	cmd.Flags().StringVar(&o.LogType, "log-type", "", "Fetch Historical logs of one type only. One of: "+strings.Join(logs.LogTypes(), "|")+". Journal logs of units are infrastructure logs")
+	cmd.Flags().StringVar(&o.Grep, "grep", "", "Fetch Historical logs whose message contains this text. Searched for in the log store when the backend supports it")
+	cmd.Flags().StringVar(&o.Regexp, "regexp", "", "Fetch Historical logs whose message matches this regular expression (RE2 syntax)")
+	cmd.Flags().StringArrayVar(&o.Where, "where", nil, "Fetch Historical logs whose document field, given by its dotted path, passes a filter like kubernetes.labels.app=web. Supports '=', '!=', '=~' and '!~' with a regular expression, can be repeated")
+	cmd.Flags().StringVar(&o.Parse, "parse", "", "Parse the message of logs the collector did not parse into structured fields. One of: "+strings.Join(logs.ParseFormats(), "|")+". The regex format takes --parse-regexp or an inline expression, e.g. --parse='regex=(?P<status>[0-9]{3})'")
+	cmd.Flags().StringVar(&o.ParseRegexp, "parse-regexp", "", "Regular expression (RE2 syntax) whose named groups are the fields of messages parsed with --parse=regex, e.g. '(?P<method>[A-Z]+) (?P<path>\\S+)'")
+	cmd.Flags().BoolVar(&o.Pretty, "pretty", false, "Print the structured fields of logs as readable lines of their time, level, message and remaining fields, with the raw output format")
+	cmd.Flags().BoolVar(&o.IgnoreCase, "ignore-case", false, "Ignore case distinctions in --grep and --regexp")
+	cmd.Flags().BoolVar(&o.Invert, "invert", false, "Fetch Historical logs whose message matches neither --grep nor --regexp")
+	cmd.Flags().IntVarP(&o.AfterContext, "after-context", "a", 0, "Print this many logs of the same container after every matching log")
+	cmd.Flags().IntVarP(&o.BeforeContext, "before-context", "B", 0, "Print this many logs of the same container before every matching log")
+	cmd.Flags().IntVarP(&o.Context, "context-lines", "C", 0, "Print this many logs of the same container before and after every matching log")
+	cmd.Flags().StringVar(&o.Order, "order", constants.OrderDescending, "Order logs are printed in, by timestamp. One of: asc|desc. With a limit the newest logs are printed in either order. Ignored in follow mode")
+	cmd.Flags().BoolVar(&o.Reverse, "reverse", false, "Print logs in the opposite of --order, oldest first by default")
+	cmd.Flags().IntVar(&o.Limit, "limit", constants.LimitUpperBound, "Specify number of documents [logs] to be fetched. Values above "+strconv.Itoa(constants.LimitUpperBound)+" and 0, which fetches every log, page through the API")
+	cmd.Flags().BoolVar(&o.All, "all", false, "Fetch every log in the time range, same as --limit=0")
+	cmd.Flags().BoolVar(&o.Prefix, "prefix", false, "Prefix each log with the log source (pod name and container name, or unit and node of journal logs)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", false, "Fetch Historical logs of the requested resources in every namespace the user can list. Namespaces whose resources cannot be read are skipped with a warning")
+	cmd.Flags().StringSliceVar(&o.Namespaces, "namespaces", nil, "Comma separated namespaces to fetch Historical logs of the requested resources from, instead of --namespace")
+	cmd.Flags().StringVar(&o.NamespaceSelector, "namespace-selector", "", "Selector (label query) on namespaces to fetch Historical logs of the requested resources from, e.g. --namespace-selector team=payments")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "Selector (label query) to filter pods on, supports '=', '==', 'in', 'notin' and '!='")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", "", "Selector (field query) to filter pods on, supports '=', '==' and '!=', e.g. --field-selector spec.nodeName=node-1")
+	cmd.Flags().BoolVar(&o.AllContainers, "all-containers", false, "Get the logs of all containers of multi-container pods, including init and ephemeral containers, instead of the default container only")
+	cmd.Flags().StringSliceVar(&o.Nodes, "node", nil, "Fetch Historical logs of the requested resources from pods which ran on these comma separated nodes only")
+	cmd.Flags().StringSliceVarP(&o.Containers, "container", "c", nil, "Get the logs of the containers, init and ephemeral containers matching these comma separated shell patterns, e.g. -c 'app,*-worker'")
+	cmd.Flags().StringSliceVar(&o.ExcludeContainers, "exclude-container", nil, "Leave out the logs of the containers matching these comma separated shell patterns, e.g. --exclude-container=istio-proxy,oauth-proxy")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Output format. One of: "+strings.Join(printers.Formats(), "|")+". Template formats also accept the template inline, e.g. -o jsonpath={._source.message}")
+	cmd.Flags().StringVar(&o.Template, "template", "", "Template string or JSONPath expression used with -o template, go-template or jsonpath")
+	cmd.Flags().StringSliceVar(&o.Columns, "columns", nil, "Columns printed with -o csv. One of: "+strings.Join(printers.Columns(), ",")+", or the dotted path of a document field, e.g. kubernetes.labels.app")
+	cmd.Flags().StringVar(&o.APIUrl, "api-url", "", "URL of the log-exploration API. Defaults to $"+constants.APIUrlEnv+", then to the API route or service in --api-namespace")
+	cmd.Flags().StringVar(&o.APINamespace, "api-namespace", constants.APINamespace, "Namespace in which the log-exploration API route and service are looked up")
+	cmd.Flags().StringVar(&o.APICAFile, "api-ca-file", "", "Path to a CA bundle used to verify the log-exploration API certificate, in addition to the system roots")
+	cmd.Flags().StringVar(&o.APIClientCert, "api-client-cert", "", "Path to a client certificate presented to the log-exploration API")
+	cmd.Flags().StringVar(&o.APIClientKey, "api-client-key", "", "Path to the key of the client certificate presented to the log-exploration API")
+	cmd.Flags().StringVar(&o.LokiTenant, "loki-tenant", "", "LokiStack tenant queried by the loki backend. One of: application|infrastructure|audit. Defaults to infrastructure for default, openshift-* and kube-* namespaces and to application otherwise")
+	cmd.Flags().BoolVar(&o.APIInsecure, "api-insecure", false, "If true, the log-exploration API certificate will not be checked for validity")
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", false, "Keep polling for new logs and print them as they are indexed")
+	cmd.Flags().DurationVar(&o.FollowInterval, "follow-interval", constants.FollowInterval, "Time to wait between two polls in follow mode")
+	cmd.Flags().DurationVar(&o.ReorderWindow, "reorder-window", constants.ReorderWindow, "Hold back logs newer than this window in follow mode so that late arrivals are printed in timestamp order")
+	cmd.Flags().IntVar(&o.MaxConcurrency, "max-concurrency", constants.MaxConcurrency, "Maximum number of requests sent to the log store at the same time, 0 for no limit")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "Maximum time to fetch the logs, e.g. 30s or 2m. Every single request is limited by --request-timeout. 0 waits until all logs are fetched")
+	cmd.Flags().BoolVar(&o.FailOnPartial, "fail-on-partial", false, "Exit with code 3 when the logs of some pods could not be fetched, instead of 0")
+	cmd.Flags().BoolVar(&o.HistoricalPods, "historical-pods", false, "Also get the logs of pods which no longer exist, discovered from the log store by the labels and pod name pattern of the requested resources")
+}
+
+// addTimeRangeFlags adds the flags selecting the time range of the logs.
+func (o *LogParameters) addTimeRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Tail, "tail", "", "Fetch Historical logs for the last N seconds, minutes, hours, or days, same as --since")
+	cmd.Flags().StringVar(&o.Since, "since", "", "Fetch Historical logs newer than a relative duration like 30s, 1h30m, 0.5h, 2d or 1w")
+	cmd.Flags().StringVar(&o.SinceTime, "since-time", "", "Fetch Historical logs after a time in RFC3339 (2006-01-02T15:04:05Z), local (2006-01-02 15:04:05) or date (2006-01-02) format, or an expression like now-2h, today or yesterday")
+	cmd.Flags().StringVar(&o.UntilTime, "until-time", "", "Fetch Historical logs before a time, in the formats accepted by --since-time")
+	cmd.Flags().StringVar(&o.Timezone, "timezone", "", "Time zone of --since-time and --until-time values without a zone, e.g. UTC or Europe/Berlin. Defaults to the local time zone")
+}
+
+// addStoreFlags adds the flags selecting the log store the logs are read from,
+// one of backends.
+func (o *LogParameters) addStoreFlags(cmd *cobra.Command, defaultBackend string, backends []string) {
+	cmd.Flags().StringVar(&o.Backend, "backend", defaultBackend, "Store the logs are read from. One of: "+strings.Join(backends, "|"))
+	cmd.Flags().StringVar(&o.ESUrl, "es-url", "", "URL of the Elasticsearch cluster queried by the elasticsearch backend")
+	cmd.Flags().StringVar(&o.ESIndices, "es-indices", constants.ESIndices, "Comma separated indices, aliases or patterns queried by the elasticsearch backend")
+	cmd.Flags().StringVar(&o.LokiUrl, "loki-url", "", "URL of the LokiStack gateway queried by the loki backend")
+	cmd.Flags().StringVar(&o.StoreCAFile, "store-ca-file", "", "Path to a CA bundle used to verify the certificate of the Elasticsearch or LokiStack URL, in addition to the system roots")
+	cmd.Flags().StringVar(&o.StoreClientCert, "store-client-cert", "", "Path to a client certificate presented to the Elasticsearch or LokiStack URL")
+	cmd.Flags().StringVar(&o.StoreClientKey, "store-client-key", "", "Path to the key of the client certificate presented to the Elasticsearch or LokiStack URL")
+	cmd.Flags().BoolVar(&o.StoreInsecure, "store-insecure", false, "If true, the certificate of the Elasticsearch or LokiStack URL will not be checked for validity")
+	cmd.Flags().StringVar(&o.StoreToken, "store-token", "", "Bearer token sent to the Elasticsearch or LokiStack URL. The LokiStack gateway is sent the kubeconfig token by default, the other kubeconfig credentials are only sent to the log-exploration API")
 }
 
 func (o *LogParameters) Execute(kubernetesOptions *client.KubernetesOptions, streams genericclioptions.IOStreams, args []string) error {
+	o.errOut = streams.ErrOut
 	err := o.ProcessLogParameters(kubernetesOptions, args)
 
 	if err != nil {
 		return err
 	}
 
-	var podList []string
-
-	podList, err = k8sresources.GetResourcesPodList(kubernetesOptions, &o.Resources, o.Namespace)
-
+	o.namespaces, err = k8sresources.ResolveNamespaces(kubernetesOptions, k8sresources.NamespaceOptions{
+		AllNamespaces: o.AllNamespaces,
+		Namespaces:    o.Namespaces,
+		Selector:      o.NamespaceSelector,
+	}, o.Namespace)
 	if err != nil {
 		return err
 	}
 
-	endIndex := strings.LastIndex(kubernetesOptions.ClusterUrl, ":")
-	startIndex := strings.Index(kubernetesOptions.ClusterUrl, ".") + 1
-	clusterName := kubernetesOptions.ClusterUrl[startIndex:endIndex]
-	/*Example cluster URL : http://api.sangupta-tetrh.devcluster.openshift.com:6443. The first occurrence of '.' and last occurrence of ':'
-	act as start and end indices. Extract cluster name as substring using start and end Indices i.e, sangupta-tetrh.devcluster.openshift.com to build the log-exploration-api URL*/
-	baseUrl := "http://log-exploration-api-route-openshift-logging.apps." + clusterName + "/logs"
-
-	podLogsCh := make(chan []logs.LogOptions)
-	var logList []logs.LogOptions
-	for _, pod := range podList {
-		go FetchLogs(baseUrl, o, pod, podLogsCh, kubernetesOptions.ClusterToken)
+	printer, err := printers.NewLogPrinter(streams.Out, printers.PrintOptions{
+		Output:          o.Output,
+		Template:        o.Template,
+		Columns:         o.Columns,
+		Prefix:          o.Prefix,
+		PrefixNamespace: o.multiNamespace(),
+		Pretty:          o.Pretty,
+	})
+	if err != nil {
+		return err
 	}
 
-	for index := 0; index < len(podList); index++ {
-		podLogs := <-podLogsCh
-		logList = append(logList, podLogs...)
-	}
+	var podList []k8sresources.Pod
 
-	sort.Slice(logList, func(index1, index2 int) bool {
-		return logList[index1].Source.Timestamp.String() > logList[index2].Source.Timestamp.String()
+	podList, err = k8sresources.GetNamespacesPodList(kubernetesOptions, &o.Resources, o.namespaces, func(namespace string, err error) {
+		fmt.Fprintf(o.warnings(), "warning: skipping namespace %s - %v\n", namespace, err)
 	})
 
-	err = printLogs(logList, streams, o.Limit, o.Prefix)
 	if err != nil {
 		return err
 	}
 
-	return nil
-
-}
-
-func FetchLogs(baseUrl string, logParameters *LogParameters, podname string, podLogsCh chan<- []logs.LogOptions, token string) {
-
-	req, err := http.NewRequest("GET", baseUrl, nil)
-
+	httpClient, err := o.httpClient(kubernetesOptions)
 	if err != nil {
-		fmt.Printf("unable to fetch logs of pod %s - http request failed: %v\n", podname, err)
-		podLogsCh <- nil
-		return
+		return err
 	}
 
-	// reading token from config in KubernetesClient
-	var bearer = "`Bearer " + token + "`"
-	req.Header.Set("Authorization", bearer)
-
-	query := req.URL.Query()
-	query.Add("/pod/", podname)
-	query.Add("/namespace/", logParameters.Namespace)
-	query.Add("/starttime/", logParameters.StartTime)
-	query.Add("/finishtime/", logParameters.EndTime)
-	query.Add("/maxlogs/", strconv.Itoa(logParameters.Limit))
-	query.Add("/level/", logParameters.Level)
-	req.URL.RawQuery = query.Encode()
-
-	response, err := http.DefaultClient.Do(req)
+	logBackend, closeBackend, err := o.newBackend(kubernetesOptions, httpClient, streams.ErrOut)
 	if err != nil {
-		fmt.Printf("unable to fetch logs of pod %s - failed to get http response %v\n", podname, err)
-		podLogsCh <- nil
-		return
+		return err
 	}
+	defer closeBackend()
 
-	responseBody, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		fmt.Printf("unable to fetch logs of pod %s - failed to read response: %v\n", podname, err)
-		podLogsCh <- nil
-		return
+	if units, _ := podUnits(podList); len(units) > 0 && !searchesUnits(logBackend) {
+		return fmt.Errorf("journal logs of units cannot be read through the \"%s\" backend, use --backend=%s or --backend=%s", o.Backend, constants.BackendElasticsearch, constants.BackendLoki)
 	}
 
-	err = response.Body.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
 
-	if err != nil {
-		fmt.Printf("unable to fetch logs of pod %s - an error occurred while attempting to close response body %v\n", podname, err)
-		podLogsCh <- nil
-		return
+	if o.HistoricalPods {
+		matchers, err := k8sresources.GetNamespacesPodMatchers(kubernetesOptions, &o.Resources, o.namespaces)
+		if err != nil {
+			return err
+		}
+		podList = append(podList, discoverHistoricalPods(ctx, logBackend, o, matchers, podList)...)
 	}
 
-	jsonResponse := &ResponseLogs{}
-	err = json.Unmarshal(responseBody, &jsonResponse)
+	if o.Follow {
+		return o.followLogs(ctx, logBackend, podList, printer)
+	}
 
-	if err != nil {
-		fmt.Printf("unable to fetch logs of pod %s - an error occurred while unmarshalling JSON response: %v\n", podname, err)
-		podLogsCh <- nil
-		return
+	if before, after := o.contextLines(); before > 0 || after > 0 {
+		printer, err = o.newContextPrinter(ctx, logBackend, printer)
+		if err != nil {
+			return err
+		}
 	}
 
-	if jsonResponse.Error != "" {
-		fmt.Printf("unable to fetch logs of pod %s - a server-side error occured: %v\n", podname, jsonResponse.Error)
-		podLogsCh <- nil
-		return
+	err = o.printPodsLogs(ctx, logBackend, podList, printer)
+	if ctx.Err() == context.DeadlineExceeded {
+		return &exitError{code: ExitTotalFailure, err: fmt.Errorf("unable to fetch every log within %v, use --timeout to allow more time", o.Timeout)}
 	}
+	return err
+}
 
-	var logList []logs.LogOptions
-	for _, log := range jsonResponse.Logs {
-		logOption := logs.LogOptions{}
-		err := json.Unmarshal([]byte(log), &logOption)
+func filterContainers(logList []logs.LogOptions, containers []string) []logs.LogOptions {
 
-		if err != nil {
-			fmt.Printf("unable to fetch logs of pod %s - no logs present, or input parameters were invalid %v\n", podname, err)
-			podLogsCh <- nil
-			return
-		}
-		logList = append(logList, logOption)
+	if len(containers) == 0 {
+		return logList
 	}
 
-	podLogsCh <- logList
+	var filtered []logs.LogOptions
+	for _, log := range logList {
+		for _, container := range containers {
+			if log.Source.Kubernetes.ContainerName == container {
+				filtered = append(filtered, log)
+				break
+			}
+		}
+	}
+	return filtered
 }
 
-func printLogs(logList []logs.LogOptions, streams genericclioptions.IOStreams, limit int, prefix bool) error {
+func printLogs(logList []logs.LogOptions, printer printers.LogPrinter, limit int) error {
 
 	if len(logList) == 0 {
 		return fmt.Errorf("no logs present, or input parameters were invalid")
@@ -223,28 +455,17 @@ func printLogs(logList []logs.LogOptions, streams genericclioptions.IOStreams, l
 
 	for logCount, log := range logList {
 		if limit < 0 {
-			return fmt.Errorf("incorrect \"limit\" value entered, an integer value between 0 and 1000 is required")
+			return fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required")
 		}
 		if logCount >= limit {
-			return nil
+			break
 		}
 
-		if len(log.Source.Message) > 0 {
-			if prefix && len(log.Source.Kubernetes.PodName) > 0 && len(log.Source.Kubernetes.ContainerName) > 0 {
-				_, err := fmt.Fprintf(streams.Out, "pod/"+log.Source.Kubernetes.PodName+"/"+log.Source.Kubernetes.ContainerName+"   "+log.Source.Message+"\n")
-				if err != nil {
-					return fmt.Errorf("an error occurred while printing logs: %v", err)
-				}
-			} else {
-				_, err := fmt.Fprintf(streams.Out, log.Source.Message+"\n")
-				if err != nil {
-					return fmt.Errorf("an error occurred while printing logs: %v", err)
-				}
-			}
-
+		err := printer.PrintLog(log)
+		if err != nil {
+			return err
 		}
-
 	}
 
-	return nil
+	return printer.Flush()
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
 	"github.com/jarcoal/httpmock"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -49,7 +50,7 @@ func TestExecute(t *testing.T) {
 		{
 			"Logs with tail parameter",
 			false,
-			map[string]string{"Tail": "30m"},
+			map[string]string{"Tail": "30m", "Limit": "10"},
 			map[string]string{"Deployment": "openshift-deployment"},
 			[]string{"deployment=openshift-deployment"},
 			nil,
@@ -81,7 +82,7 @@ func TestExecute(t *testing.T) {
 			map[string]string{"Limit": "-5"},
 			map[string]string{"Deployment": "openshift-deployment"},
 			[]string{"deployment=openshift-deployment"},
-			fmt.Errorf("incorrect \"limit\" value entered, an integer value between 0 and 1000 is required"),
+			fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required"),
 		},
 		{
 			"Server-Side Error",
@@ -89,13 +90,13 @@ func TestExecute(t *testing.T) {
 			map[string]string{"Limit": "5"},
 			map[string]string{"Deployment": "openshift-deployment"},
 			[]string{"deployment=openshift-deployment"},
-			fmt.Errorf("no logs present, or input parameters were invalid"),
+			fmt.Errorf("unable to fetch the logs of any pod"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Log("Running:", tt.TestName)
-		logParameters := LogParameters{}
+		logParameters := LogParameters{APIUrl: "http://log-exploration-api-route-openshift-logging.apps.com"}
 		for k, v := range tt.TestLogParams {
 			switch k {
 			case "Namespace":
@@ -114,17 +115,7 @@ func TestExecute(t *testing.T) {
 		}
 		logParameters.Resources = k8sresources.Resources{}
 		for k, v := range tt.TestResources {
-			switch k {
-			case "Deployment":
-				logParameters.Resources.IsDeployment = true
-			case "Daemonset":
-				logParameters.Resources.IsDaemonSet = true
-			case "Statefulset":
-				logParameters.Resources.IsStatefulSet = true
-			case "Pod":
-				logParameters.Resources.IsPod = true
-			}
-			logParameters.Resources.Name = v
+			logParameters.Resources.Targets = append(logParameters.Resources.Targets, k8sresources.Resource{Type: strings.ToLower(k), Name: v})
 		}
 
 		clientset := fake.NewSimpleClientset(
@@ -230,7 +221,7 @@ func TestPrintLogs(t *testing.T) {
 			"Negative limit",
 			false,
 			-2,
-			fmt.Errorf("incorrect \"limit\" value entered, an integer value between 0 and 1000 is required"),
+			fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required"),
 		},
 	}
 
@@ -246,7 +237,8 @@ func TestPrintLogs(t *testing.T) {
 			}
 			logList = append(logList, logOption)
 		}
-		err := printLogs(logList, genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}, tt.TestLimit, false)
+		printer, _ := printers.NewLogPrinter(os.Stdout, printers.PrintOptions{})
+		err := printLogs(logList, printer, tt.TestLimit)
 		if err == nil && tt.Error != nil {
 			t.Errorf("Expected error is %v, found %v", tt.Error, err)
 		}
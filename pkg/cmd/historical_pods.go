@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// discoverHistoricalPods asks the log store for the pods which logged in the
+// selected namespaces during the requested time range and returns those belonging to the
+// requested resources which are not part of podList, i.e. pods which have been
+// deleted, replaced by a newer revision or have crashed since. The whole time
+// range is searched, not only its newest logs.
+func discoverHistoricalPods(ctx context.Context, logBackend backend.Backend, logParameters *LogParameters, matchers []k8sresources.PodMatcher, podList []k8sresources.Pod) []k8sresources.Pod {
+
+	known := map[string]bool{}
+	for _, pod := range podList {
+		known[pod.Namespace+"/"+pod.Name] = true
+	}
+
+	discoveryParameters := *logParameters
+	discoveryParameters.Limit = constants.LimitUpperBound
+	// pods are discovered from all their logs, not only from matching ones
+	discoveryParameters.Level = ""
+	discoveryParameters.Grep = ""
+	discoveryParameters.Regexp = ""
+
+	var logList []logs.LogOptions
+	for _, namespace := range logParameters.selectedNamespaces() {
+		query, _, err := discoveryParameters.query(logBackend, namespace, nil)
+		if err != nil {
+			fmt.Fprintln(logParameters.warnings(), err)
+			return nil
+		}
+		namespaceLogs, err := podLogs(ctx, logBackend, query)
+		if err != nil {
+			fmt.Fprintf(logParameters.warnings(), "unable to discover historical pods of namespace %s - %v\n", namespace, err)
+			continue
+		}
+		logList = append(logList, namespaceLogs...)
+	}
+
+	var discovered []k8sresources.Pod
+	for _, log := range logList {
+		kubernetes := log.Source.Kubernetes
+		key := kubernetes.NamespaceName + "/" + kubernetes.PodName
+		if known[key] {
+			continue
+		}
+		podLabels := log.Labels()
+		for _, matcher := range matchers {
+			if matcher.MatchesHost(logHost(log)) && matcher.Matches(kubernetes.NamespaceName, kubernetes.PodName, podLabels) {
+				known[key] = true
+				discovered = append(discovered, k8sresources.Pod{Name: kubernetes.PodName, Namespace: kubernetes.NamespaceName})
+				break
+			}
+		}
+	}
+
+	sort.Slice(discovered, func(index1, index2 int) bool {
+		if discovered[index1].Namespace != discovered[index2].Namespace {
+			return discovered[index1].Namespace < discovered[index2].Namespace
+		}
+		return discovered[index1].Name < discovered[index2].Name
+	})
+	return discovered
+}
+
+// podLogs returns a log of every pod with logs matching the query. Backends
+// which cannot aggregate the logs by pod are paged through in full, one page
+// of query.Limit logs at a time.
+func podLogs(ctx context.Context, logBackend backend.Backend, query backend.Query) ([]logs.LogOptions, error) {
+
+	if podSearcher, ok := logBackend.(backend.PodSearcher); ok {
+		return podSearcher.PodLogs(ctx, query)
+	}
+
+	seen := map[string]bool{}
+	var logList []logs.LogOptions
+	err := logBackend.Stream(ctx, query, func(page []logs.LogOptions) error {
+		for _, log := range page {
+			key := log.Source.Kubernetes.NamespaceName + "/" + log.Source.Kubernetes.PodName
+			if !seen[key] {
+				seen[key] = true
+				logList = append(logList, log)
+			}
+		}
+		return nil
+	})
+	return logList, err
+}
+
+// selectedNamespaces returns the namespaces logs are requested from.
+func (o *LogParameters) selectedNamespaces() []string {
+	if len(o.namespaces) == 0 {
+		return []string{o.Namespace}
+	}
+	return o.namespaces
+}
+
+// multiNamespace reports whether logs are requested from several namespaces.
+func (o *LogParameters) multiNamespace() bool {
+	return len(o.namespaces) > 1 || len(o.namespaces) == 1 && o.namespaces[0] == metav1.NamespaceAll
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/jarcoal/httpmock"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestDiscoverHistoricalPods(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("/pod/") != "" {
+				t.Errorf("Expected discovery for all pods, found pod %q", req.URL.Query().Get("/pod/"))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": {
+				`{"_id":"1","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-7c9d5f8b4-q8w2e","flat_labels":["app=kibana"]}}}`,
+				`{"_id":"2","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-5d8f7c6b9-x2k4p","flat_labels":["app=kibana","pod-template-hash=5d8f7c6b9"]}}}`,
+				`{"_id":"3","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-5d8f7c6b9-x2k4p","flat_labels":["app=kibana","pod-template-hash=5d8f7c6b9"]}}}`,
+				`{"_id":"4","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-48d7c5b6f-m3n7r","labels":{"app":"kibana"}}}}`,
+				`{"_id":"5","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kubernetes":{"namespace_name":"openshift-logging","pod_name":"kibana-proxy-48d7c5b6f-m3n7r","flat_labels":["app=kibana-proxy"]}}}`,
+			}})
+		})
+
+	matchers := []k8sresources.PodMatcher{{
+		Namespace: "openshift-logging",
+		Selector:  labels.SelectorFromSet(labels.Set{"app": "kibana"}),
+	}}
+	podList := []k8sresources.Pod{{Name: "kibana-7c9d5f8b4-q8w2e", Namespace: "openshift-logging"}}
+
+	logParameters := LogParameters{Namespace: "openshift-logging", Limit: 10}
+	discovered := discoverHistoricalPods(context.Background(), backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), &logParameters, matchers, podList)
+
+	expected := []string{"kibana-48d7c5b6f-m3n7r", "kibana-5d8f7c6b9-x2k4p"}
+	if len(discovered) != len(expected) {
+		t.Fatalf("Expected pods %v found %v", expected, discovered)
+	}
+	for index, pod := range discovered {
+		if pod.Name != expected[index] || pod.Namespace != "openshift-logging" {
+			t.Errorf("Expected pods %v found %v", expected, discovered)
+		}
+	}
+	if logParameters.Limit != 10 {
+		t.Errorf("Expected limit %d to be left unchanged, found %d", 10, logParameters.Limit)
+	}
+}
+
+func TestDiscoverHistoricalPodsBeyondFirstPage(t *testing.T) {
+	base := time.Date(2021, 3, 18, 6, 41, 0, 0, time.UTC)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			start, _ := time.Parse(time.RFC3339Nano, req.URL.Query().Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, req.URL.Query().Get("/finishtime/"))
+			limit, _ := strconv.Atoi(req.URL.Query().Get("/maxlogs/"))
+
+			// the live pod logged more than a page after the deleted pod logged
+			var documents []string
+			for index := 1500; index >= 0 && len(documents) < limit; index-- {
+				pod := "kibana-7c9d5f8b4-q8w2e"
+				if index == 0 {
+					pod = "kibana-5d8f7c6b9-x2k4p"
+				}
+				timestamp := base.Add(time.Duration(index) * time.Second)
+				if timestamp.Before(start) || timestamp.After(end) {
+					continue
+				}
+				documents = append(documents, fmt.Sprintf(`{"_id":"%d","_source":{"@timestamp":"%s","kubernetes":{"namespace_name":"openshift-logging","pod_name":"%s","labels":{"app":"kibana"}}}}`, index, timestamp.Format(time.RFC3339Nano), pod))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": documents})
+		})
+
+	matchers := []k8sresources.PodMatcher{{
+		Namespace: "openshift-logging",
+		Selector:  labels.SelectorFromSet(labels.Set{"app": "kibana"}),
+	}}
+	podList := []k8sresources.Pod{{Name: "kibana-7c9d5f8b4-q8w2e", Namespace: "openshift-logging"}}
+
+	logParameters := LogParameters{Namespace: "openshift-logging", StartTime: "2021-03-18T06:00:00Z", EndTime: "2021-03-18T08:00:00Z"}
+	discovered := discoverHistoricalPods(context.Background(), backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), &logParameters, matchers, podList)
+
+	if len(discovered) != 1 || discovered[0].Name != "kibana-5d8f7c6b9-x2k4p" {
+		t.Errorf("Expected pods %v found %v", []string{"kibana-5d8f7c6b9-x2k4p"}, discovered)
+	}
+}
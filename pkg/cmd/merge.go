@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+)
+
+// podPage is a page of the logs of one pod, or the error which ended them.
+type podPage struct {
+	logs []logs.LogOptions
+	err  error
+}
+
+// logBefore orders logs chronologically. Logs with the same timestamp are
+// ordered by the time the collector received them, then by their ID.
+func logBefore(log1 logs.LogOptions, log2 logs.LogOptions) bool {
+
+	if !log1.Source.Timestamp.Equal(log2.Source.Timestamp) {
+		return log1.Source.Timestamp.Before(log2.Source.Timestamp)
+	}
+	receivedAt1 := log1.Source.PipelineMetadata.Collector.ReceivedAt
+	receivedAt2 := log2.Source.PipelineMetadata.Collector.ReceivedAt
+	if !receivedAt1.Equal(receivedAt2) {
+		return receivedAt1.Before(receivedAt2)
+	}
+	return log1.ID < log2.ID
+}
+
+// ascending reports whether logs are printed oldest first, as selected by
+// --order and flipped by --reverse.
+func (o *LogParameters) ascending() bool {
+	return (o.Order == constants.OrderAscending) != o.Reverse
+}
+
+// logOrder returns the order logs are printed in.
+func logOrder(ascending bool) func(log1 logs.LogOptions, log2 logs.LogOptions) bool {
+	if ascending {
+		return logBefore
+	}
+	return func(log1 logs.LogOptions, log2 logs.LogOptions) bool {
+		return logBefore(log2, log1)
+	}
+}
+
+// printPodsLogs prints the logs of the pods merged into one chronological
+// stream. Printing starts once every pod delivered its first page and only the
+// current page of every pod is held in memory. The newest logs up to the limit
+// are printed in either order, a limited ascending listing is therefore merged
+// newest first and printed once complete. The pods whose logs could not be
+// fetched are summarized after the logs and decide the exit code.
+func (o *LogParameters) printPodsLogs(ctx context.Context, logBackend backend.Backend, podList []k8sresources.Pod, printer printers.LogPrinter) error {
+
+	if len(podList) == 0 {
+		return &exitError{code: ExitNoLogs, err: fmt.Errorf("no logs present, or input parameters were invalid")}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var fetches sync.WaitGroup
+	defer func() {
+		cancel()
+		fetches.Wait()
+	}()
+
+	collect := o.ascending() && o.Limit > 0
+	ascending := o.ascending() && !collect
+	paged := o.paged() || o.filteredLocally(logBackend) || containersFilteredLocally(logBackend, podList)
+
+	streams, err := o.streamPodsLogs(ctx, logBackend, podList, paged, ascending, &fetches)
+	if err != nil {
+		return err
+	}
+
+	var collected []logs.LogOptions
+	var failures fetchFailures
+	printed := 0
+	err = mergeLogStreams(streams, logOrder(ascending), func(log logs.LogOptions) error {
+		if collect {
+			collected = append(collected, log)
+		} else {
+			err := printer.PrintLog(log)
+			if err != nil {
+				return err
+			}
+		}
+		printed++
+		if o.Limit > 0 && printed >= o.Limit {
+			return backend.ErrStop
+		}
+		return nil
+	}, failures.add)
+	if err != nil {
+		return err
+	}
+
+	if collect && len(collected) > 0 {
+		for index1, index2 := 0, len(collected)-1; index1 < index2; index1, index2 = index1+1, index2-1 {
+			collected[index1], collected[index2] = collected[index2], collected[index1]
+		}
+		err = printLogs(collected, printer, o.Limit)
+	} else {
+		err = printer.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	// failures are summarized after the logs, which may be piped elsewhere
+	failures.summarize(o.warnings(), len(podList))
+	return failures.exitError(len(podList), printed, o.FailOnPartial)
+}
+
+// streamPodsLogs fetches the logs of every pod in the background and delivers
+// them on one channel per pod, page by page, in the order of less. Paged
+// streams fetch pages until the context is cancelled, the others deliver a
+// single page of the newest logs. At most one page per pod waits in a channel.
+// Every fetch is added to fetches.
+func (o *LogParameters) streamPodsLogs(ctx context.Context, logBackend backend.Backend, podList []k8sresources.Pod, paged bool, ascending bool, fetches *sync.WaitGroup) ([]<-chan podPage, error) {
+
+	less := logOrder(ascending)
+
+	var streams []<-chan podPage
+	for _, pod := range podList {
+		query, local, source, err := o.podQuery(logBackend, pod)
+		if err != nil {
+			return nil, err
+		}
+		if paged {
+			query.Limit = constants.LimitUpperBound
+			query.Ascending = ascending
+		}
+
+		pagesCh := make(chan podPage, 1)
+		streams = append(streams, pagesCh)
+
+		fetches.Add(1)
+		go func(pod k8sresources.Pod, source string, query backend.Query, local localFilter) {
+			defer fetches.Done()
+			defer close(pagesCh)
+
+			send := func(page []logs.LogOptions) error {
+				page, err := local.apply(filterContainers(page, pod.Containers))
+				if err != nil {
+					return err
+				}
+				if len(page) == 0 {
+					return nil
+				}
+				sort.SliceStable(page, func(index1, index2 int) bool {
+					return less(page[index1], page[index2])
+				})
+				select {
+				case pagesCh <- podPage{logs: page}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			var err error
+			if paged {
+				err = logBackend.Stream(ctx, query, send)
+			} else {
+				var logList []logs.LogOptions
+				logList, err = logBackend.Query(ctx, query)
+				if err == nil {
+					err = send(logList)
+				}
+			}
+			if err != nil && ctx.Err() == nil {
+				select {
+				case pagesCh <- podPage{err: &podError{pod: source, err: err}}:
+				case <-ctx.Done():
+				}
+			}
+		}(pod, source, query, local)
+	}
+	return streams, nil
+}
+
+// podQuery builds the backend query for the logs of a pod, or of a unit, and
+// names the source of the logs in failure reports.
+func (o *LogParameters) podQuery(logBackend backend.Backend, pod k8sresources.Pod) (backend.Query, localFilter, string, error) {
+
+	if len(pod.Unit) > 0 {
+		query, local, err := o.unitsQuery(logBackend, []string{pod.Unit})
+		return query, local, constants.Unit + "/" + pod.Unit, err
+	}
+
+	namespace := pod.Namespace
+	if len(namespace) == 0 {
+		namespace = o.Namespace
+	}
+	query, local, err := o.query(logBackend, namespace, []string{pod.Name})
+	// pods of several namespaces may share their name
+	source := pod.Name
+	if o.multiNamespace() {
+		source = namespace + "/" + pod.Name
+	}
+	if len(pod.Containers) > 0 && searchesContainers(logBackend, pod.Containers, nil) {
+		// the containers resolved from the pod spec already match the patterns
+		query.Containers = pod.Containers
+		query.ExcludeContainers = nil
+	}
+	return query, local, source, err
+}
+
+// streamCursor is the position in the current page of a stream.
+type streamCursor struct {
+	pages <-chan podPage
+	page  []logs.LogOptions
+	index int
+}
+
+func (c *streamCursor) log() logs.LogOptions {
+	return c.page[c.index]
+}
+
+// next moves to the next log of the stream and reports whether there is one.
+// Errors ending the stream are passed to report.
+func (c *streamCursor) next(report func(err error)) bool {
+
+	c.index++
+	for c.index >= len(c.page) {
+		page, ok := <-c.pages
+		if !ok {
+			return false
+		}
+		if page.err != nil {
+			report(page.err)
+			continue
+		}
+		c.page, c.index = page.logs, 0
+	}
+	return true
+}
+
+type cursorHeap struct {
+	cursors []*streamCursor
+	less    func(log1 logs.LogOptions, log2 logs.LogOptions) bool
+}
+
+func (h *cursorHeap) Len() int { return len(h.cursors) }
+
+func (h *cursorHeap) Less(index1, index2 int) bool {
+	return h.less(h.cursors[index1].log(), h.cursors[index2].log())
+}
+
+func (h *cursorHeap) Swap(index1, index2 int) {
+	h.cursors[index1], h.cursors[index2] = h.cursors[index2], h.cursors[index1]
+}
+
+func (h *cursorHeap) Push(cursor interface{}) {
+	h.cursors = append(h.cursors, cursor.(*streamCursor))
+}
+
+func (h *cursorHeap) Pop() interface{} {
+	cursor := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return cursor
+}
+
+// mergeLogStreams passes the logs of every stream to emit in the order of less,
+// which every stream delivers its logs in. Merging starts once every stream
+// delivered its first page and holds only the current page of every stream.
+// Returning backend.ErrStop from emit ends the merge without an error. Errors
+// of a stream are passed to report and end that stream only.
+func mergeLogStreams(streams []<-chan podPage, less func(log1 logs.LogOptions, log2 logs.LogOptions) bool, emit func(log logs.LogOptions) error, report func(err error)) error {
+
+	cursors := &cursorHeap{less: less}
+	for _, pages := range streams {
+		cursor := &streamCursor{pages: pages, index: -1}
+		if cursor.next(report) {
+			cursors.cursors = append(cursors.cursors, cursor)
+		}
+	}
+	heap.Init(cursors)
+
+	for cursors.Len() > 0 {
+		cursor := cursors.cursors[0]
+		err := emit(cursor.log())
+		if err == backend.ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if cursor.next(report) {
+			heap.Fix(cursors, 0)
+		} else {
+			heap.Pop(cursors)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func mergeTestLog(t *testing.T, id string, timestamp string, receivedAt string) logs.LogOptions {
+	log := logs.LogOptions{}
+	document := fmt.Sprintf(`{"_id":"%s","_source":{"message":"%s","@timestamp":"%s","pipeline_metadata":{"collector":{"received_at":"%s"}}}}`, id, id, timestamp, receivedAt)
+	err := json.Unmarshal([]byte(document), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	return log
+}
+
+func TestLogBefore(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Log1     []string
+		Log2     []string
+		Before   bool
+	}{
+		{
+			"Earlier timestamp in another time zone",
+			[]string{"1", "2021-03-18T08:00:00+02:00", "2021-03-18T06:00:01Z"},
+			[]string{"2", "2021-03-18T07:00:00Z", "2021-03-18T07:00:01Z"},
+			true,
+		},
+		{
+			"Fractional seconds",
+			[]string{"1", "2021-03-18T06:00:00.9Z", "2021-03-18T06:00:01Z"},
+			[]string{"2", "2021-03-18T06:00:00.10Z", "2021-03-18T06:00:01Z"},
+			false,
+		},
+		{
+			"Same timestamp, received earlier",
+			[]string{"2", "2021-03-18T06:00:00Z", "2021-03-18T06:00:01Z"},
+			[]string{"1", "2021-03-18T06:00:00Z", "2021-03-18T06:00:02Z"},
+			true,
+		},
+		{
+			"Same timestamp and received time, lower ID",
+			[]string{"1", "2021-03-18T06:00:00Z", "2021-03-18T06:00:01Z"},
+			[]string{"2", "2021-03-18T06:00:00Z", "2021-03-18T06:00:01Z"},
+			true,
+		},
+		{
+			"Same log",
+			[]string{"1", "2021-03-18T06:00:00Z", "2021-03-18T06:00:01Z"},
+			[]string{"1", "2021-03-18T06:00:00Z", "2021-03-18T06:00:01Z"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		log1 := mergeTestLog(t, tt.Log1[0], tt.Log1[1], tt.Log1[2])
+		log2 := mergeTestLog(t, tt.Log2[0], tt.Log2[1], tt.Log2[2])
+		if logBefore(log1, log2) != tt.Before {
+			t.Errorf("Expected %v found %v", tt.Before, logBefore(log1, log2))
+		}
+	}
+}
+
+func TestMergeLogStreams(t *testing.T) {
+	tests := []struct {
+		TestName  string
+		Streams   [][][]int
+		Errors    []string
+		Limit     int
+		Ascending bool
+		Merged    []int
+		Reported  []string
+	}{
+		{
+			"Pages of three streams",
+			[][][]int{{{9, 5}, {4, 1}}, {{8, 7}, {6}}, {{3, 2}}},
+			nil,
+			0,
+			false,
+			[]int{9, 8, 7, 6, 5, 4, 3, 2, 1},
+			nil,
+		},
+		{
+			"Oldest first",
+			[][][]int{{{1, 4}, {5, 9}}, {{2, 3}}, {{6, 7, 8}}},
+			nil,
+			0,
+			true,
+			[]int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			nil,
+		},
+		{
+			"Stopped at the limit",
+			[][][]int{{{9, 5}, {4, 1}}, {{8, 7}, {6}}, {{3, 2}}},
+			nil,
+			4,
+			false,
+			[]int{9, 8, 7, 6},
+			nil,
+		},
+		{
+			"Failed and empty streams",
+			[][][]int{{{6, 4}, {2}}, {{5, 3}}, {}},
+			[]string{"", "unable to fetch logs of pod-2", ""},
+			0,
+			false,
+			[]int{6, 5, 4, 3, 2},
+			[]string{"unable to fetch logs of pod-2"},
+		},
+	}
+
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		var streams []<-chan podPage
+		for index, stream := range tt.Streams {
+			pagesCh := make(chan podPage, len(stream)+1)
+			for _, page := range stream {
+				var logList []logs.LogOptions
+				for _, second := range page {
+					timestamp := base.Add(time.Duration(second) * time.Second).Format(time.RFC3339Nano)
+					logList = append(logList, mergeTestLog(t, fmt.Sprint(second), timestamp, timestamp))
+				}
+				pagesCh <- podPage{logs: logList}
+			}
+			if index < len(tt.Errors) && len(tt.Errors[index]) > 0 {
+				pagesCh <- podPage{err: fmt.Errorf(tt.Errors[index])}
+			}
+			close(pagesCh)
+			streams = append(streams, pagesCh)
+		}
+
+		var merged []int
+		var reported []string
+		err := mergeLogStreams(streams, logOrder(tt.Ascending), func(log logs.LogOptions) error {
+			var second int
+			fmt.Sscan(log.Source.Message, &second)
+			merged = append(merged, second)
+			if tt.Limit > 0 && len(merged) >= tt.Limit {
+				return backend.ErrStop
+			}
+			return nil
+		}, func(err error) {
+			reported = append(reported, err.Error())
+		})
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if fmt.Sprint(merged) != fmt.Sprint(tt.Merged) {
+			t.Errorf("Expected logs %v found %v", tt.Merged, merged)
+		}
+		if fmt.Sprint(reported) != fmt.Sprint(tt.Reported) {
+			t.Errorf("Expected errors %v found %v", tt.Reported, reported)
+		}
+	}
+}
+
+func TestPrintPodsLogs(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Limit    int
+		Order    string
+		Reverse  bool
+		Output   []string
+	}{
+		{
+			"Newest first",
+			100,
+			"",
+			false,
+			[]string{"pod-2 c", "pod-1 c", "pod-1 b", "pod-2 b", "pod-1 a", "pod-2 a"},
+		},
+		{
+			"Newest logs oldest first",
+			4,
+			"asc",
+			false,
+			[]string{"pod-2 b", "pod-1 b", "pod-1 c", "pod-2 c"},
+		},
+		{
+			"Reversed order",
+			4,
+			"desc",
+			true,
+			[]string{"pod-2 b", "pod-1 b", "pod-1 c", "pod-2 c"},
+		},
+		{
+			"Reversed ascending order",
+			3,
+			"asc",
+			true,
+			[]string{"pod-2 c", "pod-1 c", "pod-1 b"},
+		},
+		{
+			"All logs oldest first",
+			0,
+			"asc",
+			false,
+			[]string{"pod-2 a", "pod-1 a", "pod-2 b", "pod-1 b", "pod-1 c", "pod-2 c"},
+		},
+	}
+
+	// timestamps in different time zones and precisions, which sort
+	// differently as strings
+	documents := map[string][]string{
+		"pod-1": {
+			`{"_id":"1c","_source":{"message":"pod-1 c","@timestamp":"2021-03-18T08:00:03+02:00"}}`,
+			`{"_id":"1b","_source":{"message":"pod-1 b","@timestamp":"2021-03-18T06:00:01.5Z"}}`,
+			`{"_id":"1a","_source":{"message":"pod-1 a","@timestamp":"2021-03-18T06:00:00.25Z"}}`,
+		},
+		"pod-2": {
+			`{"_id":"2c","_source":{"message":"pod-2 c","@timestamp":"2021-03-18T06:00:03.5Z"}}`,
+			`{"_id":"2b","_source":{"message":"pod-2 b","@timestamp":"2021-03-18T07:00:01+01:00"}}`,
+			`{"_id":"2a","_source":{"message":"pod-2 a","@timestamp":"2021-03-18T06:00:00Z"}}`,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			start, _ := time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+
+			var page []string
+			for _, document := range documents[query.Get("/pod/")] {
+				log := logs.LogOptions{}
+				json.Unmarshal([]byte(document), &log)
+				if log.Source.Timestamp.Before(start) || log.Source.Timestamp.After(end) {
+					continue
+				}
+				page = append(page, document)
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		out := &bytes.Buffer{}
+		printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+		logParameters := LogParameters{
+			Limit:     tt.Limit,
+			Order:     tt.Order,
+			Reverse:   tt.Reverse,
+			StartTime: "2021-03-18T06:00:00Z",
+			EndTime:   "2021-03-18T07:00:00Z",
+		}
+		err := logParameters.printPodsLogs(context.Background(), backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), []k8sresources.Pod{{Name: "pod-1"}, {Name: "pod-2"}}, printer)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if strings.Join(lines, ",") != strings.Join(tt.Output, ",") {
+			t.Errorf("Expected output %v found %v", tt.Output, lines)
+		}
+	}
+}
+
+func TestPrintUnitsLogs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:9200/app-*,infra-*,audit-*/_search",
+		func(req *http.Request) (*http.Response, error) {
+			requestBody, _ := ioutil.ReadAll(req.Body)
+			if strings.Contains(string(requestBody), `{"terms":{"systemd.t.SYSTEMD_UNIT":["kubelet.service"]}}`) {
+				if !strings.Contains(string(requestBody), `{"prefix":{"_index":"infra-"}}`) || strings.Contains(string(requestBody), "kubernetes.namespace_name") {
+					t.Errorf("Expected the infrastructure logs of every namespace, found %s", requestBody)
+				}
+				return httpmock.NewStringResponse(200, `{"hits":{"hits":[
+					{"_index":"infra-000001","_id":"2","_source":{"message":"kubelet b","hostname":"worker-0","systemd":{"t":{"SYSTEMD_UNIT":"kubelet.service"}},"@timestamp":"2021-03-18T06:00:02Z"}},
+					{"_index":"infra-000001","_id":"1","_source":{"message":"kubelet a","hostname":"worker-0","systemd":{"t":{"SYSTEMD_UNIT":"kubelet.service"}},"@timestamp":"2021-03-18T06:00:00Z"}}
+				]}}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"hits":{"hits":[
+				{"_index":"app-000001","_id":"3","_source":{"message":"pod-1 a","kubernetes":{"pod_name":"pod-1","container_name":"app"},"@timestamp":"2021-03-18T06:00:01Z"}}
+			]}}`), nil
+		})
+
+	out := &bytes.Buffer{}
+	printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{Prefix: true})
+	logParameters := LogParameters{Namespace: "project-a", Limit: 100}
+	esBackend := backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", "")
+	err := logParameters.printPodsLogs(context.Background(), esBackend, []k8sresources.Pod{{Unit: "kubelet.service"}, {Name: "pod-1", Namespace: "project-a"}}, printer)
+	if err != nil {
+		t.Errorf("Expected error is %v, found %v", nil, err)
+	}
+
+	expected := []string{"unit/kubelet.service/worker-0   kubelet b", "pod/pod-1/app   pod-1 a", "unit/kubelet.service/worker-0   kubelet a"}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if strings.Join(lines, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected output %v found %v", expected, lines)
+	}
+}
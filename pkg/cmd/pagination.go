@@ -0,0 +1,9 @@
+package cmd
+
+import "github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+
+// paged reports whether the requested number of logs exceeds what the API
+// returns for a single request.
+func (o *LogParameters) paged() bool {
+	return o.Limit == 0 || o.Limit > constants.LimitUpperBound
+}
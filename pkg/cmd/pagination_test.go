@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestPageLogs(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Limit      int
+		StartTime  string
+		TestCount  int
+		Error      error
+	}{
+		{
+			"All logs",
+			false,
+			0,
+			"2021-03-18T06:00:00Z",
+			2500,
+			nil,
+		},
+		{
+			"All logs without start time",
+			false,
+			0,
+			"",
+			2500,
+			nil,
+		},
+		{
+			"Limit above the page size",
+			false,
+			1500,
+			"2021-03-18T06:00:00Z",
+			1500,
+			nil,
+		},
+		{
+			"No logs in time range",
+			false,
+			0,
+			"2021-03-18T08:00:00Z",
+			0,
+			fmt.Errorf("no logs present, or input parameters were invalid"),
+		},
+	}
+
+	// 2500 logs one second apart, the API returns the newest page of a range
+	base := time.Date(2021, 3, 18, 6, 0, 0, 0, time.UTC)
+	var stored []time.Time
+	for index := 0; index < 2500; index++ {
+		stored = append(stored, base.Add(time.Duration(index)*time.Second))
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:8080/logs",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			start, _ := time.Parse(time.RFC3339Nano, query.Get("/starttime/"))
+			end, _ := time.Parse(time.RFC3339Nano, query.Get("/finishtime/"))
+			maxLogs, _ := strconv.Atoi(query.Get("/maxlogs/"))
+
+			var page []string
+			for index := len(stored) - 1; index >= 0 && len(page) < maxLogs; index-- {
+				if stored[index].Before(start) || stored[index].After(end) {
+					continue
+				}
+				page = append(page, fmt.Sprintf(`{"_id":"%d","_source":{"message":"%d","@timestamp":"%s"}}`, index, index, stored[index].Format(time.RFC3339Nano)))
+			}
+			return httpmock.NewJsonResponse(200, map[string][]string{"Logs": page})
+		})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		out := &bytes.Buffer{}
+		printer, _ := printers.NewLogPrinter(out, printers.PrintOptions{})
+		logParameters := LogParameters{
+			Limit:     tt.Limit,
+			StartTime: tt.StartTime,
+			EndTime:   base.Add(time.Hour).Format(time.RFC3339Nano),
+		}
+		err := logParameters.printPodsLogs(context.Background(), backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs"), []k8sresources.Pod{{Name: "pod-1"}}, printer)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		lines := strings.Fields(out.String())
+		if len(lines) != tt.TestCount {
+			t.Errorf("Expected %d logs found %d", tt.TestCount, len(lines))
+			continue
+		}
+		for index, line := range lines {
+			if line != strconv.Itoa(len(stored)-1-index) {
+				t.Errorf("Expected log %d at position %d found %s", len(stored)-1-index, index, line)
+				break
+			}
+		}
+	}
+}
@@ -2,79 +2,175 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/printers"
 )
 
 func (o *LogParameters) ProcessLogParameters(kubernetesOptions *client.KubernetesOptions, args []string) error {
 
-	if len(o.Tail) > 0 {
-		tail, err := strconv.Atoi(o.Tail[0 : len(o.Tail)-1]) //extract numeric value. For example, extract 50 from 50s or 10 from 10m
+	err := o.processTimeRange(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if o.All {
+		o.Limit = 0
+	}
+
+	if o.Limit < constants.LimitLowerBound {
+		return fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required")
+	}
+
+	_, err = logs.ParseLevels(o.Level)
+	if err != nil {
+		return fmt.Errorf("incorrect \"level\" value entered: %v", err)
+	}
+
+	o.LogType, err = logs.ParseLogType(o.LogType)
+	if err != nil {
+		return fmt.Errorf("incorrect \"log-type\" value entered: %v", err)
+	}
+
+	if len(o.LogType) > 0 && len(o.LokiTenant) > 0 && o.LogType != o.LokiTenant {
+		return fmt.Errorf("\"log-type\" %s and \"loki-tenant\" %s select different tenants", o.LogType, o.LokiTenant)
+	}
+
+	for _, messageFilter := range o.messageFilters() {
+		_, err := messageFilter.Compile()
 		if err != nil {
-			return fmt.Errorf("an invalid \"tail\" value was entered: %v", err)
+			return fmt.Errorf("incorrect \"regexp\" value entered: %v", err)
 		}
+	}
 
-		timeUnit := o.Tail[len(o.Tail)-1] //Last character (time unit) is 's'(seconds),'m'(minutes),'h'(hours),'d'(days)
-		endTime := time.Now().UTC()
-		var startTime time.Time
-
-		switch timeUnit {
-		case 's':
-			startTime = endTime.Add(time.Duration(-tail) * time.Second).UTC()
-		case 'm':
-			startTime = endTime.Add(time.Duration(-tail) * time.Minute).UTC()
-		case 'h':
-			startTime = endTime.Add(time.Duration(-tail) * time.Hour).UTC()
-		case 'd':
-			startTime = endTime.Add(time.Duration(-tail) * time.Hour * 24)
-		default:
-			return fmt.Errorf("invalid time unit entered in \"tail\". please enter s, m, h, or d as time unit")
+	for _, where := range o.Where {
+		_, err := logs.ParseFieldFilter(where)
+		if err != nil {
+			return fmt.Errorf("incorrect \"where\" value entered: %v", err)
 		}
+	}
 
-		o.StartTime = startTime.UTC().Format(time.RFC3339Nano)
-		o.EndTime = endTime.UTC().Format(time.RFC3339Nano)
+	if len(o.Parse) > 0 {
+		_, err := logs.NewMessageParser(o.Parse, o.ParseRegexp)
+		if err != nil {
+			return fmt.Errorf("incorrect \"parse\" value entered: %v", err)
+		}
+	} else if len(o.ParseRegexp) > 0 {
+		return fmt.Errorf("\"parse-regexp\" requires \"parse\" to be %s", logs.ParseRegex)
 	}
 
-	if o.Limit < constants.LimitLowerBound || o.Limit > constants.LimitUpperBound {
-		return fmt.Errorf("incorrect \"limit\" value entered, an integer value between %d and %d is required", constants.LimitLowerBound, constants.LimitUpperBound)
+	if (o.IgnoreCase || o.Invert) && len(o.messageFilters()) == 0 {
+		return fmt.Errorf("\"ignore-case\" and \"invert\" require a \"grep\" or \"regexp\" value")
 	}
 
-	if len(o.Namespace) == 0 {
-		o.Namespace = kubernetesOptions.CurrentNamespace
+	if o.BeforeContext < 0 || o.AfterContext < 0 || o.Context < 0 {
+		return fmt.Errorf("incorrect context value entered, a non-negative integer is required")
 	}
 
-	if len(args) != 1 {
-		return fmt.Errorf("one of deployment/daemonset/statefulset/podname required as argument in the format - [resource-type]=[resource-name]")
+	if before, after := o.contextLines(); o.Follow && (before > 0 || after > 0) {
+		return fmt.Errorf("context logs cannot be printed in follow mode")
 	}
 
-	resourceTypeNameSplit := strings.Split(args[0], "=") //example command- oc historical-logs deployment=deployment1 hence, splitting on "=" to extract resource and name
+	if o.Follow && o.Output == printers.JSON {
+		return fmt.Errorf("the \"%s\" output is a single array printed once complete, use -o %s in follow mode", printers.JSON, printers.JSONLines)
+	}
 
-	if len(resourceTypeNameSplit) != 2 {
-		return fmt.Errorf("invalid format. [resource-type]=[resource-name] required as argument")
+	switch o.Order {
+	case "", constants.OrderAscending, constants.OrderDescending:
+	default:
+		return fmt.Errorf("invalid \"order\" value \"%s\" entered, one of %v is required", o.Order, []string{constants.OrderAscending, constants.OrderDescending})
 	}
 
-	resourceType := resourceTypeNameSplit[0]
-	resourceName := resourceTypeNameSplit[1]
+	if o.MaxConcurrency < 0 {
+		return fmt.Errorf("incorrect \"max-concurrency\" value entered, a non-negative integer is required")
+	}
 
-	switch resourceType {
-	case constants.Deployment:
-		o.Resources.IsDeployment = true
-		o.Resources.Name = resourceName
-	case constants.DaemonSet:
-		o.Resources.IsDaemonSet = true
-		o.Resources.Name = resourceName
-	case constants.StatefulSet:
-		o.Resources.IsStatefulSet = true
-		o.Resources.Name = resourceName
-	case constants.Podname:
-		o.Resources.IsPod = true
-		o.Resources.Name = resourceName
-	default:
-		return fmt.Errorf("logs for invalid resource type \"%s\" requested", resourceType)
+	if o.Timeout < 0 {
+		return fmt.Errorf("incorrect \"timeout\" value entered, a non-negative duration is required")
+	}
+
+	if o.Follow && o.FollowInterval <= 0 {
+		return fmt.Errorf("incorrect \"follow-interval\" value entered, a positive duration is required")
+	}
+
+	if o.Follow && o.ReorderWindow < 0 {
+		return fmt.Errorf("incorrect \"reorder-window\" value entered, a non-negative duration is required")
+	}
+
+	for _, patterns := range [][]string{o.Containers, o.ExcludeContainers} {
+		if err := k8sresources.ValidateContainerPatterns(patterns); err != nil {
+			return err
+		}
+	}
+
+	namespaceOptions := 0
+	for _, selected := range []bool{o.AllNamespaces, len(o.Namespaces) > 0, len(o.NamespaceSelector) > 0} {
+		if selected {
+			namespaceOptions++
+		}
+	}
+	if namespaceOptions > 1 {
+		return fmt.Errorf("only one of \"all-namespaces\", \"namespaces\" and \"namespace-selector\" can be used")
+	}
+
+	if len(args) == 0 && len(o.Resources.Selector) == 0 && len(o.Resources.FieldSelector) == 0 {
+		return fmt.Errorf("one or more of pod/deployment/daemonset/statefulset/job/cronjob/replicaset/replicationcontroller/deploymentconfig/buildconfig/service/node/unit required as argument in the format - [resource-type]/[resource-name], or a selector")
+	}
+
+	o.Resources.Targets = nil
+	for _, arg := range args { //example command- oc historical-logs deployment/deployment1 daemonset/daemonset1
+		resource, err := k8sresources.ParseResource(arg)
+		if err != nil {
+			return err
+		}
+		o.Resources.Targets = append(o.Resources.Targets, resource)
+	}
+
+	nodes := o.Nodes
+	namespaceless := len(o.Resources.Targets) > 0
+	for _, target := range o.Resources.Targets {
+		switch target.Type {
+		case constants.Node:
+			nodes = append(nodes, target.Name)
+		case constants.Unit:
+			if len(o.LogType) > 0 && o.LogType != logs.LogTypeInfrastructure {
+				return fmt.Errorf("journal logs of unit \"%s\" are %s logs, not %s logs", target.Name, logs.LogTypeInfrastructure, o.LogType)
+			}
+		default:
+			namespaceless = false
+		}
+	}
+	err = k8sresources.ValidateNodes(kubernetesOptions, nodes)
+	if err != nil {
+		return err
+	}
+
+	// nodes run the pods of every namespace, units run outside of namespaces
+	if len(o.Namespace) == 0 && !namespaceless {
+		o.Namespace = kubernetesOptions.CurrentNamespace
 	}
 	return nil
 }
+
+// NormalizeLevelArgs rewrites level thresholds written as part of the flag
+// name, like --level>=warning, into flag values, like --level=>=warning.
+func NormalizeLevelArgs(args []string) []string {
+
+	normalized := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--" {
+			normalized = append(normalized, args[len(normalized):]...)
+			break
+		}
+		if strings.HasPrefix(arg, "--level>") || strings.HasPrefix(arg, "--level<") {
+			arg = "--level=" + strings.TrimPrefix(arg, "--level")
+		}
+		normalized = append(normalized, arg)
+	}
+	return normalized
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
@@ -47,6 +48,14 @@ func TestProcessLogParameters(t *testing.T) {
 			[]string{"deployment=openshift-deployment"},
 			nil,
 		},
+		{
+			"Logs with composite tail parameter",
+			false,
+			map[string]string{"Tail": "1h30m"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			nil,
+		},
 		{
 			"Logs with multiple parameters",
 			false,
@@ -68,13 +77,237 @@ func TestProcessLogParameters(t *testing.T) {
 			[]string{"deployment=openshift-deployment"},
 			nil,
 		},
+		{
+			"Logs for multiple resources",
+			false,
+			map[string]string{},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment/openshift-deployment", "pod/openshift-logging-1234"},
+			nil,
+		},
+		{
+			"Logs without resource",
+			false,
+			map[string]string{},
+			map[string]string{},
+			[]string{},
+			fmt.Errorf("one or more of pod/deployment/daemonset/statefulset/job/cronjob/replicaset/replicationcontroller/deploymentconfig/buildconfig/service/node/unit required as argument in the format - [resource-type]/[resource-name], or a selector"),
+		},
+		{
+			"Logs for invalid resource type",
+			false,
+			map[string]string{},
+			map[string]string{},
+			[]string{"configmap/openshift-deployment"},
+			fmt.Errorf("logs for invalid resource type \"configmap\" requested"),
+		},
+		{
+			"Logs with selector only",
+			false,
+			map[string]string{"Selector": "app=nginx"},
+			map[string]string{},
+			[]string{},
+			nil,
+		},
 		{
 			"Logs with negative limit",
 			false,
 			map[string]string{"Limit": "-5"},
 			map[string]string{"Deployment": "openshift-deployment"},
 			[]string{"deployment=openshift-deployment"},
-			fmt.Errorf("incorrect \"limit\" value entered, an integer value between 0 and 1000 is required"),
+			fmt.Errorf("incorrect \"limit\" value entered, a non-negative integer is required"),
+		},
+		{
+			"Logs with limit above a single page",
+			false,
+			map[string]string{"Limit": "5000"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			nil,
+		},
+		{
+			"Logs with message filters",
+			false,
+			map[string]string{"Limit": "5", "Grep": "timeout (", "Regexp": "^E[0-9]+", "Invert": "true"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			nil,
+		},
+		{
+			"Logs with invalid regexp",
+			false,
+			map[string]string{"Regexp": "timeout ("},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("incorrect \"regexp\" value entered: error parsing regexp: missing closing ): `timeout (`"),
+		},
+		{
+			"Logs with invert and no message filter",
+			false,
+			map[string]string{"Grep": "", "Regexp": ""},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("\"ignore-case\" and \"invert\" require a \"grep\" or \"regexp\" value"),
+		},
+		{
+			"Logs with negative context",
+			false,
+			map[string]string{"Invert": "false", "Context": "-1"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("incorrect context value entered, a non-negative integer is required"),
+		},
+		{
+			"Logs with invalid level",
+			false,
+			map[string]string{"Context": "0", "Level": ">=verbose"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("incorrect \"level\" value entered: unknown level \"verbose\", one of emerg,alert,crit,err,warning,notice,info,debug,trace,unknown is required"),
+		},
+		{
+			"Logs with invalid order",
+			false,
+			map[string]string{"Level": "", "Order": "newest"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("invalid \"order\" value \"newest\" entered, one of [asc desc] is required"),
+		},
+		{
+			"Logs with negative max concurrency",
+			false,
+			map[string]string{"Order": "", "MaxConcurrency": "-1"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("incorrect \"max-concurrency\" value entered, a non-negative integer is required"),
+		},
+		{
+			"Logs with namespace list",
+			false,
+			map[string]string{"MaxConcurrency": "0", "Namespaces": "openshift-logging,default"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			nil,
+		},
+		{
+			"Logs with namespace list and all namespaces",
+			false,
+			map[string]string{"Namespaces": "openshift-logging,default", "AllNamespaces": "true"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("only one of \"all-namespaces\", \"namespaces\" and \"namespace-selector\" can be used"),
+		},
+		{
+			"Logs with invalid container pattern",
+			false,
+			map[string]string{"Namespaces": "", "AllNamespaces": "false", "Containers": "app,[proxy"},
+			map[string]string{"Deployment": "openshift-deployment"},
+			[]string{"deployment=openshift-deployment"},
+			fmt.Errorf("invalid container pattern \"[proxy\": syntax error in pattern"),
+		},
+		{
+			"Logs for node",
+			false,
+			map[string]string{},
+			map[string]string{},
+			[]string{"node/worker-0"},
+			nil,
+		},
+		{
+			"Logs for unknown node",
+			false,
+			map[string]string{},
+			map[string]string{},
+			[]string{"node/worker-9"},
+			fmt.Errorf("node \"worker-9\" not found"),
+		},
+		{
+			"Logs with log type",
+			false,
+			map[string]string{"LogType": "Audit"},
+			map[string]string{},
+			[]string{"node/worker-0"},
+			nil,
+		},
+		{
+			"Logs of unit with another log type",
+			false,
+			map[string]string{"LogType": "application"},
+			map[string]string{},
+			[]string{"unit/kubelet.service"},
+			fmt.Errorf("journal logs of unit \"kubelet.service\" are infrastructure logs, not application logs"),
+		},
+		{
+			"Logs of unit",
+			false,
+			map[string]string{"LogType": ""},
+			map[string]string{},
+			[]string{"unit/kubelet", "node/worker-0"},
+			nil,
+		},
+		{
+			"Logs with invalid log type",
+			false,
+			map[string]string{"LogType": "journal"},
+			map[string]string{},
+			[]string{"unit/kubelet"},
+			fmt.Errorf("incorrect \"log-type\" value entered: unknown log type \"journal\", one of application,infrastructure,audit is required"),
+		},
+		{
+			"Log type of another Loki tenant",
+			false,
+			map[string]string{"LogType": "audit", "LokiTenant": "application"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			fmt.Errorf("\"log-type\" audit and \"loki-tenant\" application select different tenants"),
+		},
+		{
+			"Logs with field filter",
+			false,
+			map[string]string{"LogType": "", "LokiTenant": "", "Where": "kubernetes.labels.app=~^web"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			nil,
+		},
+		{
+			"Logs with invalid field filter",
+			false,
+			map[string]string{"Where": "kubernetes.labels.app"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			fmt.Errorf("incorrect \"where\" value entered: invalid field filter \"kubernetes.labels.app\", a filter like kubernetes.labels.app=web or structured.status=~^5 is required"),
+		},
+		{
+			"Logs with parsed messages",
+			false,
+			map[string]string{"Where": "structured.status=~^5", "Parse": "logfmt"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			nil,
+		},
+		{
+			"Logs with invalid parse format",
+			false,
+			map[string]string{"Parse": "xml"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			fmt.Errorf("incorrect \"parse\" value entered: unknown format \"xml\", one of json|logfmt|regex is required"),
+		},
+		{
+			"Logs with regular expression and no parse format",
+			false,
+			map[string]string{"Parse": "", "ParseRegexp": "(?P<status>[0-9]{3})"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			fmt.Errorf("\"parse-regexp\" requires \"parse\" to be regex"),
+		},
+		{
+			"Logs with JSON output in follow mode",
+			false,
+			map[string]string{"ParseRegexp": "", "Follow": "true", "Output": "json"},
+			map[string]string{},
+			[]string{"deployment/openshift-deployment"},
+			fmt.Errorf("the \"json\" output is a single array printed once complete, use -o jsonl in follow mode"),
 		},
 	}
 
@@ -93,26 +326,54 @@ func TestProcessLogParameters(t *testing.T) {
 				logParameters.EndTime = v
 			case "Level":
 				logParameters.Level = v
+			case "Grep":
+				logParameters.Grep = v
+			case "Regexp":
+				logParameters.Regexp = v
+			case "Invert":
+				logParameters.Invert, _ = strconv.ParseBool(v)
+			case "Context":
+				logParameters.Context, _ = strconv.Atoi(v)
+			case "Order":
+				logParameters.Order = v
+			case "MaxConcurrency":
+				logParameters.MaxConcurrency, _ = strconv.Atoi(v)
+			case "Namespaces":
+				logParameters.Namespaces = nil
+				if len(v) > 0 {
+					logParameters.Namespaces = strings.Split(v, ",")
+				}
+			case "AllNamespaces":
+				logParameters.AllNamespaces, _ = strconv.ParseBool(v)
+			case "LogType":
+				logParameters.LogType = v
+			case "LokiTenant":
+				logParameters.LokiTenant = v
+			case "Where":
+				logParameters.Where = []string{v}
+			case "Parse":
+				logParameters.Parse = v
+			case "ParseRegexp":
+				logParameters.ParseRegexp = v
+			case "Follow":
+				logParameters.Follow, _ = strconv.ParseBool(v)
+			case "Output":
+				logParameters.Output = v
+
 			case "Limit":
 				logParameters.Limit, _ = strconv.Atoi(v)
 			}
 		}
-		logParameters.Resources = k8sresources.Resources{}
+		logParameters.Resources = k8sresources.Resources{Selector: tt.TestLogParams["Selector"]}
+		if containers, ok := tt.TestLogParams["Containers"]; ok {
+			logParameters.Resources.Containers = strings.Split(containers, ",")
+		}
 		for k, v := range tt.TestResources {
-			switch k {
-			case "Deployment":
-				logParameters.Resources.IsDeployment = true
-			case "Daemonset":
-				logParameters.Resources.IsDaemonSet = true
-			case "Statefulset":
-				logParameters.Resources.IsStatefulSet = true
-			case "Pod":
-				logParameters.Resources.IsPod = true
-			}
-			logParameters.Resources.Name = v
+			logParameters.Resources.Targets = append(logParameters.Resources.Targets, k8sresources.Resource{Type: strings.ToLower(k), Name: v})
 		}
 
 		clientset := fake.NewSimpleClientset(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}},
 			&appsv1.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:        "openshift-deployment",
@@ -159,3 +420,11 @@ func TestProcessLogParameters(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeLevelArgs(t *testing.T) {
+	args := NormalizeLevelArgs([]string{"deployment/kibana", "--level>=warning", "--level<info", "--level=err", "--", "--level>=debug"})
+	expected := []string{"deployment/kibana", "--level=>=warning", "--level=<info", "--level=err", "--", "--level>=debug"}
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected arguments %v found %v", expected, args)
+	}
+}
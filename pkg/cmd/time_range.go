@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localTimeLayouts are the accepted formats for times given without a zone,
+// they are read in the zone chosen with --timezone.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// relativeTimePattern matches expressions such as now-2h, today or yesterday+6h.
+var relativeTimePattern = regexp.MustCompile(`^(now|today|yesterday|tomorrow)(?:\s*([+-])\s*(.+))?$`)
+
+// dayWeekPattern matches the day and week units time.ParseDuration lacks.
+var dayWeekPattern = regexp.MustCompile(`([0-9]*\.?[0-9]+)([dw])`)
+
+// parseDuration parses any time.ParseDuration value, such as 90s, 1h30m or
+// 0.5h, and additionally accepts days (d) and weeks (w), e.g. 2d or 1w12h.
+func parseDuration(value string) (time.Duration, error) {
+
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var convertErr error
+	converted := dayWeekPattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := dayWeekPattern.FindStringSubmatch(match)
+		amount, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			convertErr = err
+			return match
+		}
+		hours := amount * 24
+		if parts[2] == "w" {
+			hours *= 7
+		}
+		return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+	})
+	if convertErr != nil {
+		return 0, fmt.Errorf("invalid duration \"%s\": %v", value, convertErr)
+	}
+
+	duration, err := time.ParseDuration(converted)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration \"%s\", use a value such as 30s, 1h30m, 0.5h, 2d or 1w", value)
+	}
+	return duration, nil
+}
+
+// parseTime parses an absolute or relative point in time. Times without a zone
+// and the day boundaries of today, yesterday and tomorrow are taken in location.
+func parseTime(value string, now time.Time, location *time.Location) (time.Time, error) {
+
+	value = strings.TrimSpace(value)
+	now = now.In(location)
+
+	if match := relativeTimePattern.FindStringSubmatch(strings.ToLower(value)); match != nil {
+		anchor := now
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+		switch match[1] {
+		case "today":
+			anchor = midnight
+		case "yesterday":
+			anchor = midnight.AddDate(0, 0, -1)
+		case "tomorrow":
+			anchor = midnight.AddDate(0, 0, 1)
+		}
+		if len(match[2]) == 0 {
+			return anchor, nil
+		}
+		offset, err := parseDuration(match[3])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if match[2] == "-" {
+			offset = -offset
+		}
+		return anchor.Add(offset), nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time \"%s\", use RFC3339 (2006-01-02T15:04:05Z), a local time (2006-01-02 15:04:05), a date (2006-01-02) or an expression such as now-2h or yesterday", value)
+}
+
+// loadTimezone returns the zone used for times given without one. It defaults
+// to the zone of the local machine.
+func loadTimezone(name string) (*time.Location, error) {
+
+	if len(name) == 0 {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("an invalid \"timezone\" value was entered: %v", err)
+	}
+	return location, nil
+}
+
+// processTimeRange sets StartTime and EndTime from the time range flags.
+func (o *LogParameters) processTimeRange(now time.Time) error {
+
+	location, err := loadTimezone(o.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if len(o.Since) > 0 && len(o.Tail) > 0 {
+		return fmt.Errorf("only one of \"since\" and \"tail\" can be used")
+	}
+	sinceFlag, since := "since", o.Since
+	if len(o.Tail) > 0 {
+		sinceFlag, since = "tail", o.Tail
+	}
+	if len(since) > 0 && len(o.SinceTime) > 0 {
+		return fmt.Errorf("only one of \"%s\" and \"since-time\" can be used", sinceFlag)
+	}
+
+	var startTime, endTime time.Time
+	if len(since) > 0 {
+		duration, err := parseDuration(since)
+		if err != nil {
+			return fmt.Errorf("an invalid \"%s\" value was entered: %v", sinceFlag, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("an invalid \"%s\" value was entered: a positive duration is required", sinceFlag)
+		}
+		startTime = now.Add(-duration)
+	}
+
+	if len(o.SinceTime) > 0 {
+		startTime, err = parseTime(o.SinceTime, now, location)
+		if err != nil {
+			return fmt.Errorf("an invalid \"since-time\" value was entered: %v", err)
+		}
+	}
+
+	if len(o.UntilTime) > 0 {
+		if o.Follow {
+			return fmt.Errorf("\"until-time\" cannot be used together with \"follow\"")
+		}
+		endTime, err = parseTime(o.UntilTime, now, location)
+		if err != nil {
+			return fmt.Errorf("an invalid \"until-time\" value was entered: %v", err)
+		}
+	} else if !startTime.IsZero() {
+		endTime = now
+	}
+
+	if !startTime.IsZero() && endTime.Before(startTime) {
+		return fmt.Errorf("the end of the time range %s is before its start %s", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339))
+	}
+
+	if !startTime.IsZero() {
+		o.StartTime = startTime.UTC().Format(time.RFC3339Nano)
+	}
+	if !endTime.IsZero() {
+		o.EndTime = endTime.UTC().Format(time.RFC3339Nano)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		TestName     string
+		ShouldFail   bool
+		Value        string
+		TestDuration time.Duration
+		Error        error
+	}{
+		{"Seconds", false, "30s", 30 * time.Second, nil},
+		{"Composite duration", false, "1h30m", 90 * time.Minute, nil},
+		{"Fractional hours", false, "0.5h", 30 * time.Minute, nil},
+		{"Days", false, "2d", 48 * time.Hour, nil},
+		{"Weeks and hours", false, "1w12h", 180 * time.Hour, nil},
+		{"Fractional days", false, "1.5d", 36 * time.Hour, nil},
+		{"Missing unit", true, "30", 0, fmt.Errorf("invalid duration \"30\", use a value such as 30s, 1h30m, 0.5h, 2d or 1w")},
+		{"Unknown unit", true, "3y", 0, fmt.Errorf("invalid duration \"3y\", use a value such as 30s, 1h30m, 0.5h, 2d or 1w")},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		duration, err := parseDuration(tt.Value)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if duration != tt.TestDuration {
+			t.Errorf("Expected duration %v found %v", tt.TestDuration, duration)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+	now := time.Date(2021, 3, 18, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Value      string
+		Location   *time.Location
+		TestTime   time.Time
+		Error      error
+	}{
+		{"RFC3339", false, "2021-03-18T06:41:17Z", time.UTC, time.Date(2021, 3, 18, 6, 41, 17, 0, time.UTC), nil},
+		{"RFC3339 with offset", false, "2021-03-18T06:41:17+02:00", berlin, time.Date(2021, 3, 18, 4, 41, 17, 0, time.UTC), nil},
+		{"Local time", false, "2021-03-18 06:41:17", berlin, time.Date(2021, 3, 18, 5, 41, 17, 0, time.UTC), nil},
+		{"Local time without seconds", false, "2021-03-18T06:41", time.UTC, time.Date(2021, 3, 18, 6, 41, 0, 0, time.UTC), nil},
+		{"Date", false, "2021-03-17", berlin, time.Date(2021, 3, 16, 23, 0, 0, 0, time.UTC), nil},
+		{"Now", false, "now", time.UTC, now, nil},
+		{"Now minus duration", false, "now-2h", time.UTC, now.Add(-2 * time.Hour), nil},
+		{"Now plus duration", false, "now + 1d", time.UTC, now.Add(24 * time.Hour), nil},
+		{"Today", false, "today", berlin, time.Date(2021, 3, 17, 23, 0, 0, 0, time.UTC), nil},
+		{"Yesterday", false, "Yesterday", time.UTC, time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC), nil},
+		{"Yesterday plus duration", false, "yesterday+6h", time.UTC, time.Date(2021, 3, 17, 6, 0, 0, 0, time.UTC), nil},
+		{"Invalid expression", true, "now-2x", time.UTC, time.Time{}, fmt.Errorf("invalid duration \"2x\", use a value such as 30s, 1h30m, 0.5h, 2d or 1w")},
+		{"Invalid time", true, "18/03/2021", time.UTC, time.Time{}, fmt.Errorf("invalid time \"18/03/2021\", use RFC3339 (2006-01-02T15:04:05Z), a local time (2006-01-02 15:04:05), a date (2006-01-02) or an expression such as now-2h or yesterday")},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		parsed, err := parseTime(tt.Value, now, tt.Location)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if !parsed.Equal(tt.TestTime) {
+			t.Errorf("Expected time %v found %v", tt.TestTime, parsed)
+		}
+	}
+}
+
+func TestProcessTimeRange(t *testing.T) {
+	now := time.Date(2021, 3, 18, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		TestName      string
+		ShouldFail    bool
+		LogParameters LogParameters
+		StartTime     string
+		EndTime       string
+		Error         error
+	}{
+		{
+			"No time range",
+			false,
+			LogParameters{},
+			"",
+			"",
+			nil,
+		},
+		{
+			"Since",
+			false,
+			LogParameters{Since: "1h30m"},
+			"2021-03-18T09:00:00Z",
+			"2021-03-18T10:30:00Z",
+			nil,
+		},
+		{
+			"Tail",
+			false,
+			LogParameters{Tail: "0.5h"},
+			"2021-03-18T10:00:00Z",
+			"2021-03-18T10:30:00Z",
+			nil,
+		},
+		{
+			"Since time and until time",
+			false,
+			LogParameters{SinceTime: "2021-03-17 08:00", UntilTime: "now-2h", Timezone: "UTC"},
+			"2021-03-17T08:00:00Z",
+			"2021-03-18T08:30:00Z",
+			nil,
+		},
+		{
+			"Until time only",
+			false,
+			LogParameters{UntilTime: "today", Timezone: "UTC"},
+			"",
+			"2021-03-18T00:00:00Z",
+			nil,
+		},
+		{
+			"Since and tail",
+			true,
+			LogParameters{Since: "1h", Tail: "1h"},
+			"",
+			"",
+			fmt.Errorf("only one of \"since\" and \"tail\" can be used"),
+		},
+		{
+			"Since and since time",
+			true,
+			LogParameters{Since: "1h", SinceTime: "today"},
+			"",
+			"",
+			fmt.Errorf("only one of \"since\" and \"since-time\" can be used"),
+		},
+		{
+			"Negative since",
+			true,
+			LogParameters{Since: "-1h"},
+			"",
+			"",
+			fmt.Errorf("an invalid \"since\" value was entered: a positive duration is required"),
+		},
+		{
+			"Until time before since time",
+			true,
+			LogParameters{SinceTime: "now-1h", UntilTime: "now-2h"},
+			"",
+			"",
+			fmt.Errorf("the end of the time range 2021-03-18T08:30:00Z is before its start 2021-03-18T09:30:00Z"),
+		},
+		{
+			"Until time in follow mode",
+			true,
+			LogParameters{UntilTime: "now", Follow: true},
+			"",
+			"",
+			fmt.Errorf("\"until-time\" cannot be used together with \"follow\""),
+		},
+		{
+			"Invalid timezone",
+			true,
+			LogParameters{SinceTime: "today", Timezone: "Mars/Olympus"},
+			"",
+			"",
+			fmt.Errorf("an invalid \"timezone\" value was entered: unknown time zone Mars/Olympus"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		logParameters := tt.LogParameters
+		err := logParameters.processTimeRange(now)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if logParameters.StartTime != tt.StartTime || logParameters.EndTime != tt.EndTime {
+			t.Errorf("Expected time range %q - %q found %q - %q", tt.StartTime, tt.EndTime, logParameters.StartTime, logParameters.EndTime)
+		}
+	}
+}
@@ -1,10 +1,44 @@
 package constants
 
+import "time"
+
 const (
-	LimitLowerBound = 0
-	LimitUpperBound = 1000
-	Deployment      = "deployment"
-	DaemonSet       = "daemonset"
-	StatefulSet     = "statefulset"
-	Podname         = "podname"
+	LimitLowerBound          = 0
+	LimitUpperBound          = 1000
+	Deployment               = "deployment"
+	DaemonSet                = "daemonset"
+	StatefulSet              = "statefulset"
+	Podname                  = "podname"
+	Pod                      = "pod"
+	Job                      = "job"
+	CronJob                  = "cronjob"
+	ReplicaSet               = "replicaset"
+	ReplicationController    = "replicationcontroller"
+	DeploymentConfig         = "deploymentconfig"
+	Service                  = "service"
+	BuildConfig              = "buildconfig"
+	Node                     = "node"
+	Unit                     = "unit"
+	FollowInterval           = 2 * time.Second
+	ReorderWindow            = 5 * time.Second
+	APIUrlEnv                = "LOG_EXPLORATION_API_URL"
+	APINamespace             = "openshift-logging"
+	APIRoute                 = "log-exploration-api-route"
+	APIService               = "log-exploration-api"
+	APILogsPath              = "/logs"
+	BackendAPI               = "api"
+	BackendElasticsearch     = "elasticsearch"
+	ESIndices                = "app-*,infra-*,audit-*"
+	BackendLoki              = "loki"
+	LokiTenantApplication    = "application"
+	LokiTenantInfrastructure = "infrastructure"
+	LokiTenantAudit          = "audit"
+	LokiLookback             = 7 * 24 * time.Hour
+	ContextLookback          = 24 * time.Hour
+	OrderAscending           = "asc"
+	OrderDescending          = "desc"
+	MaxConcurrency           = 10
+	MaxRetries               = 4
+	RetryBackoff             = 500 * time.Millisecond
+	MaxRetryBackoff          = 30 * time.Second
 )
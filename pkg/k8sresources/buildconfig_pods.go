@@ -0,0 +1,58 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	buildv1 "github.com/openshift/api/build/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// getBuildConfigSelector selects the pods of every build started from the build
+// config. Builds carry the build config name as a label, their pods the build name.
+func getBuildConfigSelector(kubernetesOptions *client.KubernetesOptions, targetBuildConfig string, namespace string) (labels.Selector, error) {
+
+	if kubernetesOptions.DynamicClient == nil {
+		return nil, fmt.Errorf("build configs require access to the OpenShift API")
+	}
+
+	object, err := kubernetesOptions.DynamicClient.Resource(buildv1.Resource("buildconfigs").WithVersion("v1")).Namespace(namespace).Get(context.Background(), targetBuildConfig, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("build config", targetBuildConfig, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching build config \"%v\": %w", targetBuildConfig, err)
+	}
+
+	buildConfig := &buildv1.BuildConfig{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, buildConfig)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading build config \"%v\": %v", targetBuildConfig, err)
+	}
+
+	options := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{buildv1.BuildConfigLabel: buildConfig.Name}).String(),
+	}
+	builds, err := kubernetesOptions.DynamicClient.Resource(buildv1.Resource("builds").WithVersion("v1")).Namespace(namespace).List(context.Background(), options)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching build config builds: %w", err)
+	}
+
+	var buildNames []string
+	for _, build := range builds.Items {
+		buildNames = append(buildNames, build.GetName())
+	}
+	if len(buildNames) == 0 {
+		return labels.Nothing(), nil
+	}
+
+	requirement, err := labels.NewRequirement(buildv1.BuildLabel, selection.In, buildNames)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while selecting build config pods: %v", err)
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
@@ -0,0 +1,139 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetBuildConfigPodsList(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		ShouldFail  bool
+		BuildConfig string
+		Namespace   string
+		PodList     []string
+		TestPodList []string
+		Error       error
+	}{
+		{
+			"BuildConfig doesn't exist",
+			false,
+			"dummy-bc",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			fmt.Errorf("build config \"dummy-bc\" not found in namespace \"openshift-logging\""),
+		},
+		{
+			"BuildConfig is present",
+			false,
+			"openshift-bc",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-bc-1-build", "openshift-bc-2-build"},
+			nil,
+		},
+		{
+			"BuildConfig without builds",
+			false,
+			"openshift-idle-bc",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			nil,
+		},
+	}
+
+	objects := []runtime.Object{
+		buildConfigObject("openshift-bc"),
+		buildConfigObject("openshift-idle-bc"),
+		buildObject("openshift-bc-1", "openshift-bc"),
+		buildObject("openshift-bc-2", "openshift-bc"),
+		buildObject("openshift-other-1", "openshift-other"),
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		buildv1.Resource("builds").WithVersion("v1"):       "BuildList",
+		buildv1.Resource("buildconfigs").WithVersion("v1"): "BuildConfigList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	clientset := fake.NewSimpleClientset()
+	for _, build := range []string{"openshift-bc-1", "openshift-bc-2", "openshift-other-1"} {
+		clientset.CoreV1().Pods("openshift-logging").Create(context.TODO(),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      build + "-build",
+				Namespace: "openshift-logging",
+				Labels:    map[string]string{buildv1.BuildLabel: build},
+			},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "sti-build",
+						},
+					},
+				},
+			}, metav1.CreateOptions{})
+	}
+
+	kubernetesOptions := &client.KubernetesOptions{Clientset: clientset, DynamicClient: dynamicClient}
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		podList, err := GetPodsList(kubernetesOptions, &Resources{}, Resource{Type: constants.BuildConfig, Name: tt.BuildConfig}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		if len(tt.PodList) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+		} else {
+			for i, v := range tt.PodList {
+				if v != tt.TestPodList[i] {
+					t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+				}
+			}
+		}
+	}
+}
+
+func buildConfigObject(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "build.openshift.io/v1",
+		"kind":       "BuildConfig",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "openshift-logging",
+		},
+	}}
+}
+
+func buildObject(name string, buildConfig string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "build.openshift.io/v1",
+		"kind":       "Build",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "openshift-logging",
+			"labels":    map[string]interface{}{buildv1.BuildConfigLabel: buildConfig},
+		},
+	}}
+}
@@ -0,0 +1,61 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// jobNameLabel is set by the job controller on every pod of a job.
+const jobNameLabel = "job-name"
+
+// getCronJobSelector selects the pods of every job currently owned by the cron
+// job. Clusters serving cron jobs from batch/v1beta1 only are supported as well.
+func getCronJobSelector(kubernetesOptions *client.KubernetesOptions, targetCronJob string, namespace string) (labels.Selector, error) {
+
+	var cronJobUID types.UID
+	cronJob, err := kubernetesOptions.Clientset.BatchV1().CronJobs(namespace).Get(context.Background(), targetCronJob, metav1.GetOptions{})
+	if err == nil {
+		cronJobUID = cronJob.UID
+	} else if errors.IsNotFound(err) {
+		betaCronJob, betaErr := kubernetesOptions.Clientset.BatchV1beta1().CronJobs(namespace).Get(context.Background(), targetCronJob, metav1.GetOptions{})
+		if betaErr != nil && !errors.IsNotFound(betaErr) {
+			return nil, fmt.Errorf("an error occurred while fetching cron job \"%v\": %w", targetCronJob, betaErr)
+		}
+		if betaErr != nil {
+			return nil, notFoundError("cron job", targetCronJob, namespace)
+		}
+		cronJobUID = betaCronJob.UID
+	} else {
+		return nil, fmt.Errorf("an error occurred while fetching cron job \"%v\": %w", targetCronJob, err)
+	}
+
+	jobs, err := kubernetesOptions.Clientset.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching cron job jobs: %w", err)
+	}
+
+	var jobNames []string
+	for _, job := range jobs.Items {
+		for _, owner := range job.OwnerReferences {
+			if owner.Kind == "CronJob" && owner.UID == cronJobUID {
+				jobNames = append(jobNames, job.Name)
+				break
+			}
+		}
+	}
+	if len(jobNames) == 0 {
+		return labels.Nothing(), nil
+	}
+
+	requirement, err := labels.NewRequirement(jobNameLabel, selection.In, jobNames)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while selecting cron job pods: %v", err)
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
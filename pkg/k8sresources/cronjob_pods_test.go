@@ -0,0 +1,148 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetCronJobPodsList(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		ShouldFail  bool
+		CronJob     string
+		Namespace   string
+		PodList     []string
+		TestPodList []string
+		Error       error
+	}{
+		{
+			"CronJob doesn't exist",
+			false,
+			"dummy-cronjob",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			fmt.Errorf("cron job \"dummy-cronjob\" not found in namespace \"openshift-logging\""),
+		},
+		{
+			"CronJob is present",
+			false,
+			"openshift-cronjob",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-cronjob-1-pod", "openshift-cronjob-2-pod"},
+			nil,
+		},
+		{
+			"CronJob from batch/v1beta1",
+			false,
+			"openshift-beta-cronjob",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-beta-cronjob-1-pod"},
+			nil,
+		},
+		{
+			"CronJob without jobs",
+			false,
+			"openshift-idle-cronjob",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			nil,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "openshift-cronjob",
+				Namespace: "openshift-logging",
+				UID:       "cronjob-uid",
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "openshift-idle-cronjob",
+				Namespace: "openshift-logging",
+				UID:       "idle-cronjob-uid",
+			},
+		},
+		&batchv1beta1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "openshift-beta-cronjob",
+				Namespace: "openshift-logging",
+				UID:       "beta-cronjob-uid",
+			},
+		})
+
+	jobs := map[string]string{
+		"openshift-cronjob-1":      "cronjob-uid",
+		"openshift-cronjob-2":      "cronjob-uid",
+		"openshift-beta-cronjob-1": "beta-cronjob-uid",
+		"openshift-manual-job":     "",
+	}
+	for job, owner := range jobs {
+		var ownerReferences []metav1.OwnerReference
+		if len(owner) > 0 {
+			ownerReferences = []metav1.OwnerReference{{Kind: "CronJob", UID: types.UID(owner)}}
+		}
+		clientset.BatchV1().Jobs("openshift-logging").Create(context.TODO(),
+			&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+				Name:            job,
+				Namespace:       "openshift-logging",
+				OwnerReferences: ownerReferences,
+			}}, metav1.CreateOptions{})
+
+		clientset.CoreV1().Pods("openshift-logging").Create(context.TODO(),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      job + "-pod",
+				Namespace: "openshift-logging",
+				Labels:    map[string]string{"job-name": job},
+			},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "logging",
+						},
+					},
+				},
+			}, metav1.CreateOptions{})
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		podList, err := GetPodsList(&client.KubernetesOptions{Clientset: clientset}, &Resources{}, Resource{Type: constants.CronJob, Name: tt.CronJob}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		if len(tt.PodList) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+		} else {
+			for i, v := range tt.PodList {
+				if v != tt.TestPodList[i] {
+					t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+				}
+			}
+		}
+	}
+}
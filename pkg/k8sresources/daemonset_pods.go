@@ -3,47 +3,21 @@ package k8sresources
 import (
 	"context"
 	"fmt"
-	appsv1 "k8s.io/api/apps/v1"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/kubernetes"
 )
 
-func GetDaemonSetPodsList(clientset kubernetes.Interface, podList *[]string, targetDaemonset string, namespace string) error {
+func getDaemonSetSelector(kubernetesOptions *client.KubernetesOptions, targetDaemonset string, namespace string) (labels.Selector, error) {
 
-	var requiredDaemonSet appsv1.DaemonSet
-	daemonsets, _ := clientset.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
-
-	requiredDaemonSetFound := false
-	for _, daemonset := range daemonsets.Items {
-		if daemonset.ObjectMeta.Name == targetDaemonset {
-			requiredDaemonSetFound = true
-			requiredDaemonSet = daemonset
-			break
-		}
-	}
-
-	if !requiredDaemonSetFound {
-		if len(namespace) > 0 {
-			return fmt.Errorf("daemon set \"%v\" not found in namespace \"%v\"", targetDaemonset, namespace)
-		} else {
-			return fmt.Errorf("daemon set \"%v\" not found", targetDaemonset)
-		}
-	}
-
-	labelSelector := labels.Set(requiredDaemonSet.Spec.Selector.MatchLabels)
-
-	options := metav1.ListOptions{
-		LabelSelector: string(labelSelector.AsSelector().String()),
+	daemonset, err := kubernetesOptions.Clientset.AppsV1().DaemonSets(namespace).Get(context.Background(), targetDaemonset, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("daemon set", targetDaemonset, namespace)
 	}
-
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), options)
-
 	if err != nil {
-		return fmt.Errorf("an error occurred while fetching daemon set pods: %v", err)
+		return nil, fmt.Errorf("an error occurred while fetching daemon set \"%v\": %w", targetDaemonset, err)
 	}
-	for _, pod := range (*pods).Items {
-		*podList = append(*podList, pod.Name)
-	}
-	return nil
+
+	return metav1.LabelSelectorAsSelector(daemonset.Spec.Selector)
 }
@@ -0,0 +1,35 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	appsv1 "github.com/openshift/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func getDeploymentConfigSelector(kubernetesOptions *client.KubernetesOptions, targetDeploymentConfig string, namespace string) (labels.Selector, error) {
+
+	if kubernetesOptions.DynamicClient == nil {
+		return nil, fmt.Errorf("deployment configs require access to the OpenShift API")
+	}
+
+	object, err := kubernetesOptions.DynamicClient.Resource(appsv1.Resource("deploymentconfigs").WithVersion("v1")).Namespace(namespace).Get(context.Background(), targetDeploymentConfig, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("deployment config", targetDeploymentConfig, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching deployment config \"%v\": %w", targetDeploymentConfig, err)
+	}
+
+	deploymentConfig := &appsv1.DeploymentConfig{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, deploymentConfig)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading deployment config \"%v\": %v", targetDeploymentConfig, err)
+	}
+
+	return labels.SelectorFromSet(deploymentConfig.Spec.Selector), nil
+}
@@ -0,0 +1,109 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeploymentConfigPodsList(t *testing.T) {
+	tests := []struct {
+		TestName         string
+		ShouldFail       bool
+		DeploymentConfig string
+		Namespace        string
+		PodList          []string
+		TestPodList      []string
+		Error            error
+	}{
+		{
+			"DeploymentConfig doesn't exist",
+			false,
+			"dummy-dc",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			fmt.Errorf("deployment config \"dummy-dc\" not found in namespace \"openshift-logging\""),
+		},
+		{
+			"DeploymentConfig is present",
+			false,
+			"openshift-dc",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-dc-1-abcde"},
+			nil,
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps.openshift.io/v1",
+			"kind":       "DeploymentConfig",
+			"metadata": map[string]interface{}{
+				"name":      "openshift-dc",
+				"namespace": "openshift-logging",
+			},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"deploymentconfig": "openshift-dc"},
+			},
+		}})
+
+	clientset := fake.NewSimpleClientset()
+	pods := map[string]string{
+		"openshift-dc-1-abcde":    "openshift-dc",
+		"openshift-other-1-fghij": "openshift-other",
+	}
+	for pod, deploymentConfig := range pods {
+		clientset.CoreV1().Pods("openshift-logging").Create(context.TODO(),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      pod,
+				Namespace: "openshift-logging",
+				Labels:    map[string]string{"deploymentconfig": deploymentConfig},
+			},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "logging",
+						},
+					},
+				},
+			}, metav1.CreateOptions{})
+	}
+
+	kubernetesOptions := &client.KubernetesOptions{Clientset: clientset, DynamicClient: dynamicClient}
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		podList, err := GetPodsList(kubernetesOptions, &Resources{}, Resource{Type: constants.DeploymentConfig, Name: tt.DeploymentConfig}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		if len(tt.PodList) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+		} else {
+			for i, v := range tt.PodList {
+				if v != tt.TestPodList[i] {
+					t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+				}
+			}
+		}
+	}
+}
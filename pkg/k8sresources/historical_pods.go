@@ -0,0 +1,143 @@
+package k8sresources
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PodMatcher recognises the pods of a resource among the pods recorded in the
+// log store, including pods which no longer exist in the cluster. A pod matches
+// when it lives in Namespace, its labels satisfy Selector and its name matches
+// Name. A nil Selector or Name matches every pod. Hosts restricts the nodes the
+// pod ran on, see MatchesHost.
+type PodMatcher struct {
+	Namespace string
+	Selector  labels.Selector
+	Name      *regexp.Regexp
+	Hosts     []string
+}
+
+// podNameSuffixes are the suffixes controllers append to the name of the
+// workload when naming its pods. Deployments add the ReplicaSet hash,
+// stateful sets the ordinal and deployment configs the revision.
+var podNameSuffixes = map[string]string{
+	constants.Deployment:            `-[a-z0-9]{1,10}-[a-z0-9]{5}`,
+	constants.DaemonSet:             `-[a-z0-9]{5}`,
+	constants.StatefulSet:           `-[0-9]+`,
+	constants.Job:                   `-[a-z0-9]{5}`,
+	constants.CronJob:               `-[0-9]+-[a-z0-9]{5}`,
+	constants.ReplicaSet:            `-[a-z0-9]{5}`,
+	constants.ReplicationController: `-[a-z0-9]{5}`,
+	constants.DeploymentConfig:      `-[0-9]+-([a-z0-9]{5}|deploy)`,
+	constants.BuildConfig:           `-[0-9]+-build`,
+}
+
+// GetResourcesPodMatchers builds one matcher per requested resource. The label
+// selector of a workload does not include the revision of its pods, so the
+// matchers also cover pods of older revisions which have been replaced since.
+func GetResourcesPodMatchers(kubernetesOptions *client.KubernetesOptions, resources *Resources, namespace string) ([]PodMatcher, error) {
+
+	userSelector := labels.Everything()
+	if len(resources.Selector) > 0 {
+		var err error
+		userSelector, err = labels.Parse(resources.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while parsing selector \"%s\": %v", resources.Selector, err)
+		}
+	}
+
+	if len(resources.Targets) == 0 {
+		return []PodMatcher{{Namespace: namespace, Selector: userSelector, Hosts: resources.Nodes}}, nil
+	}
+
+	var matchers []PodMatcher
+	for _, target := range resources.Targets {
+		matcher := PodMatcher{Namespace: namespace, Selector: userSelector, Hosts: resources.Nodes}
+
+		if target.Type == constants.Unit {
+			// journal logs are not written by pods
+			continue
+		}
+
+		if target.Type == constants.Node {
+			if len(resources.Nodes) > 0 && !containsString(resources.Nodes, target.Name) {
+				continue
+			}
+			matcher.Hosts = []string{target.Name}
+			matchers = append(matchers, matcher)
+			continue
+		}
+
+		if target.Type == constants.Pod {
+			matcher.Name = regexp.MustCompile("^" + regexp.QuoteMeta(target.Name) + "$")
+			matchers = append(matchers, matcher)
+			continue
+		}
+
+		getSelector, ok := selectorFuncs[target.Type]
+		if !ok {
+			return nil, fmt.Errorf("logs for invalid resource type \"%s\" requested", target.Type)
+		}
+		selector, err := getSelector(kubernetesOptions, target.Name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if requirements, selectable := selector.Requirements(); selectable {
+			for _, requirement := range requirements {
+				matcher.Selector = matcher.Selector.Add(requirement)
+			}
+		}
+		if suffix, ok := podNameSuffixes[target.Type]; ok {
+			matcher.Name = regexp.MustCompile("^" + regexp.QuoteMeta(target.Name) + suffix + "$")
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// Matches reports whether a pod recorded in the log store belongs to the
+// resource. The labels of the pod are only compared when they were recorded.
+func (m PodMatcher) Matches(namespace string, podName string, podLabels map[string]string) bool {
+
+	if len(podName) == 0 || (len(m.Namespace) > 0 && namespace != m.Namespace) {
+		return false
+	}
+	if m.Name != nil && !m.Name.MatchString(podName) {
+		return false
+	}
+	if m.Selector == nil || podLabels == nil {
+		return m.Name != nil || m.Selector == nil || m.Selector.Empty()
+	}
+
+	requirements, _ := m.Selector.Requirements()
+	for _, requirement := range requirements {
+		if !requirement.Matches(storedLabels(podLabels, requirement.Key())) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesHost reports whether a pod which logged on host may belong to the
+// resource. Logs without a host match.
+func (m PodMatcher) MatchesHost(host string) bool {
+	return len(m.Hosts) == 0 || len(host) == 0 || containsString(m.Hosts, host)
+}
+
+// storedLabels looks a label up the way the collector stores it. Dots in label
+// keys are replaced with underscores before the logs are indexed.
+func storedLabels(podLabels map[string]string, key string) labels.Set {
+
+	if value, ok := podLabels[key]; ok {
+		return labels.Set{key: value}
+	}
+	if value, ok := podLabels[strings.ReplaceAll(key, ".", "_")]; ok {
+		return labels.Set{key: value}
+	}
+	return labels.Set{}
+}
@@ -0,0 +1,220 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetResourcesPodMatchers(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Resources  Resources
+		Namespace  string
+		PodName    string
+		PodLabels  map[string]string
+		Matches    bool
+		Error      error
+	}{
+		{
+			"Old revision of a deployment",
+			false,
+			Resources{Targets: []Resource{{Type: constants.Deployment, Name: "kibana"}}},
+			"openshift-logging",
+			"kibana-5d8f7c6b9-x2k4p",
+			map[string]string{"app": "kibana", "pod-template-hash": "5d8f7c6b9"},
+			true,
+			nil,
+		},
+		{
+			"Old revision of a deployment without recorded labels",
+			false,
+			Resources{Targets: []Resource{{Type: constants.Deployment, Name: "kibana"}}},
+			"openshift-logging",
+			"kibana-5d8f7c6b9-x2k4p",
+			nil,
+			true,
+			nil,
+		},
+		{
+			"Deployment sharing the name prefix",
+			false,
+			Resources{Targets: []Resource{{Type: constants.Deployment, Name: "kibana"}}},
+			"openshift-logging",
+			"kibana-proxy-5d8f7c6b9-x2k4p",
+			map[string]string{"app": "kibana-proxy"},
+			false,
+			nil,
+		},
+		{
+			"Pod in another namespace",
+			false,
+			Resources{Targets: []Resource{{Type: constants.Deployment, Name: "kibana"}}},
+			"default",
+			"kibana-5d8f7c6b9-x2k4p",
+			map[string]string{"app": "kibana"},
+			false,
+			nil,
+		},
+		{
+			"Replaced stateful set replica",
+			false,
+			Resources{Targets: []Resource{{Type: constants.StatefulSet, Name: "elasticsearch"}}},
+			"openshift-logging",
+			"elasticsearch-2",
+			map[string]string{"app_kubernetes_io/name": "elasticsearch"},
+			true,
+			nil,
+		},
+		{
+			"Stateful set replica with other labels",
+			false,
+			Resources{Targets: []Resource{{Type: constants.StatefulSet, Name: "elasticsearch"}}},
+			"openshift-logging",
+			"elasticsearch-2",
+			map[string]string{"app_kubernetes_io/name": "kibana"},
+			false,
+			nil,
+		},
+		{
+			"Deleted pod",
+			false,
+			Resources{Targets: []Resource{{Type: constants.Pod, Name: "fluentd-abcde"}}},
+			"openshift-logging",
+			"fluentd-abcde",
+			nil,
+			true,
+			nil,
+		},
+		{
+			"Selector only",
+			false,
+			Resources{Selector: "component=fluentd"},
+			"openshift-logging",
+			"fluentd-abcde",
+			map[string]string{"component": "fluentd"},
+			true,
+			nil,
+		},
+		{
+			"Selector only without recorded labels",
+			false,
+			Resources{Selector: "component=fluentd"},
+			"openshift-logging",
+			"fluentd-abcde",
+			nil,
+			false,
+			nil,
+		},
+		{
+			"Deployment doesn't exist",
+			true,
+			Resources{Targets: []Resource{{Type: constants.Deployment, Name: "dummy"}}},
+			"openshift-logging",
+			"dummy-5d8f7c6b9-x2k4p",
+			nil,
+			false,
+			fmt.Errorf("deployment \"dummy\" not found in namespace \"openshift-logging\""),
+		},
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.AppsV1().Deployments("openshift-logging").Create(context.TODO(),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      "kibana",
+			Namespace: "openshift-logging",
+		},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "kibana"}},
+			},
+		}, metav1.CreateOptions{})
+	clientset.AppsV1().StatefulSets("openshift-logging").Create(context.TODO(),
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "elasticsearch"}},
+			},
+		}, metav1.CreateOptions{})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		matchers, err := GetResourcesPodMatchers(&client.KubernetesOptions{Clientset: clientset}, &tt.Resources, "openshift-logging")
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		matches := false
+		for _, matcher := range matchers {
+			matches = matches || matcher.Matches(tt.Namespace, tt.PodName, tt.PodLabels)
+		}
+		if matches != tt.Matches {
+			t.Errorf("Expected pod %s to match: %v, found %v", tt.PodName, tt.Matches, matches)
+		}
+	}
+}
+
+func TestPodMatcherHosts(t *testing.T) {
+	tests := []struct {
+		TestName  string
+		Resources Resources
+		Host      string
+		Matches   bool
+	}{
+		{
+			"Pod of the node",
+			Resources{Targets: []Resource{{Type: constants.Node, Name: "worker-0"}}},
+			"worker-0",
+			true,
+		},
+		{
+			"Pod of another node",
+			Resources{Targets: []Resource{{Type: constants.Node, Name: "worker-0"}}},
+			"worker-1",
+			false,
+		},
+		{
+			"Pod of a selected node",
+			Resources{Targets: []Resource{{Type: constants.Pod, Name: "fluentd-abcde"}}, Nodes: []string{"worker-0", "worker-1"}},
+			"worker-1",
+			true,
+		},
+		{
+			"Node which is not selected",
+			Resources{Targets: []Resource{{Type: constants.Node, Name: "worker-0"}}, Nodes: []string{"worker-1"}},
+			"worker-0",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		matchers, err := GetResourcesPodMatchers(&client.KubernetesOptions{Clientset: fake.NewSimpleClientset()}, &tt.Resources, "openshift-logging")
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		matches := false
+		for _, matcher := range matchers {
+			matches = matches || matcher.MatchesHost(tt.Host) && matcher.Matches("openshift-logging", "fluentd-abcde", nil)
+		}
+		if matches != tt.Matches {
+			t.Errorf("Expected pod on %s to match: %v, found %v", tt.Host, tt.Matches, matches)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func getJobSelector(kubernetesOptions *client.KubernetesOptions, targetJob string, namespace string) (labels.Selector, error) {
+
+	job, err := kubernetesOptions.Clientset.BatchV1().Jobs(namespace).Get(context.Background(), targetJob, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("job", targetJob, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching job \"%v\": %w", targetJob, err)
+	}
+
+	return metav1.LabelSelectorAsSelector(job.Spec.Selector)
+}
@@ -0,0 +1,101 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetJobPodsList(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		ShouldFail  bool
+		Job         string
+		Namespace   string
+		PodList     []string
+		TestPodList []string
+		Error       error
+	}{
+		{
+			"Job doesn't exist",
+			false,
+			"dummy-job",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			fmt.Errorf("job \"dummy-job\" not found in namespace \"openshift-logging\""),
+		},
+		{
+			"Job is present",
+			false,
+			"openshift-job",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-job-pod"},
+			nil,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "openshift-job",
+				Namespace:   "openshift-logging",
+				Annotations: map[string]string{},
+			},
+			Spec: batchv1.JobSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"name": "logging"},
+				},
+			},
+		})
+
+	clientset.CoreV1().Pods("openshift-logging").Create(context.TODO(),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "openshift-job-pod",
+			Namespace:   "openshift-logging",
+			Annotations: map[string]string{},
+			Labels:      map[string]string{"name": "logging"},
+		},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "logging",
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		podList, err := GetPodsList(&client.KubernetesOptions{Clientset: clientset}, &Resources{}, Resource{Type: constants.Job, Name: tt.Job}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		if len(tt.PodList) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+		} else {
+			for i, v := range tt.PodList {
+				if v != tt.TestPodList[i] {
+					t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+				}
+			}
+		}
+	}
+}
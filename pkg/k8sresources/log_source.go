@@ -4,37 +4,29 @@ import (
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
 )
 
-func GetResourcesPodList(kubernetesOptions *client.KubernetesOptions, resources *Resources, namespace string) ([]string, error) {
+func GetResourcesPodList(kubernetesOptions *client.KubernetesOptions, resources *Resources, namespace string) ([]Pod, error) {
 
-	var podList []string
-
-	if resources.IsDeployment {
-		err := GetDeploymentPodsList(kubernetesOptions.Clientset, &podList, resources.Name, namespace)
-		if err != nil {
-			return nil, err
+	if len(resources.Targets) == 0 {
+		if len(resources.Selector) == 0 && len(resources.FieldSelector) == 0 {
+			return nil, nil
 		}
-		return podList, nil
+		return GetSelectedPodsList(kubernetesOptions.Clientset, resources, nil, namespace)
 	}
 
-	if resources.IsDaemonSet {
-		err := GetDaemonSetPodsList(kubernetesOptions.Clientset, &podList, resources.Name, namespace)
+	var podList []Pod
+	seen := map[string]bool{}
+	for _, target := range resources.Targets {
+		pods, err := GetPodsList(kubernetesOptions, resources, target, namespace)
 		if err != nil {
 			return nil, err
 		}
-		return podList, nil
-	}
-
-	if resources.IsStatefulSet {
-		err := GetStatefulSetPodsList(kubernetesOptions.Clientset, &podList, resources.Name, namespace)
-		if err != nil {
-			return nil, err
+		for _, pod := range pods {
+			if seen[pod.key()] {
+				continue
+			}
+			seen[pod.key()] = true
+			podList = append(podList, pod)
 		}
-		return podList, nil
-	}
-
-	if resources.IsPod {
-		podList = append(podList, resources.Name)
-		return podList, nil
 	}
-	return nil, nil
+	return podList, nil
 }
@@ -3,6 +3,7 @@ package k8sresources
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
@@ -17,6 +18,7 @@ func TestGetResourcesPodList(t *testing.T) {
 		TestName    string
 		ShouldFail  bool
 		Resources   map[string]string
+		Selector    string
 		TestPodList []string
 		Namespace   string
 		Error       error
@@ -25,6 +27,7 @@ func TestGetResourcesPodList(t *testing.T) {
 			"Resource doesn't exist",
 			false,
 			map[string]string{"Deployment": "dummy-deployment"},
+			"",
 			[]string{},
 			"openshift-logging",
 			fmt.Errorf("deployment \"dummy-deployment\" not found in namespace \"openshift-logging\""),
@@ -33,10 +36,47 @@ func TestGetResourcesPodList(t *testing.T) {
 			"Resources are present",
 			false,
 			map[string]string{"Deployment": "openshift-deployment"},
+			"",
 			[]string{"openshift-deployment"},
 			"openshift-logging",
 			nil,
 		},
+		{
+			"Resources sharing pods",
+			false,
+			map[string]string{"Deployment": "openshift-deployment", "Pod": "openshift-deployment"},
+			"",
+			[]string{"openshift-deployment"},
+			"openshift-logging",
+			nil,
+		},
+		{
+			"Resource narrowed by selector",
+			false,
+			map[string]string{"Deployment": "openshift-deployment"},
+			"tier=backend",
+			[]string{},
+			"openshift-logging",
+			nil,
+		},
+		{
+			"Selector only",
+			false,
+			map[string]string{},
+			"tier=frontend",
+			[]string{"openshift-deployment"},
+			"openshift-logging",
+			nil,
+		},
+		{
+			"Invalid selector",
+			false,
+			map[string]string{},
+			"tier in (frontend",
+			[]string{},
+			"openshift-logging",
+			fmt.Errorf("an invalid label selector was entered: unable to parse requirement: found '', expected: ',' or ')'"),
+		},
 	}
 
 	clientset := fake.NewSimpleClientset(
@@ -58,7 +98,7 @@ func TestGetResourcesPodList(t *testing.T) {
 			Name:        "openshift-deployment",
 			Namespace:   "openshift-logging",
 			Annotations: map[string]string{},
-			Labels:      map[string]string{"name": "logging"},
+			Labels:      map[string]string{"name": "logging", "tier": "frontend"},
 		},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{
@@ -71,17 +111,9 @@ func TestGetResourcesPodList(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Log("Running:", tt.TestName)
-		resources := Resources{}
+		resources := Resources{Selector: tt.Selector}
 		for k, v := range tt.Resources {
-			switch k {
-			case "Deployment":
-				resources.IsDeployment = true
-			case "Daemonset":
-				resources.IsDaemonSet = true
-			case "Statefulset":
-				resources.IsStatefulSet = true
-			}
-			resources.Name = v
+			resources.Targets = append(resources.Targets, Resource{Type: strings.ToLower(k), Name: v})
 		}
 		kubernetesOptions := &client.KubernetesOptions{
 			Clientset:        clientset,
@@ -99,12 +131,13 @@ func TestGetResourcesPodList(t *testing.T) {
 			t.Errorf("Expected error is %v, found %v", tt.Error, err)
 		}
 
-		if len(podList) != len(tt.TestPodList) {
-			t.Errorf("Expected list %v found %v", tt.TestPodList, podList)
+		names := podNames(podList)
+		if len(names) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, names)
 		} else {
-			for i, v := range podList {
+			for i, v := range names {
 				if v != tt.TestPodList[i] {
-					t.Errorf("Expected list %v found %v", tt.TestPodList, podList)
+					t.Errorf("Expected list %v found %v", tt.TestPodList, names)
 				}
 			}
 		}
@@ -0,0 +1,203 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var projectResource = schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"}
+
+// NamespaceOptions select the namespaces logs are requested from. Without any
+// of them the logs of a single namespace are requested.
+type NamespaceOptions struct {
+	AllNamespaces bool
+	Namespaces    []string
+	Selector      string
+}
+
+// ResolveNamespaces returns the namespaces selected by the options, sorted, or
+// namespace when none is selected. Users who may not list namespaces get the
+// OpenShift projects they have access to instead.
+func ResolveNamespaces(kubernetesOptions *client.KubernetesOptions, options NamespaceOptions, namespace string) ([]string, error) {
+
+	if len(options.Namespaces) > 0 {
+		seen := map[string]bool{}
+		var namespaces []string
+		for _, name := range options.Namespaces {
+			if len(name) == 0 || seen[name] {
+				continue
+			}
+			seen[name] = true
+			namespaces = append(namespaces, name)
+		}
+		return namespaces, nil
+	}
+	if !options.AllNamespaces && len(options.Selector) == 0 {
+		return []string{namespace}, nil
+	}
+
+	selector, err := labels.Parse(options.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("an invalid namespace selector was entered: %v", err)
+	}
+	listOptions := metav1.ListOptions{LabelSelector: selector.String()}
+
+	var namespaces []string
+	namespaceList, err := kubernetesOptions.Clientset.CoreV1().Namespaces().List(context.Background(), listOptions)
+	switch {
+	case err == nil:
+		for _, item := range namespaceList.Items {
+			namespaces = append(namespaces, item.Name)
+		}
+	case apierrors.IsForbidden(err) && kubernetesOptions.DynamicClient != nil:
+		projects, projectErr := kubernetesOptions.DynamicClient.Resource(projectResource).List(context.Background(), listOptions)
+		if projectErr != nil {
+			return nil, fmt.Errorf("not allowed to list namespaces, use --namespaces to name them: %v", err)
+		}
+		for _, item := range projects.Items {
+			namespaces = append(namespaces, item.GetName())
+		}
+	case apierrors.IsForbidden(err):
+		return nil, fmt.Errorf("not allowed to list namespaces, use --namespaces to name them: %v", err)
+	default:
+		return nil, fmt.Errorf("an error occurred while listing namespaces: %v", err)
+	}
+
+	if len(namespaces) == 0 {
+		if len(options.Selector) > 0 {
+			return nil, fmt.Errorf("no namespace matches the namespace selector \"%s\"", options.Selector)
+		}
+		return nil, fmt.Errorf("no namespace is accessible")
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// GetNamespacesPodList resolves the resources in each of the namespaces. Every
+// resource has to exist in one of them. Namespaces the user may not read are
+// passed to skip and left out. Pods named explicitly which exist in none of
+// the namespaces are kept in every readable namespace, their logs may still be
+// stored.
+func GetNamespacesPodList(kubernetesOptions *client.KubernetesOptions, resources *Resources, namespaces []string, skip func(namespace string, err error)) ([]Pod, error) {
+
+	if len(namespaces) == 1 {
+		return GetResourcesPodList(kubernetesOptions, resources, namespaces[0])
+	}
+
+	var podList []Pod
+	seen := map[string]bool{}
+	found := map[Resource]bool{}
+	var readable []string
+
+	for _, namespace := range namespaces {
+		var namespacePods []Pod
+		var err error
+		if len(resources.Targets) == 0 {
+			if len(resources.Selector) == 0 && len(resources.FieldSelector) == 0 {
+				return nil, nil
+			}
+			namespacePods, err = GetSelectedPodsList(kubernetesOptions.Clientset, resources, nil, namespace)
+		}
+		for _, target := range resources.Targets {
+			var pods []Pod
+			pods, err = getNamespacePodsList(kubernetesOptions, resources, target, namespace)
+			if isNotFound(err) {
+				err = nil
+				continue
+			}
+			if err != nil {
+				break
+			}
+			found[target] = true
+			namespacePods = append(namespacePods, pods...)
+		}
+		if apierrors.IsForbidden(err) {
+			skip(namespace, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		readable = append(readable, namespace)
+		for _, pod := range namespacePods {
+			if !seen[pod.key()] {
+				seen[pod.key()] = true
+				podList = append(podList, pod)
+			}
+		}
+	}
+
+	if len(readable) == 0 {
+		return nil, fmt.Errorf("not allowed to read any of the %d selected namespaces", len(namespaces))
+	}
+	for _, target := range resources.Targets {
+		if found[target] {
+			continue
+		}
+		if target.Type == constants.Pod {
+			for _, namespace := range readable {
+				podList = append(podList, Pod{Name: target.Name, Namespace: namespace})
+			}
+			continue
+		}
+		return nil, fmt.Errorf("%s \"%s\" not found in any of the selected namespaces", target.Type, target.Name)
+	}
+	return podList, nil
+}
+
+// getNamespacePodsList resolves a resource in one of several namespaces. Unlike
+// GetPodsList, pods named explicitly are not found when they do not exist.
+func getNamespacePodsList(kubernetesOptions *client.KubernetesOptions, resources *Resources, target Resource, namespace string) ([]Pod, error) {
+
+	if target.Type != constants.Pod || len(resources.Selector) > 0 || len(resources.FieldSelector) > 0 {
+		return GetPodsList(kubernetesOptions, resources, target, namespace)
+	}
+	pod, err := kubernetesOptions.Clientset.CoreV1().Pods(namespace).Get(context.Background(), target.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return requestedPod(pod, resources)
+}
+
+// GetNamespacesPodMatchers builds the matchers of the resources in each of the
+// namespaces. Namespaces the user may not read and workloads which do not exist
+// in a namespace are left out.
+func GetNamespacesPodMatchers(kubernetesOptions *client.KubernetesOptions, resources *Resources, namespaces []string) ([]PodMatcher, error) {
+
+	if len(namespaces) == 1 {
+		return GetResourcesPodMatchers(kubernetesOptions, resources, namespaces[0])
+	}
+
+	var matchers []PodMatcher
+	for _, namespace := range namespaces {
+		for _, target := range resources.Targets {
+			targetResources := *resources
+			targetResources.Targets = []Resource{target}
+			targetMatchers, err := GetResourcesPodMatchers(kubernetesOptions, &targetResources, namespace)
+			if isNotFound(err) || apierrors.IsForbidden(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, targetMatchers...)
+		}
+		if len(resources.Targets) == 0 {
+			namespaceMatchers, err := GetResourcesPodMatchers(kubernetesOptions, resources, namespace)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, namespaceMatchers...)
+		}
+	}
+	return matchers, nil
+}
@@ -0,0 +1,259 @@
+package k8sresources
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResolveNamespaces(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Options    NamespaceOptions
+		Forbidden  bool
+		Namespaces []string
+		Error      error
+	}{
+		{
+			"Current namespace",
+			NamespaceOptions{},
+			false,
+			[]string{"openshift-logging"},
+			nil,
+		},
+		{
+			"Namespace list",
+			NamespaceOptions{Namespaces: []string{"team-b", "team-a", "team-b", ""}},
+			false,
+			[]string{"team-b", "team-a"},
+			nil,
+		},
+		{
+			"All namespaces",
+			NamespaceOptions{AllNamespaces: true},
+			false,
+			[]string{"openshift-logging", "team-a", "team-b"},
+			nil,
+		},
+		{
+			"Namespace selector",
+			NamespaceOptions{Selector: "team=payments"},
+			false,
+			[]string{"team-a", "team-b"},
+			nil,
+		},
+		{
+			"Namespace selector without match",
+			NamespaceOptions{Selector: "team=billing"},
+			false,
+			nil,
+			fmt.Errorf("no namespace matches the namespace selector \"team=billing\""),
+		},
+		{
+			"Invalid namespace selector",
+			NamespaceOptions{Selector: "team in (payments"},
+			false,
+			nil,
+			fmt.Errorf("an invalid namespace selector was entered: unable to parse requirement: found '', expected: ',' or ')'"),
+		},
+		{
+			"Projects of a user who may not list namespaces",
+			NamespaceOptions{AllNamespaces: true},
+			true,
+			[]string{"team-a"},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		clientset := fake.NewSimpleClientset(
+			namespaceObject("openshift-logging", nil),
+			namespaceObject("team-a", map[string]string{"team": "payments"}),
+			namespaceObject("team-b", map[string]string{"team": "payments"}),
+		)
+		if tt.Forbidden {
+			clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", fmt.Errorf("forbidden"))
+			})
+		}
+		project := &unstructured.Unstructured{}
+		project.SetAPIVersion("project.openshift.io/v1")
+		project.SetKind("Project")
+		project.SetName("team-a")
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{projectResource: "ProjectList"}, project)
+
+		kubernetesOptions := &client.KubernetesOptions{
+			Clientset:        clientset,
+			DynamicClient:    dynamicClient,
+			CurrentNamespace: "openshift-logging",
+		}
+		namespaces, err := ResolveNamespaces(kubernetesOptions, tt.Options, "openshift-logging")
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if strings.Join(namespaces, ",") != strings.Join(tt.Namespaces, ",") {
+			t.Errorf("Expected namespaces %v found %v", tt.Namespaces, namespaces)
+		}
+	}
+}
+
+func TestGetNamespacesPodList(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Resources  []Resource
+		Selector   string
+		Namespaces []string
+		PodList    []string
+		Skipped    []string
+		Error      error
+	}{
+		{
+			"Deployment in some namespaces",
+			[]Resource{{Type: "deployment", Name: "payments"}},
+			"",
+			[]string{"team-a", "team-b", "team-c"},
+			[]string{"team-a/payments-1", "team-b/payments-2"},
+			nil,
+			nil,
+		},
+		{
+			"Deployment in no namespace",
+			[]Resource{{Type: "deployment", Name: "billing"}},
+			"",
+			[]string{"team-a", "team-b"},
+			nil,
+			nil,
+			fmt.Errorf("deployment \"billing\" not found in any of the selected namespaces"),
+		},
+		{
+			"Pod which no longer exists",
+			[]Resource{{Type: "pod", Name: "payments-0"}},
+			"",
+			[]string{"team-a", "team-b"},
+			[]string{"team-a/payments-0", "team-b/payments-0"},
+			nil,
+			nil,
+		},
+		{
+			"Unit and deployment",
+			[]Resource{{Type: "unit", Name: "kubelet.service"}, {Type: "deployment", Name: "payments"}},
+			"",
+			[]string{"team-a", "team-b"},
+			[]string{"unit/kubelet.service", "team-a/payments-1", "team-b/payments-2"},
+			nil,
+			nil,
+		},
+		{
+			"Selector only",
+			nil,
+			"app=payments",
+			[]string{"team-a", "team-b"},
+			[]string{"team-a/payments-1", "team-b/payments-2"},
+			nil,
+			nil,
+		},
+		{
+			"Forbidden namespace skipped",
+			[]Resource{{Type: "deployment", Name: "payments"}},
+			"",
+			[]string{"team-a", "secret"},
+			[]string{"team-a/payments-1"},
+			[]string{"secret"},
+			nil,
+		},
+		{
+			"Every namespace forbidden",
+			[]Resource{{Type: "deployment", Name: "payments"}},
+			"",
+			[]string{"secret", "secret-2"},
+			nil,
+			[]string{"secret", "secret-2"},
+			fmt.Errorf("not allowed to read any of the 2 selected namespaces"),
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		deploymentObject("payments", "team-a"),
+		deploymentObject("payments", "team-b"),
+		podObject("payments-1", "team-a"),
+		podObject("payments-2", "team-b"),
+	)
+	clientset.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if !strings.HasPrefix(action.GetNamespace(), "secret") {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "payments", fmt.Errorf("forbidden"))
+	})
+	kubernetesOptions := &client.KubernetesOptions{Clientset: clientset}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		var skipped []string
+		resources := Resources{Targets: tt.Resources, Selector: tt.Selector}
+		podList, err := GetNamespacesPodList(kubernetesOptions, &resources, tt.Namespaces, func(namespace string, err error) {
+			skipped = append(skipped, namespace)
+		})
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		var pods []string
+		for _, pod := range podList {
+			pods = append(pods, pod.key())
+		}
+		if strings.Join(pods, ",") != strings.Join(tt.PodList, ",") {
+			t.Errorf("Expected list %v found %v", tt.PodList, pods)
+		}
+		if strings.Join(skipped, ",") != strings.Join(tt.Skipped, ",") {
+			t.Errorf("Expected skipped namespaces %v found %v", tt.Skipped, skipped)
+		}
+	}
+}
+
+func namespaceObject(name string, namespaceLabels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: namespaceLabels}}
+}
+
+func deploymentObject(name string, namespace string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+		},
+	}
+}
+
+func podObject(name string, namespace string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": "payments"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "payments"}}},
+	}
+}
@@ -0,0 +1,45 @@
+package k8sresources
+
+import (
+	"context"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// getNodePodsList lists the pods scheduled on a node with all their
+// containers, unless containers are selected by pattern. The node is checked
+// by ValidateNodes beforehand.
+func getNodePodsList(kubernetesOptions *client.KubernetesOptions, resources *Resources, targetNode string, namespace string) ([]Pod, error) {
+
+	if len(resources.Nodes) > 0 && !containsString(resources.Nodes, targetNode) {
+		return nil, nil
+	}
+
+	nodeResources := *resources
+	nodeResources.AllContainers = true
+	nodeResources.Nodes = []string{targetNode}
+	nodeSelector := fields.OneTermEqualSelector("spec.nodeName", targetNode).String()
+	if len(resources.FieldSelector) > 0 {
+		nodeResources.FieldSelector = resources.FieldSelector + "," + nodeSelector
+	} else {
+		nodeResources.FieldSelector = nodeSelector
+	}
+	return GetSelectedPodsList(kubernetesOptions.Clientset, &nodeResources, nil, namespace)
+}
+
+// ValidateNodes checks the nodes exist when the Nodes API can be read. Users
+// who may not read nodes can still name them, the logs of removed nodes may
+// still be stored.
+func ValidateNodes(kubernetesOptions *client.KubernetesOptions, nodes []string) error {
+
+	for _, node := range nodes {
+		_, err := kubernetesOptions.Clientset.CoreV1().Nodes().Get(context.Background(), node, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return notFoundError("node", node, "")
+		}
+	}
+	return nil
+}
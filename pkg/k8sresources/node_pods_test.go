@@ -0,0 +1,64 @@
+package k8sresources
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateNodes(t *testing.T) {
+	tests := []struct {
+		TestName  string
+		Nodes     []string
+		Forbidden bool
+		Error     error
+	}{
+		{
+			"Existing node",
+			[]string{"worker-0"},
+			false,
+			nil,
+		},
+		{
+			"Node doesn't exist",
+			[]string{"worker-0", "worker-9"},
+			false,
+			fmt.Errorf("node \"worker-9\" not found"),
+		},
+		{
+			"Nodes cannot be read",
+			[]string{"worker-9"},
+			true,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		clientset := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}})
+		if tt.Forbidden {
+			clientset.PrependReactor("get", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "worker-9", fmt.Errorf("forbidden"))
+			})
+		}
+		err := ValidateNodes(&client.KubernetesOptions{Clientset: clientset}, tt.Nodes)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// selectorFunc resolves a workload to the label selector matching its pods.
+type selectorFunc func(kubernetesOptions *client.KubernetesOptions, name string, namespace string) (labels.Selector, error)
+
+var selectorFuncs = map[string]selectorFunc{
+	constants.Deployment:            getDeploymentSelector,
+	constants.DaemonSet:             getDaemonSetSelector,
+	constants.StatefulSet:           getStatefulSetSelector,
+	constants.Job:                   getJobSelector,
+	constants.CronJob:               getCronJobSelector,
+	constants.ReplicaSet:            getReplicaSetSelector,
+	constants.ReplicationController: getReplicationControllerSelector,
+	constants.DeploymentConfig:      getDeploymentConfigSelector,
+	constants.Service:               getServiceSelector,
+	constants.BuildConfig:           getBuildConfigSelector,
+}
+
+// defaultContainerAnnotation selects the container used when no container is
+// requested explicitly, as in kubectl.
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// GetPodsList resolves one resource to its pods. Pods named explicitly are kept
+// even when they no longer exist, since their logs may still be stored.
+func GetPodsList(kubernetesOptions *client.KubernetesOptions, resources *Resources, target Resource, namespace string) ([]Pod, error) {
+
+	clientset := kubernetesOptions.Clientset
+
+	if target.Type == constants.Pod {
+		if len(resources.Selector) > 0 || len(resources.FieldSelector) > 0 {
+			return GetSelectedPodsList(clientset, resources, nil, namespace, target.Name)
+		}
+		pod, err := clientset.CoreV1().Pods(namespace).Get(context.Background(), target.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			// the logs of deleted pods are still stored
+			return []Pod{{Name: target.Name, Namespace: namespace}}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while fetching pod \"%v\": %w", target.Name, err)
+		}
+		return requestedPod(pod, resources)
+	}
+
+	if target.Type == constants.Node {
+		return getNodePodsList(kubernetesOptions, resources, target.Name, namespace)
+	}
+
+	if target.Type == constants.Unit {
+		// units run on the nodes, outside of any namespace
+		return []Pod{{Unit: target.Name}}, nil
+	}
+
+	getSelector, ok := selectorFuncs[target.Type]
+	if !ok {
+		return nil, fmt.Errorf("logs for invalid resource type \"%s\" requested", target.Type)
+	}
+	selector, err := getSelector(kubernetesOptions, target.Name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return GetSelectedPodsList(clientset, resources, selector, namespace)
+}
+
+// GetSelectedPodsList lists the pods matching both the workload selector and the
+// label and field selectors given on the command line. When names are given,
+// only pods with one of these names are kept, when nodes are given only pods
+// scheduled on one of them.
+func GetSelectedPodsList(clientset kubernetes.Interface, resources *Resources, selector labels.Selector, namespace string, names ...string) ([]Pod, error) {
+
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	if _, selectable := selector.Requirements(); !selectable {
+		return nil, nil
+	}
+	if len(resources.Selector) > 0 {
+		userSelector, err := labels.Parse(resources.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("an invalid label selector was entered: %v", err)
+		}
+		requirements, _ := userSelector.Requirements()
+		selector = selector.Add(requirements...)
+	}
+
+	options := metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: resources.FieldSelector,
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), options)
+	if errors.IsBadRequest(err) {
+		return nil, fmt.Errorf("an invalid field selector was entered: %v", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching pods: %w", err)
+	}
+
+	var podList []Pod
+	for index := range pods.Items {
+		if len(names) > 0 && !containsString(names, pods.Items[index].Name) {
+			continue
+		}
+		if len(resources.Nodes) > 0 && !containsString(resources.Nodes, pods.Items[index].Spec.NodeName) {
+			continue
+		}
+		if pod, ok := podContainers(&pods.Items[index], resources); ok {
+			podList = append(podList, pod)
+		}
+	}
+	return podList, nil
+}
+
+// podContainers selects the containers of a pod whose logs are requested. The
+// default container of multi-container pods is selected unless the logs of all
+// containers, which include init and ephemeral containers, or of containers
+// matching patterns are requested. Pods without a matching container are not
+// selected.
+func podContainers(pod *corev1.Pod, resources *Resources) (Pod, bool) {
+
+	result := Pod{Name: pod.Name, Namespace: pod.Namespace}
+	filtered := len(resources.Containers) > 0 || len(resources.ExcludeContainers) > 0
+	if !filtered && resources.AllContainers {
+		return result, true
+	}
+
+	var candidates []string
+	for _, container := range pod.Spec.Containers {
+		candidates = append(candidates, container.Name)
+	}
+	// init and ephemeral containers only log when requested explicitly
+	if resources.AllContainers || len(resources.Containers) > 0 {
+		for _, container := range pod.Spec.InitContainers {
+			candidates = append(candidates, container.Name)
+		}
+		for _, container := range pod.Spec.EphemeralContainers {
+			candidates = append(candidates, container.Name)
+		}
+	}
+
+	if !filtered {
+		if len(candidates) < 2 && len(pod.Spec.InitContainers) == 0 && len(pod.Spec.EphemeralContainers) == 0 {
+			return result, true
+		}
+		if container, ok := pod.Annotations[defaultContainerAnnotation]; ok {
+			result.Containers = []string{container}
+		} else if len(pod.Spec.Containers) > 0 {
+			result.Containers = []string{pod.Spec.Containers[0].Name}
+		}
+		return result, true
+	}
+
+	for _, container := range candidates {
+		if MatchesContainer(container, resources.Containers, resources.ExcludeContainers) {
+			result.Containers = append(result.Containers, container)
+		}
+	}
+	return result, len(result.Containers) > 0
+}
+
+// requestedPod selects the containers of a pod named on the command line,
+// which has to have a matching container. Pods on other nodes than the
+// requested ones are left out.
+func requestedPod(pod *corev1.Pod, resources *Resources) ([]Pod, error) {
+
+	if len(resources.Nodes) > 0 && !containsString(resources.Nodes, pod.Spec.NodeName) {
+		return nil, nil
+	}
+
+	result, ok := podContainers(pod, resources)
+	if !ok {
+		return nil, fmt.Errorf("no container of pod \"%s\" matches the requested containers", pod.Name)
+	}
+	return []Pod{result}, nil
+}
+
+// MatchesContainer reports whether a container name matches one of the
+// include patterns, or there are none, and none of the exclude patterns.
+// Patterns are validated by ValidateContainerPatterns.
+func MatchesContainer(container string, include []string, exclude []string) bool {
+
+	matches := len(include) == 0
+	for _, pattern := range include {
+		if matched, _ := path.Match(pattern, container); matched {
+			matches = true
+			break
+		}
+	}
+	for _, pattern := range exclude {
+		if matched, _ := path.Match(pattern, container); matched {
+			return false
+		}
+	}
+	return matches
+}
+
+// ValidateContainerPatterns checks the container patterns are valid shell
+// patterns.
+func ValidateContainerPatterns(patterns []string) error {
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid container pattern \"%s\": %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package k8sresources
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func podNames(podList []Pod) []string {
+	names := []string{}
+	for _, pod := range podList {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Argument   string
+		Resource   Resource
+		Error      error
+	}{
+		{"Type and name", false, "deployment/kibana", Resource{Type: constants.Deployment, Name: "kibana"}, nil},
+		{"Short type", false, "ds/fluentd", Resource{Type: constants.DaemonSet, Name: "fluentd"}, nil},
+		{"Plural type", false, "StatefulSets/prometheus", Resource{Type: constants.StatefulSet, Name: "prometheus"}, nil},
+		{"Legacy format", false, "podname=kibana-1234", Resource{Type: constants.Pod, Name: "kibana-1234"}, nil},
+		{"Unit", false, "unit/crio.service", Resource{Type: constants.Unit, Name: "crio.service"}, nil},
+		{"Unit without type", false, "units/kubelet", Resource{Type: constants.Unit, Name: "kubelet.service"}, nil},
+		{"Missing name", false, "deployment/", Resource{}, fmt.Errorf("invalid format \"deployment/\". [resource-type]/[resource-name] required as argument")},
+		{"Missing type", false, "kibana", Resource{}, fmt.Errorf("invalid format \"kibana\". [resource-type]/[resource-name] required as argument")},
+		{"Invalid type", false, "configmap/kibana", Resource{}, fmt.Errorf("logs for invalid resource type \"configmap\" requested")},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		resource, err := ParseResource(tt.Argument)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if resource != tt.Resource {
+			t.Errorf("Expected resource %v found %v", tt.Resource, resource)
+		}
+	}
+}
+
+func TestGetPodsList(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		ShouldFail    bool
+		Resource      Resource
+		FieldSelector string
+		AllContainers bool
+		Containers    []string
+		Exclude       []string
+		TestPods      []string
+		Error         error
+	}{
+		{
+			"Existing pod uses its default container",
+			false,
+			Resource{Type: constants.Pod, Name: "multi"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{"multi:app"},
+			nil,
+		},
+		{
+			"Existing pod with all containers",
+			false,
+			Resource{Type: constants.Pod, Name: "multi"},
+			"",
+			true,
+			nil,
+			nil,
+			[]string{"multi:"},
+			nil,
+		},
+		{
+			"Annotated default container",
+			false,
+			Resource{Type: constants.Pod, Name: "annotated"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{"annotated:sidecar"},
+			nil,
+		},
+		{
+			"Pod which no longer exists",
+			false,
+			Resource{Type: constants.Pod, Name: "deleted"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{"deleted:"},
+			nil,
+		},
+		{
+			"Pod which cannot be fetched",
+			false,
+			Resource{Type: constants.Pod, Name: "forbidden"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{},
+			fmt.Errorf("an error occurred while fetching pod \"forbidden\": pods \"forbidden\" is forbidden: User \"developer\" cannot get resource \"pods\""),
+		},
+		{
+			"Pod narrowed by field selector",
+			false,
+			Resource{Type: constants.Pod, Name: "multi"},
+			"metadata.name=multi",
+			true,
+			nil,
+			nil,
+			[]string{"multi:"},
+			nil,
+		},
+		{
+			"Init container in default selection",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{"initialized:app"},
+			nil,
+		},
+		{
+			"All containers including init and ephemeral containers",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			true,
+			nil,
+			nil,
+			[]string{"initialized:"},
+			nil,
+		},
+		{
+			"Containers matching a pattern",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			false,
+			[]string{"*-proxy", "setup"},
+			nil,
+			[]string{"initialized:istio-proxy,setup"},
+			nil,
+		},
+		{
+			"Excluded containers",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			false,
+			nil,
+			[]string{"istio-*"},
+			[]string{"initialized:app"},
+			nil,
+		},
+		{
+			"Ephemeral container",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			false,
+			[]string{"debugger"},
+			nil,
+			[]string{"initialized:debugger"},
+			nil,
+		},
+		{
+			"No matching container",
+			false,
+			Resource{Type: constants.Pod, Name: "initialized"},
+			"",
+			false,
+			[]string{"oauth-proxy"},
+			nil,
+			[]string{},
+			fmt.Errorf("no container of pod \"initialized\" matches the requested containers"),
+		},
+		{
+			"Every container of the pods of a node",
+			false,
+			Resource{Type: constants.Node, Name: "worker-0"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{"multi:"},
+			nil,
+		},
+		{
+			"Selected containers of the pods of a node",
+			false,
+			Resource{Type: constants.Node, Name: "worker-0"},
+			"",
+			false,
+			[]string{"side*"},
+			nil,
+			[]string{"multi:sidecar"},
+			nil,
+		},
+		{
+			"Invalid resource type",
+			false,
+			Resource{Type: "configmap", Name: "multi"},
+			"",
+			false,
+			nil,
+			nil,
+			[]string{},
+			fmt.Errorf("logs for invalid resource type \"configmap\" requested"),
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "multi", Namespace: "openshift-logging"},
+			Spec: corev1.PodSpec{
+				NodeName:   "worker-0",
+				Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "annotated",
+				Namespace:   "openshift-logging",
+				Annotations: map[string]string{"kubectl.kubernetes.io/default-container": "sidecar"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "initialized", Namespace: "openshift-logging"},
+			Spec: corev1.PodSpec{
+				InitContainers:      []corev1.Container{{Name: "setup"}},
+				Containers:          []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}},
+				EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"}}},
+			},
+		})
+
+	clientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.GetAction).GetName() != "forbidden" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "forbidden", fmt.Errorf("User \"developer\" cannot get resource \"pods\""))
+	})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		resources := &Resources{FieldSelector: tt.FieldSelector, AllContainers: tt.AllContainers, Containers: tt.Containers, ExcludeContainers: tt.Exclude}
+		podList, err := GetPodsList(&client.KubernetesOptions{Clientset: clientset}, resources, tt.Resource, "openshift-logging")
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		pods := []string{}
+		for _, pod := range podList {
+			pods = append(pods, pod.Name+":"+strings.Join(pod.Containers, ","))
+		}
+		if strings.Join(pods, " ") != strings.Join(tt.TestPods, " ") {
+			t.Errorf("Expected pods %v found %v", tt.TestPods, pods)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func getReplicaSetSelector(kubernetesOptions *client.KubernetesOptions, targetReplicaSet string, namespace string) (labels.Selector, error) {
+
+	replicaSet, err := kubernetesOptions.Clientset.AppsV1().ReplicaSets(namespace).Get(context.Background(), targetReplicaSet, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("replica set", targetReplicaSet, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching replica set \"%v\": %w", targetReplicaSet, err)
+	}
+
+	return metav1.LabelSelectorAsSelector(replicaSet.Spec.Selector)
+}
@@ -0,0 +1,23 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func getReplicationControllerSelector(kubernetesOptions *client.KubernetesOptions, targetReplicationController string, namespace string) (labels.Selector, error) {
+
+	replicationController, err := kubernetesOptions.Clientset.CoreV1().ReplicationControllers(namespace).Get(context.Background(), targetReplicationController, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("replication controller", targetReplicationController, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching replication controller \"%v\": %w", targetReplicationController, err)
+	}
+
+	return labels.SelectorFromSet(replicationController.Spec.Selector), nil
+}
@@ -1,9 +1,135 @@
 package k8sresources
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Resource is a single workload, pod, node or systemd unit named on the
+// command line.
+type Resource struct {
+	Type string
+	Name string
+}
+
 type Resources struct {
-	IsDeployment  bool
-	IsDaemonSet   bool
-	IsStatefulSet bool
-	IsPod         bool
-	Name          string
+	Targets       []Resource
+	Selector      string
+	FieldSelector string
+	AllContainers bool
+	// Containers and ExcludeContainers are shell patterns selecting the
+	// containers, init and ephemeral containers whose logs are requested.
+	Containers        []string
+	ExcludeContainers []string
+	// Nodes restricts the pods to those scheduled on these nodes.
+	Nodes []string
+}
+
+// Pod is a pod whose logs are requested. Containers lists the containers to
+// keep, an empty list keeps the logs of every container. When Unit is set the
+// journal logs of that systemd unit are requested instead, on every node.
+type Pod struct {
+	Name       string
+	Namespace  string
+	Containers []string
+	Unit       string
+}
+
+// key identifies the pod, or the unit, among the pods of several resources.
+func (p Pod) key() string {
+	if len(p.Unit) > 0 {
+		return constants.Unit + "/" + p.Unit
+	}
+	return p.Namespace + "/" + p.Name
+}
+
+var resourceAliases = map[string]string{
+	constants.Deployment:            constants.Deployment,
+	"deployments":                   constants.Deployment,
+	"deploy":                        constants.Deployment,
+	constants.DaemonSet:             constants.DaemonSet,
+	"daemonsets":                    constants.DaemonSet,
+	"ds":                            constants.DaemonSet,
+	constants.StatefulSet:           constants.StatefulSet,
+	"statefulsets":                  constants.StatefulSet,
+	"sts":                           constants.StatefulSet,
+	constants.Pod:                   constants.Pod,
+	"pods":                          constants.Pod,
+	"po":                            constants.Pod,
+	constants.Podname:               constants.Pod,
+	constants.Job:                   constants.Job,
+	"jobs":                          constants.Job,
+	constants.CronJob:               constants.CronJob,
+	"cronjobs":                      constants.CronJob,
+	"cj":                            constants.CronJob,
+	constants.ReplicaSet:            constants.ReplicaSet,
+	"replicasets":                   constants.ReplicaSet,
+	"rs":                            constants.ReplicaSet,
+	constants.ReplicationController: constants.ReplicationController,
+	"replicationcontrollers":        constants.ReplicationController,
+	"rc":                            constants.ReplicationController,
+	constants.DeploymentConfig:      constants.DeploymentConfig,
+	"deploymentconfigs":             constants.DeploymentConfig,
+	"dc":                            constants.DeploymentConfig,
+	constants.Service:               constants.Service,
+	"services":                      constants.Service,
+	"svc":                           constants.Service,
+	constants.BuildConfig:           constants.BuildConfig,
+	"buildconfigs":                  constants.BuildConfig,
+	"bc":                            constants.BuildConfig,
+	constants.Node:                  constants.Node,
+	"nodes":                         constants.Node,
+	"no":                            constants.Node,
+	constants.Unit:                  constants.Unit,
+	"units":                         constants.Unit,
+}
+
+// ParseResource parses a [resource-type]/[resource-name] argument. The older
+// [resource-type]=[resource-name] form is accepted as well.
+func ParseResource(argument string) (Resource, error) {
+
+	separator := "/"
+	if !strings.Contains(argument, separator) {
+		separator = "="
+	}
+	resourceTypeNameSplit := strings.Split(argument, separator)
+	if len(resourceTypeNameSplit) != 2 || len(resourceTypeNameSplit[1]) == 0 {
+		return Resource{}, fmt.Errorf("invalid format \"%s\". [resource-type]/[resource-name] required as argument", argument)
+	}
+
+	resourceType, ok := resourceAliases[strings.ToLower(resourceTypeNameSplit[0])]
+	if !ok {
+		return Resource{}, fmt.Errorf("logs for invalid resource type \"%s\" requested", resourceTypeNameSplit[0])
+	}
+	name := resourceTypeNameSplit[1]
+	if resourceType == constants.Unit && !strings.Contains(name, ".") {
+		// units without a type are services, as with systemctl
+		name += ".service"
+	}
+	return Resource{Type: resourceType, Name: name}, nil
+}
+
+// resourceNotFoundError reports a requested resource which does not exist.
+type resourceNotFoundError struct {
+	message string
+}
+
+func (e *resourceNotFoundError) Error() string {
+	return e.message
+}
+
+func notFoundError(kind string, name string, namespace string) error {
+	if len(namespace) > 0 {
+		return &resourceNotFoundError{message: fmt.Sprintf("%s \"%v\" not found in namespace \"%v\"", kind, name, namespace)}
+	}
+	return &resourceNotFoundError{message: fmt.Sprintf("%s \"%v\" not found", kind, name)}
+}
+
+func isNotFound(err error) bool {
+	var notFound *resourceNotFoundError
+	return errors.As(err, &notFound) || apierrors.IsNotFound(err)
 }
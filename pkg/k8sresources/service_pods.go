@@ -0,0 +1,26 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func getServiceSelector(kubernetesOptions *client.KubernetesOptions, targetService string, namespace string) (labels.Selector, error) {
+
+	service, err := kubernetesOptions.Clientset.CoreV1().Services(namespace).Get(context.Background(), targetService, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, notFoundError("service", targetService, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while fetching service \"%v\": %w", targetService, err)
+	}
+
+	if len(service.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("service \"%v\" has no pod selector", targetService)
+	}
+	return labels.SelectorFromSet(service.Spec.Selector), nil
+}
@@ -0,0 +1,98 @@
+package k8sresources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetServicePodsList(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		ShouldFail  bool
+		Service     string
+		Namespace   string
+		PodList     []string
+		TestPodList []string
+		Error       error
+	}{
+		{
+			"Service doesn't exist",
+			false,
+			"dummy-service",
+			"openshift-logging",
+			[]string{},
+			[]string{},
+			fmt.Errorf("service \"dummy-service\" not found in namespace \"openshift-logging\""),
+		},
+		{
+			"Service is present",
+			false,
+			"openshift-service",
+			"openshift-logging",
+			[]string{},
+			[]string{"openshift-service-pod"},
+			nil,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "openshift-service",
+				Namespace:   "openshift-logging",
+				Annotations: map[string]string{},
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"name": "logging"},
+			},
+		})
+
+	clientset.CoreV1().Pods("openshift-logging").Create(context.TODO(),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "openshift-service-pod",
+			Namespace:   "openshift-logging",
+			Annotations: map[string]string{},
+			Labels:      map[string]string{"name": "logging"},
+		},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "logging",
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+
+		podList, err := GetPodsList(&client.KubernetesOptions{Clientset: clientset}, &Resources{}, Resource{Type: constants.Service, Name: tt.Service}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+
+		if len(tt.PodList) != len(tt.TestPodList) {
+			t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+		} else {
+			for i, v := range tt.PodList {
+				if v != tt.TestPodList[i] {
+					t.Errorf("Expected list %v found %v", tt.TestPodList, tt.PodList)
+				}
+			}
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/client"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -74,7 +76,8 @@ func TestGetStatefulSetPodsList(t *testing.T) {
 	for _, tt := range tests {
 		t.Log("Running:", tt.TestName)
 
-		err := GetStatefulSetPodsList(clientset, &tt.PodList, tt.StatefulSet, tt.Namespace)
+		podList, err := GetPodsList(&client.KubernetesOptions{Clientset: clientset}, &Resources{}, Resource{Type: constants.StatefulSet, Name: tt.StatefulSet}, tt.Namespace)
+		tt.PodList = append(tt.PodList, podNames(podList)...)
 		if err == nil && tt.Error != nil {
 			t.Errorf("Expected error is %v, found %v", tt.Error, err)
 		}
@@ -0,0 +1,121 @@
+package logs
+
+import (
+	"strconv"
+	"time"
+)
+
+// AuditEvent is an audit log entry of the audit indices. API server audit
+// events follow the audit.k8s.io/v1 Event schema, node audit records of auditd
+// carry their raw record as Message and its type under AuditLinux instead.
+type AuditEvent struct {
+	ID     string `json:"_id,omitempty"`
+	Index  string `json:"_index,omitempty"`
+	Source struct {
+		Timestamp                time.Time            `json:"@timestamp"`
+		Kind                     string               `json:"kind,omitempty"`
+		APIVersion               string               `json:"apiVersion,omitempty"`
+		Level                    string               `json:"level,omitempty"`
+		AuditID                  string               `json:"auditID,omitempty"`
+		Stage                    string               `json:"stage,omitempty"`
+		RequestURI               string               `json:"requestURI,omitempty"`
+		Verb                     string               `json:"verb,omitempty"`
+		User                     *AuditUser           `json:"user,omitempty"`
+		ImpersonatedUser         *AuditUser           `json:"impersonatedUser,omitempty"`
+		SourceIPs                []string             `json:"sourceIPs,omitempty"`
+		UserAgent                string               `json:"userAgent,omitempty"`
+		ObjectRef                *AuditObjectRef      `json:"objectRef,omitempty"`
+		ResponseStatus           *AuditResponseStatus `json:"responseStatus,omitempty"`
+		RequestReceivedTimestamp *time.Time           `json:"requestReceivedTimestamp,omitempty"`
+		StageTimestamp           *time.Time           `json:"stageTimestamp,omitempty"`
+		Annotations              map[string]string    `json:"annotations,omitempty"`
+		Hostname                 string               `json:"hostname,omitempty"`
+		Message                  string               `json:"message,omitempty"`
+		AuditLinux               *struct {
+			Type     string `json:"type,omitempty"`
+			RecordID string `json:"record_id,omitempty"`
+		} `json:"audit.linux,omitempty"`
+		K8sAuditLevel       string `json:"k8s_audit_level,omitempty"`
+		OpenshiftAuditLevel string `json:"openshift_audit_level,omitempty"`
+		ViaqMsgID           string `json:"viaq_msg_id,omitempty"`
+	} `json:"_source"`
+}
+
+// AuditUser is the user who sent an audited request.
+type AuditUser struct {
+	Username string              `json:"username,omitempty"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// AuditObjectRef is the object an audited request was sent for.
+type AuditObjectRef struct {
+	Resource        string `json:"resource,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name,omitempty"`
+	UID             string `json:"uid,omitempty"`
+	APIGroup        string `json:"apiGroup,omitempty"`
+	APIVersion      string `json:"apiVersion,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Subresource     string `json:"subresource,omitempty"`
+}
+
+// AuditResponseStatus is the status an audited request was answered with.
+type AuditResponseStatus struct {
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Code    int    `json:"code,omitempty"`
+}
+
+// Username returns the user who sent the request, or an empty string for node
+// audit records.
+func (e AuditEvent) Username() string {
+	if e.Source.User == nil {
+		return ""
+	}
+	return e.Source.User.Username
+}
+
+// Resource returns the resource of the object the request was sent for, with
+// its API group and subresource, e.g. deployments.apps/scale.
+func (e AuditEvent) Resource() string {
+	objectRef := e.Source.ObjectRef
+	if objectRef == nil {
+		return ""
+	}
+	resource := objectRef.Resource
+	if len(objectRef.APIGroup) > 0 {
+		resource += "." + objectRef.APIGroup
+	}
+	if len(objectRef.Subresource) > 0 {
+		resource += "/" + objectRef.Subresource
+	}
+	return resource
+}
+
+// Namespace returns the namespace of the object the request was sent for.
+func (e AuditEvent) Namespace() string {
+	if e.Source.ObjectRef == nil {
+		return ""
+	}
+	return e.Source.ObjectRef.Namespace
+}
+
+// Name returns the name of the object the request was sent for.
+func (e AuditEvent) Name() string {
+	if e.Source.ObjectRef == nil {
+		return ""
+	}
+	return e.Source.ObjectRef.Name
+}
+
+// Code returns the HTTP status code the request was answered with, or an
+// empty string when the event has no response.
+func (e AuditEvent) Code() string {
+	if e.Source.ResponseStatus == nil || e.Source.ResponseStatus.Code == 0 {
+		return ""
+	}
+	return strconv.Itoa(e.Source.ResponseStatus.Code)
+}
@@ -0,0 +1,28 @@
+package logs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAuditEvent(t *testing.T) {
+	document := `{"_index":"audit-000001","_source":{"@timestamp":"2021-03-18T06:41:17Z","verb":"patch","user":{"username":"system:admin"},"objectRef":{"resource":"deployments","apiGroup":"apps","subresource":"scale","namespace":"project-a","name":"web"},"responseStatus":{"code":200}}}`
+
+	event := AuditEvent{}
+	err := json.Unmarshal([]byte(document), &event)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if event.Username() != "system:admin" || event.Resource() != "deployments.apps/scale" || event.Namespace() != "project-a" || event.Name() != "web" || event.Code() != "200" {
+		t.Errorf("Expected the patch of deployment web, found %+v", event.Source)
+	}
+
+	nodeRecord := AuditEvent{}
+	err = json.Unmarshal([]byte(`{"_source":{"audit.linux":{"type":"SYSCALL","record_id":"12"},"message":"arch=c000003e","hostname":"master-0"}}`), &nodeRecord)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if nodeRecord.Username() != "" || nodeRecord.Resource() != "" || nodeRecord.Code() != "" || nodeRecord.Source.AuditLinux.Type != "SYSCALL" {
+		t.Errorf("Expected a node audit record without object, found %+v", nodeRecord.Source)
+	}
+}
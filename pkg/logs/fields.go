@@ -0,0 +1,319 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fieldFilterPattern matches a field filter like kubernetes.labels.app=web, the
+// operator is one of =, ==, !=, =~ and !~.
+var fieldFilterPattern = regexp.MustCompile(`^\s*([^=!~\s]+)\s*(=~|!~|!=|==|=)(.*)$`)
+
+// UnmarshalJSON reads the typed fields of a log document and keeps its source
+// document as Raw.
+func (l *LogOptions) UnmarshalJSON(data []byte) error {
+
+	type document LogOptions
+	typed := document{}
+	err := json.Unmarshal(data, &typed)
+	if err != nil {
+		return err
+	}
+
+	raw := struct {
+		Source map[string]interface{} `json:"_source"`
+	}{}
+	err = decodeJSON(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	*l = LogOptions(typed)
+	l.Raw = raw.Source
+	l.cache = &documentCache{}
+	return nil
+}
+
+// ParseSource reads a log from its source document alone, like the records
+// forwarded to stores without document metadata.
+func ParseSource(data []byte) (LogOptions, error) {
+
+	log := LogOptions{}
+	err := json.Unmarshal(data, &log.Source)
+	if err != nil {
+		return LogOptions{}, err
+	}
+	err = decodeJSON(data, &log.Raw)
+	if err != nil {
+		return LogOptions{}, err
+	}
+	log.cache = &documentCache{}
+	return log, nil
+}
+
+// MarshalJSON writes the log document with the source document returned by
+// Document, so that fields the typed source does not model are kept.
+func (l LogOptions) MarshalJSON() ([]byte, error) {
+
+	type document LogOptions
+	if l.Raw == nil {
+		return json.Marshal(document(l))
+	}
+	source, err := l.Document()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		document
+		Source map[string]interface{} `json:"_source"`
+	}{document(l), source})
+}
+
+// Document returns the source document of a log, the raw document read from
+// the log store with the typed fields laid over it. Typed fields which are
+// empty do not hide the raw ones.
+func (l LogOptions) Document() (map[string]interface{}, error) {
+
+	data, err := json.Marshal(l.Source)
+	if err != nil {
+		return nil, err
+	}
+	typed := map[string]interface{}{}
+	err = decodeJSON(data, &typed)
+	if err != nil {
+		return nil, err
+	}
+	return mergeDocuments(l.Raw, typed), nil
+}
+
+// Field returns the value of a field of the source document by its dotted
+// path, e.g. kubernetes.labels.app. A _source. prefix is ignored, _id, _index
+// and _type address the metadata of the document. Keys containing dots, like
+// the label app.kubernetes.io/name, are matched as a whole, longest first.
+func (l LogOptions) Field(path string) (interface{}, bool) {
+
+	switch path {
+	case "_id":
+		return l.ID, true
+	case "_index":
+		return l.Index, true
+	case "_type":
+		return l.Type, true
+	}
+
+	document, err := l.cachedDocument()
+	if err != nil {
+		return nil, false
+	}
+	return lookupField(document, strings.Split(strings.TrimPrefix(path, "_source."), "."))
+}
+
+// cachedDocument returns the source document of a log, decoded once for the
+// logs read from a document as long as their fields do not change. Logs built
+// otherwise have no cache to keep it in. Comparing the fields is cheap next to
+// decoding them as maps compare by their address first, which also means that
+// a map has to be replaced rather than changed in place for the change to show.
+func (l LogOptions) cachedDocument() (map[string]interface{}, error) {
+
+	if l.cache == nil {
+		return l.Document()
+	}
+	l.cache.lock.Lock()
+	defer l.cache.lock.Unlock()
+	if !l.cache.built || !reflect.DeepEqual(l.cache.source, l.Source) || !reflect.DeepEqual(l.cache.raw, l.Raw) {
+		l.cache.document, l.cache.err = l.Document()
+		l.cache.source, l.cache.raw, l.cache.built = l.Source, l.Raw, true
+	}
+	return l.cache.document, l.cache.err
+}
+
+// FieldString returns the value of a field formatted by FormatField, or an
+// empty string when the log has no such field.
+func (l LogOptions) FieldString(path string) string {
+	value, ok := l.Field(path)
+	if !ok {
+		return ""
+	}
+	return FormatField(value)
+}
+
+// Labels returns the pod labels recorded with a log, or nil when the
+// collector did not record any. Collectors which flatten the labels record
+// them as key=value strings.
+func (l LogOptions) Labels() map[string]string {
+
+	kubernetes := l.Source.Kubernetes
+	if len(kubernetes.Labels) > 0 {
+		return kubernetes.Labels
+	}
+	if len(kubernetes.FlatLabels) == 0 {
+		return nil
+	}
+
+	podLabels := map[string]string{}
+	for _, label := range kubernetes.FlatLabels {
+		key, value := label, ""
+		if index := strings.Index(label, "="); index >= 0 {
+			key, value = label[:index], label[index+1:]
+		}
+		podLabels[key] = value
+	}
+	return podLabels
+}
+
+// FormatField formats the value of a field as text. Strings, numbers and
+// booleans are printed as they are, objects and lists as compact JSON.
+func FormatField(value interface{}) string {
+
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case json.Number:
+		return typed.String()
+	case bool:
+		return strconv.FormatBool(typed)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// FieldFilter selects logs by the value of a field of their document.
+type FieldFilter struct {
+	Path     string
+	Operator string
+	Value    string
+
+	expression *regexp.Regexp
+}
+
+// ParseFieldFilter parses a filter like kubernetes.labels.app=web. The
+// operators = and == select equal values, != other values, =~ values matching
+// a regular expression (RE2 syntax) and !~ values not matching it.
+func ParseFieldFilter(filter string) (FieldFilter, error) {
+
+	match := fieldFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return FieldFilter{}, fmt.Errorf("invalid field filter \"%s\", a filter like kubernetes.labels.app=web or structured.status=~^5 is required", filter)
+	}
+
+	fieldFilter := FieldFilter{Path: match[1], Operator: match[2], Value: match[3]}
+	if fieldFilter.Operator == "==" {
+		fieldFilter.Operator = "="
+	}
+	if fieldFilter.Operator == "=~" || fieldFilter.Operator == "!~" {
+		expression, err := regexp.Compile(fieldFilter.Value)
+		if err != nil {
+			return FieldFilter{}, fmt.Errorf("invalid field filter \"%s\": %v", filter, err)
+		}
+		fieldFilter.expression = expression
+	}
+	return fieldFilter, nil
+}
+
+// Matches reports whether the field of a log passes the filter. A list passes
+// = and =~ when one of its items does, and != and !~ when none of them fails
+// the negated filter. Logs without the field only pass != and !~.
+func (f FieldFilter) Matches(log LogOptions) bool {
+
+	value, ok := log.Field(f.Path)
+	negated := f.Operator == "!=" || f.Operator == "!~"
+	if !ok {
+		return negated
+	}
+
+	values := []interface{}{value}
+	if items, ok := value.([]interface{}); ok {
+		values = items
+	}
+	for _, item := range values {
+		text := FormatField(item)
+		var matches bool
+		if f.expression != nil {
+			matches = f.expression.MatchString(text)
+		} else {
+			matches = text == f.Value
+		}
+		if matches {
+			return !negated
+		}
+	}
+	return negated
+}
+
+func (f FieldFilter) String() string {
+	return f.Path + f.Operator + f.Value
+}
+
+// lookupField returns the value of the path in a document.
+func lookupField(value interface{}, segments []string) (interface{}, bool) {
+
+	if len(segments) == 0 {
+		return value, true
+	}
+	document, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for end := len(segments); end > 0; end-- {
+		child, ok := document[strings.Join(segments[:end], ".")]
+		if !ok {
+			continue
+		}
+		if found, ok := lookupField(child, segments[end:]); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+// mergeDocuments returns the raw document with the typed one laid over it.
+// Objects are merged, empty typed values keep the raw ones.
+func mergeDocuments(raw map[string]interface{}, typed map[string]interface{}) map[string]interface{} {
+
+	merged := make(map[string]interface{}, len(raw)+len(typed))
+	for key, value := range raw {
+		merged[key] = value
+	}
+	for key, value := range typed {
+		rawValue, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+		typedObject, typedIsObject := value.(map[string]interface{})
+		rawObject, rawIsObject := rawValue.(map[string]interface{})
+		if typedIsObject && rawIsObject {
+			merged[key] = mergeDocuments(rawObject, typedObject)
+			continue
+		}
+		if value == nil || value == "" {
+			continue
+		}
+		if _, rawIsNumber := rawValue.(json.Number); rawIsNumber {
+			// the typed number went through a float64
+			if _, typedIsNumber := value.(json.Number); typedIsNumber {
+				continue
+			}
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// decodeJSON unmarshals a document keeping numbers as json.Number, so that
+// large integers are printed as they were stored.
+func decodeJSON(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
@@ -0,0 +1,161 @@
+package logs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testFieldsLog = `{"_index":"app-000001","_id":"1","_source":{"kubernetes":{"pod_name":"web-1","labels":{"app":"web","app.kubernetes.io/version":"1.4"},"namespace_labels":{"team":"payments"}},"structured":{"status":503,"bytes":1048576,"tags":["retry","upstream"]},"trace_id":"4bf92f35","level":"","message":"upstream unavailable"}}`
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Path     string
+		Found    bool
+		Expected string
+	}{
+		{"Typed field", "kubernetes.pod_name", true, "web-1"},
+		{"Label", "kubernetes.labels.app", true, "web"},
+		{"Label with dots", "kubernetes.labels.app.kubernetes.io/version", true, "1.4"},
+		{"Namespace label", "kubernetes.namespace_labels.team", true, "payments"},
+		{"Unknown field", "_source.trace_id", true, "4bf92f35"},
+		{"Large number", "structured.bytes", true, "1048576"},
+		{"List", "structured.tags", true, `["retry","upstream"]`},
+		{"Metadata", "_index", true, "app-000001"},
+		{"Missing field", "kubernetes.labels.tier", false, ""},
+	}
+
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(testFieldsLog), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		value, found := log.Field(tt.Path)
+		if found != tt.Found || FormatField(value) != tt.Expected {
+			t.Errorf("Expected field %s to be %q (%v), found %q (%v)", tt.Path, tt.Expected, tt.Found, FormatField(value), found)
+		}
+	}
+}
+
+func TestFieldOfParsedLog(t *testing.T) {
+
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(`{"_id":"1","_source":{"message":"level=error status=503"}}`), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if _, found := log.Field("structured.status"); found {
+		t.Errorf("Expected field %s to be missing before parsing", "structured.status")
+	}
+
+	parser, err := NewMessageParser(ParseLogfmt, "")
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	parsed := parser.Parse(log)
+	if value := parsed.FieldString("structured.status"); value != "503" {
+		t.Errorf("Expected field %s to be %q, found %q", "structured.status", "503", value)
+	}
+	if _, found := log.Field("structured.status"); found {
+		t.Errorf("Expected field %s to be missing from the log which was not parsed", "structured.status")
+	}
+}
+
+func TestFieldOfChangedCopy(t *testing.T) {
+
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(testFieldsLog), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if value := log.FieldString("kubernetes.pod_name"); value != "web-1" {
+		t.Errorf("Expected field %s to be %q, found %q", "kubernetes.pod_name", "web-1", value)
+	}
+
+	changed := log
+	changed.Source.Kubernetes.PodName = "web-2"
+	changed.Source.Level = "error"
+	if value := changed.FieldString("kubernetes.pod_name"); value != "web-2" {
+		t.Errorf("Expected field %s to be %q, found %q", "kubernetes.pod_name", "web-2", value)
+	}
+	if value := changed.FieldString("level"); value != "error" {
+		t.Errorf("Expected field %s to be %q, found %q", "level", "error", value)
+	}
+	if value := log.FieldString("kubernetes.pod_name"); value != "web-1" {
+		t.Errorf("Expected field %s of the original log to be %q, found %q", "kubernetes.pod_name", "web-1", value)
+	}
+}
+
+func TestFieldFilter(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Filter   string
+		Matches  bool
+	}{
+		{"Equal", "kubernetes.labels.app=web", true},
+		{"Double equal", "kubernetes.labels.app==web", true},
+		{"Not equal", "kubernetes.labels.app!=api", true},
+		{"Regular expression", "structured.status=~^5", true},
+		{"Negated regular expression", "structured.status!~^5", false},
+		{"List item", "structured.tags=retry", true},
+		{"Missing field", "kubernetes.labels.tier=web", false},
+		{"Negated missing field", "kubernetes.labels.tier!=web", true},
+	}
+
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(testFieldsLog), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		filter, err := ParseFieldFilter(tt.Filter)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+			continue
+		}
+		if filter.Matches(log) != tt.Matches {
+			t.Errorf("Expected filter %s to match %v", tt.Filter, tt.Matches)
+		}
+	}
+
+	for _, filter := range []string{"kubernetes.labels.app", "=web", "message=~("} {
+		_, err := ParseFieldFilter(filter)
+		if err == nil {
+			t.Errorf("Expected an error for filter %s", filter)
+		}
+	}
+}
+
+func TestDocumentKeepsUnknownFields(t *testing.T) {
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(testFieldsLog), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	// backends fill in typed fields missing from the document
+	log.Source.Level = "error"
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	for _, expected := range []string{`"trace_id":"4bf92f35"`, `"bytes":1048576`, `"app.kubernetes.io/version":"1.4"`, `"level":"error"`, `"_id":"1"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %s in %s", expected, data)
+		}
+	}
+
+	sourceLog, err := ParseSource([]byte(`{"message":"GET /","kubernetes":{"labels":{"app":"web"}},"http":{"method":"GET"}}`))
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if sourceLog.Source.Message != "GET /" || sourceLog.FieldString("http.method") != "GET" || sourceLog.Labels()["app"] != "web" {
+		t.Errorf("Expected the fields of the source document, found %+v", sourceLog)
+	}
+}
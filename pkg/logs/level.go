@@ -0,0 +1,139 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Levels of the ViaQ data model, the most severe first.
+const (
+	LevelEmerg   = "emerg"
+	LevelAlert   = "alert"
+	LevelCrit    = "crit"
+	LevelErr     = "err"
+	LevelWarning = "warning"
+	LevelNotice  = "notice"
+	LevelInfo    = "info"
+	LevelDebug   = "debug"
+	LevelTrace   = "trace"
+	LevelUnknown = "unknown"
+)
+
+// severities orders the levels which have a severity. Unknown has none and is
+// only selected by name.
+var severities = []string{LevelEmerg, LevelAlert, LevelCrit, LevelErr, LevelWarning, LevelNotice, LevelInfo, LevelDebug, LevelTrace}
+
+// levelAliases lists the values collectors and applications use for a level.
+var levelAliases = map[string][]string{
+	LevelEmerg:   {"emerg", "emergency", "panic"},
+	LevelAlert:   {"alert"},
+	LevelCrit:    {"crit", "critical", "fatal"},
+	LevelErr:     {"err", "error"},
+	LevelWarning: {"warning", "warn"},
+	LevelNotice:  {"notice"},
+	LevelInfo:    {"info", "information", "informational"},
+	LevelDebug:   {"debug"},
+	LevelTrace:   {"trace"},
+	LevelUnknown: {"unknown"},
+}
+
+// Levels lists the levels, the most severe first.
+func Levels() []string {
+	return append(append([]string{}, severities...), LevelUnknown)
+}
+
+// NormalizeLevel maps a level value to its ViaQ name, ignoring case. Values
+// which are not a known level are unknown.
+func NormalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for name, aliases := range levelAliases {
+		for _, alias := range aliases {
+			if level == alias {
+				return name
+			}
+		}
+	}
+	return LevelUnknown
+}
+
+// LevelAliases returns the values stored for a level, its ViaQ name first.
+func LevelAliases(level string) []string {
+	return levelAliases[level]
+}
+
+// ParseLevels parses a level filter into the set of levels it selects, the
+// most severe first. The filter is either a comma separated list of levels, or
+// a threshold: >=warning selects warning and every more severe level, <info
+// selects every less severe level than info.
+func ParseLevels(filter string) ([]string, error) {
+
+	filter = strings.TrimSpace(filter)
+	if len(filter) == 0 {
+		return nil, nil
+	}
+
+	for _, operator := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(filter, operator) {
+			continue
+		}
+		threshold, err := parseLevel(filter[len(operator):])
+		if err != nil {
+			return nil, err
+		}
+		index := severity(threshold)
+		if index < 0 {
+			return nil, fmt.Errorf("level \"%s\" has no severity and cannot be used as a threshold", threshold)
+		}
+
+		var levels []string
+		for levelIndex, level := range severities {
+			switch {
+			case operator == ">=" && levelIndex <= index,
+				operator == ">" && levelIndex < index,
+				operator == "<=" && levelIndex >= index,
+				operator == "<" && levelIndex > index:
+				levels = append(levels, level)
+			}
+		}
+		if len(levels) == 0 {
+			return nil, fmt.Errorf("no level is %s %s", operator, threshold)
+		}
+		return levels, nil
+	}
+
+	selected := map[string]bool{}
+	for _, value := range strings.Split(filter, ",") {
+		level, err := parseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		selected[level] = true
+	}
+
+	var levels []string
+	for _, level := range Levels() {
+		if selected[level] {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+// parseLevel normalizes a level of a filter, which unlike a stored value must
+// be known.
+func parseLevel(value string) (string, error) {
+	level := NormalizeLevel(value)
+	if level == LevelUnknown && !strings.EqualFold(strings.TrimSpace(value), LevelUnknown) {
+		return "", fmt.Errorf("unknown level \"%s\", one of %s is required", strings.TrimSpace(value), strings.Join(Levels(), ","))
+	}
+	return level, nil
+}
+
+func severity(level string) int {
+	for index, name := range severities {
+		if name == level {
+			return index
+		}
+	}
+	return -1
+}
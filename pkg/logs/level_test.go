@@ -0,0 +1,114 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLevel(t *testing.T) {
+	tests := map[string]string{
+		"Info":      LevelInfo,
+		"WARN":      LevelWarning,
+		"error":     LevelErr,
+		"err":       LevelErr,
+		" Critical": LevelCrit,
+		"fatal":     LevelCrit,
+		"panic":     LevelEmerg,
+		"":          LevelUnknown,
+		"verbose":   LevelUnknown,
+	}
+
+	for value, expected := range tests {
+		if level := NormalizeLevel(value); level != expected {
+			t.Errorf("Expected level %s for %q found %s", expected, value, level)
+		}
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Filter   string
+		Levels   []string
+		Error    error
+	}{
+		{
+			"No filter",
+			"",
+			nil,
+			nil,
+		},
+		{
+			"Single level with mixed case",
+			"Info",
+			[]string{"info"},
+			nil,
+		},
+		{
+			"List of levels",
+			"error,critical, Unknown",
+			[]string{"crit", "err", "unknown"},
+			nil,
+		},
+		{
+			"At least as severe",
+			">=warning",
+			[]string{"emerg", "alert", "crit", "err", "warning"},
+			nil,
+		},
+		{
+			"More severe",
+			">err",
+			[]string{"emerg", "alert", "crit"},
+			nil,
+		},
+		{
+			"At most as severe",
+			"<=debug",
+			[]string{"debug", "trace"},
+			nil,
+		},
+		{
+			"Less severe",
+			"<info",
+			[]string{"debug", "trace"},
+			nil,
+		},
+		{
+			"Threshold without levels",
+			">emerg",
+			nil,
+			fmt.Errorf("no level is > emerg"),
+		},
+		{
+			"Unknown threshold",
+			">=unknown",
+			nil,
+			fmt.Errorf("level \"unknown\" has no severity and cannot be used as a threshold"),
+		},
+		{
+			"Invalid level",
+			"info,verbose",
+			nil,
+			fmt.Errorf("unknown level \"verbose\", one of emerg,alert,crit,err,warning,notice,info,debug,trace,unknown is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		levels, err := ParseLevels(tt.Filter)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if strings.Join(levels, ",") != strings.Join(tt.Levels, ",") {
+			t.Errorf("Expected levels %v found %v", tt.Levels, levels)
+		}
+	}
+}
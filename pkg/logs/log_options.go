@@ -1,6 +1,9 @@
 package logs
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type LogOptions struct {
 	ID     string  `json:"_id"`
@@ -13,20 +16,19 @@ type LogOptions struct {
 		} `json:"docker"`
 		Hostname   string `json:"hostname"`
 		Kubernetes struct {
-			ContainerImage   string   `json:"container_image"`
-			ContainerImageID string   `json:"container_image_id"`
-			ContainerName    string   `json:"container_name"`
-			FlatLabels       []string `json:"flat_labels"`
-			Host             string   `json:"host"`
-			MasterURL        string   `json:"master_url"`
-			NamespaceID      string   `json:"namespace_id"`
-			NamespaceLabels  struct {
-				OpenshiftIoClusterMonitoring string `json:"openshift_io/cluster-monitoring"`
-				OpenshiftIoRunLevel          string `json:"openshift_io/run-level"`
-			} `json:"namespace_labels"`
-			NamespaceName string `json:"namespace_name"`
-			PodID         string `json:"pod_id"`
-			PodName       string `json:"pod_name"`
+			Annotations      map[string]string `json:"annotations,omitempty"`
+			ContainerImage   string            `json:"container_image"`
+			ContainerImageID string            `json:"container_image_id"`
+			ContainerName    string            `json:"container_name"`
+			FlatLabels       []string          `json:"flat_labels"`
+			Host             string            `json:"host"`
+			Labels           map[string]string `json:"labels,omitempty"`
+			MasterURL        string            `json:"master_url"`
+			NamespaceID      string            `json:"namespace_id"`
+			NamespaceLabels  map[string]string `json:"namespace_labels,omitempty"`
+			NamespaceName    string            `json:"namespace_name"`
+			PodID            string            `json:"pod_id"`
+			PodName          string            `json:"pod_name"`
 		} `json:"kubernetes"`
 		Level            string `json:"level"`
 		Message          string `json:"message"`
@@ -40,6 +42,79 @@ type LogOptions struct {
 			} `json:"collector"`
 		} `json:"pipeline_metadata"`
 		ViaqMsgID string `json:"viaq_msg_id"`
+
+		// Systemd holds the journal metadata of node logs, it is nil for
+		// container logs.
+		Systemd *Systemd `json:"systemd,omitempty"`
+
+		// Openshift holds the metadata added by the log forwarder, it is nil
+		// when the forwarder added none.
+		Openshift *Openshift `json:"openshift,omitempty"`
+		// Structured is the message of a JSON log parsed by the collector.
+		Structured map[string]interface{} `json:"structured,omitempty"`
 	} `json:"_source"`
 	Type string `json:"_type"`
+
+	// Raw is the source document as it was read from the log store, with
+	// every field the typed source does not model. It is nil for logs which
+	// were not read from a document.
+	Raw map[string]interface{} `json:"-"`
+
+	// cache holds the source document Field looks fields up in. It is shared
+	// by the copies of a log and built again when the lookup is made on a log
+	// whose source differs from the one it was built from.
+	cache *documentCache
+}
+
+// documentCache holds the source document of a log together with the typed
+// source and the raw document it was built from.
+type documentCache struct {
+	lock     sync.Mutex
+	built    bool
+	source   interface{}
+	raw      map[string]interface{}
+	document map[string]interface{}
+	err      error
+}
+
+// Openshift holds the labels of the log forwarder pipeline which forwarded a
+// log.
+type Openshift struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Systemd holds the journal fields of a node log, the trusted ones set by
+// journald under T and those set by the logging process under U.
+type Systemd struct {
+	T struct {
+		BootID              string `json:"BOOT_ID,omitempty"`
+		CapEffective        string `json:"CAP_EFFECTIVE,omitempty"`
+		Cmdline             string `json:"CMDLINE,omitempty"`
+		Comm                string `json:"COMM,omitempty"`
+		Exe                 string `json:"EXE,omitempty"`
+		GID                 string `json:"GID,omitempty"`
+		MachineID           string `json:"MACHINE_ID,omitempty"`
+		PID                 string `json:"PID,omitempty"`
+		SelinuxContext      string `json:"SELINUX_CONTEXT,omitempty"`
+		StreamID            string `json:"STREAM_ID,omitempty"`
+		SystemdCgroup       string `json:"SYSTEMD_CGROUP,omitempty"`
+		SystemdInvocationID string `json:"SYSTEMD_INVOCATION_ID,omitempty"`
+		SystemdSlice        string `json:"SYSTEMD_SLICE,omitempty"`
+		SystemdUnit         string `json:"SYSTEMD_UNIT,omitempty"`
+		Transport           string `json:"TRANSPORT,omitempty"`
+		UID                 string `json:"UID,omitempty"`
+	} `json:"t"`
+	U struct {
+		SyslogFacility   string `json:"SYSLOG_FACILITY,omitempty"`
+		SyslogIdentifier string `json:"SYSLOG_IDENTIFIER,omitempty"`
+	} `json:"u"`
+}
+
+// Unit returns the systemd unit which wrote a node log, or an empty string
+// for container logs.
+func (l LogOptions) Unit() string {
+	if l.Source.Systemd == nil {
+		return ""
+	}
+	return l.Source.Systemd.T.SystemdUnit
 }
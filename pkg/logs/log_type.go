@@ -0,0 +1,54 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Log types of the OpenShift Logging data model. Each of them is stored in
+// indices, or a LokiStack tenant, of its own.
+const (
+	LogTypeApplication    = "application"
+	LogTypeInfrastructure = "infrastructure"
+	LogTypeAudit          = "audit"
+)
+
+// logTypeIndexPrefixes are the prefixes of the indices the collector writes
+// the logs of a type to.
+var logTypeIndexPrefixes = map[string]string{
+	LogTypeApplication:    "app-",
+	LogTypeInfrastructure: "infra-",
+	LogTypeAudit:          "audit-",
+}
+
+// LogTypes lists the log types.
+func LogTypes() []string {
+	return []string{LogTypeApplication, LogTypeInfrastructure, LogTypeAudit}
+}
+
+// ParseLogType validates a log type, ignoring case. An empty value selects
+// logs of every type.
+func ParseLogType(logType string) (string, error) {
+	logType = strings.ToLower(strings.TrimSpace(logType))
+	if _, ok := logTypeIndexPrefixes[logType]; ok || len(logType) == 0 {
+		return logType, nil
+	}
+	return "", fmt.Errorf("unknown log type \"%s\", one of %s is required", logType, strings.Join(LogTypes(), ","))
+}
+
+// LogTypeIndexPrefix returns the prefix of the indices logs of a type are
+// stored in.
+func LogTypeIndexPrefix(logType string) string {
+	return logTypeIndexPrefixes[logType]
+}
+
+// LogType returns the type of a log from the index it was read from. Logs
+// read from other indices, or from stores without indices, are of no type.
+func (l LogOptions) LogType() string {
+	for logType, prefix := range logTypeIndexPrefixes {
+		if strings.HasPrefix(l.Index, prefix) {
+			return logType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package logs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseLogType(t *testing.T) {
+	tests := map[string]string{
+		"":               "",
+		"application":    LogTypeApplication,
+		"Infrastructure": LogTypeInfrastructure,
+		" audit":         LogTypeAudit,
+	}
+
+	for value, expected := range tests {
+		logType, err := ParseLogType(value)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if logType != expected {
+			t.Errorf("Expected log type %s for %q found %s", expected, value, logType)
+		}
+	}
+
+	_, err := ParseLogType("app")
+	if err == nil || err.Error() != "unknown log type \"app\", one of application,infrastructure,audit is required" {
+		t.Errorf("Expected error for log type app, found %v", err)
+	}
+}
+
+func TestJournalLog(t *testing.T) {
+	document := `{"_index":"infra-000001","_source":{"systemd":{"t":{"BOOT_ID":"213c3183","PID":"1567","SYSTEMD_UNIT":"kubelet.service"},"u":{"SYSLOG_IDENTIFIER":"hyperkube"}},"message":"probe succeeded","hostname":"ip-10-0-157-165","kubernetes":{}}}`
+
+	log := LogOptions{}
+	err := json.Unmarshal([]byte(document), &log)
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	if log.Unit() != "kubelet.service" || log.Source.Systemd.T.BootID != "213c3183" || log.Source.Systemd.U.SyslogIdentifier != "hyperkube" {
+		t.Errorf("Expected journal fields of kubelet.service, found %+v", log.Source.Systemd)
+	}
+	if log.LogType() != LogTypeInfrastructure {
+		t.Errorf("Expected log type %s found %s", LogTypeInfrastructure, log.LogType())
+	}
+
+	containerLog := LogOptions{Index: "app-000002"}
+	if containerLog.Unit() != "" || containerLog.LogType() != LogTypeApplication {
+		t.Errorf("Expected an application log without unit, found %s log of unit %q", containerLog.LogType(), containerLog.Unit())
+	}
+	data, _ := json.Marshal(containerLog)
+	var fields map[string]map[string]interface{}
+	_ = json.Unmarshal(data, &fields)
+	if _, ok := fields["_source"]["systemd"]; ok {
+		t.Errorf("Expected no systemd fields for container logs, found %s", data)
+	}
+}
@@ -0,0 +1,170 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Formats of the messages parsed by a MessageParser.
+const (
+	ParseJSON   = "json"
+	ParseLogfmt = "logfmt"
+	ParseRegex  = "regex"
+)
+
+// ParseFormats lists the formats of the messages a MessageParser parses.
+func ParseFormats() []string {
+	return []string{ParseJSON, ParseLogfmt, ParseRegex}
+}
+
+// MessageParser parses the messages of logs into fields.
+type MessageParser struct {
+	format     string
+	expression *regexp.Regexp
+}
+
+// NewMessageParser returns the parser of a format. The regex format takes a
+// regular expression (RE2 syntax) whose named groups are the fields, e.g.
+// (?P<method>[A-Z]+) (?P<path>\S+), either as expression or inline after the
+// format, e.g. regex=(?P<method>[A-Z]+).
+func NewMessageParser(format string, expression string) (*MessageParser, error) {
+
+	if index := strings.Index(format, "="); index >= 0 {
+		expression = format[index+1:]
+		format = format[:index]
+	}
+
+	switch format {
+	case ParseJSON, ParseLogfmt:
+		return &MessageParser{format: format}, nil
+	case ParseRegex:
+		if len(expression) == 0 {
+			return nil, fmt.Errorf("a regular expression is required by the \"%s\" format", ParseRegex)
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while parsing the regular expression: %v", err)
+		}
+		named := false
+		for _, name := range compiled.SubexpNames() {
+			named = named || len(name) > 0
+		}
+		if !named {
+			return nil, fmt.Errorf("the regular expression \"%s\" has no named group like (?P<status>[0-9]+)", expression)
+		}
+		return &MessageParser{format: format, expression: compiled}, nil
+	default:
+		return nil, fmt.Errorf("unknown format \"%s\", one of %s is required", format, strings.Join(ParseFormats(), "|"))
+	}
+}
+
+// Parse returns the log with the fields of its message as Structured. Logs
+// the collector already parsed keep their Structured fields, logs whose
+// message is not in the format of the parser are returned as they are.
+func (p *MessageParser) Parse(log LogOptions) LogOptions {
+
+	if len(log.Source.Structured) > 0 {
+		return log
+	}
+
+	var fields map[string]interface{}
+	switch p.format {
+	case ParseJSON:
+		fields = parseJSONMessage(log.Source.Message)
+	case ParseLogfmt:
+		fields = parseLogfmtMessage(log.Source.Message)
+	case ParseRegex:
+		fields = p.parseRegexMessage(log.Source.Message)
+	}
+	if len(fields) > 0 {
+		log.Source.Structured = fields
+	}
+	return log
+}
+
+// parseJSONMessage returns the fields of a message holding a JSON object.
+func parseJSONMessage(message string) map[string]interface{} {
+
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	err := decodeJSON([]byte(message), &fields)
+	if err != nil {
+		return nil
+	}
+	return fields
+}
+
+// parseLogfmtMessage returns the key=value pairs of a logfmt message, like
+// level=info msg="request done" status=200. Values in double quotes may
+// contain spaces and Go escapes. Keys without a value are left out.
+func parseLogfmtMessage(message string) map[string]interface{} {
+
+	fields := map[string]interface{}{}
+	for index := 0; index < len(message); {
+		for index < len(message) && message[index] == ' ' {
+			index++
+		}
+		start := index
+		for index < len(message) && message[index] != '=' && message[index] != ' ' {
+			index++
+		}
+		key := message[start:index]
+		if index >= len(message) || message[index] != '=' {
+			continue
+		}
+		index++
+
+		var value string
+		if index < len(message) && message[index] == '"' {
+			end := index + 1
+			for end < len(message) && message[end] != '"' {
+				if message[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(message) {
+				// an unterminated quote is no logfmt
+				return nil
+			}
+			unquoted, err := strconv.Unquote(message[index : end+1])
+			if err != nil {
+				return nil
+			}
+			value = unquoted
+			index = end + 1
+		} else {
+			start = index
+			for index < len(message) && message[index] != ' ' {
+				index++
+			}
+			value = message[start:index]
+		}
+		if len(key) > 0 {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// parseRegexMessage returns the named groups of the expression matched in a
+// message.
+func (p *MessageParser) parseRegexMessage(message string) map[string]interface{} {
+
+	match := p.expression.FindStringSubmatch(message)
+	if match == nil {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	for index, name := range p.expression.SubexpNames() {
+		if len(name) > 0 {
+			fields[name] = match[index]
+		}
+	}
+	return fields
+}
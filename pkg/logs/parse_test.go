@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMessageParser(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Format     string
+		Expression string
+		Message    string
+		Structured map[string]interface{}
+		Error      error
+	}{
+		{
+			"JSON message",
+			"json",
+			"",
+			`{"level":"error","msg":"upstream unavailable","status":503}`,
+			map[string]interface{}{"level": "error", "msg": "upstream unavailable", "status": "503"},
+			nil,
+		},
+		{
+			"Plain text message with json",
+			"json",
+			"",
+			"upstream unavailable",
+			nil,
+			nil,
+		},
+		{
+			"Logfmt message",
+			"logfmt",
+			"",
+			`level=warn msg="slow request \"GET\"" duration=1.2s cached`,
+			map[string]interface{}{"level": "warn", "msg": `slow request "GET"`, "duration": "1.2s"},
+			nil,
+		},
+		{
+			"Unterminated quote with logfmt",
+			"logfmt",
+			"",
+			`level=warn msg="slow request`,
+			nil,
+			nil,
+		},
+		{
+			"Regular expression",
+			"regex",
+			`(?P<method>[A-Z]+) (?P<path>\S+) (?P<status>[0-9]{3})`,
+			"GET /api/orders 503 12ms",
+			map[string]interface{}{"method": "GET", "path": "/api/orders", "status": "503"},
+			nil,
+		},
+		{
+			"Inline regular expression",
+			"regex=status=(?P<status>[0-9]{3})",
+			"",
+			"request done status=404",
+			map[string]interface{}{"status": "404"},
+			nil,
+		},
+		{
+			"Regular expression without named group",
+			"regex",
+			"[0-9]{3}",
+			"",
+			nil,
+			fmt.Errorf("the regular expression \"[0-9]{3}\" has no named group like (?P<status>[0-9]+)"),
+		},
+		{
+			"Missing regular expression",
+			"regex",
+			"",
+			"",
+			nil,
+			fmt.Errorf("a regular expression is required by the \"regex\" format"),
+		},
+		{
+			"Unknown format",
+			"xml",
+			"",
+			"",
+			nil,
+			fmt.Errorf("unknown format \"xml\", one of json|logfmt|regex is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		parser, err := NewMessageParser(tt.Format, tt.Expression)
+		if fmt.Sprint(err) != fmt.Sprint(tt.Error) {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		log := LogOptions{}
+		log.Source.Message = tt.Message
+		log = parser.Parse(log)
+		if len(log.Source.Structured) != len(tt.Structured) {
+			t.Errorf("Expected structured fields %v, found %v", tt.Structured, log.Source.Structured)
+			continue
+		}
+		for key, value := range tt.Structured {
+			if FormatField(log.Source.Structured[key]) != value {
+				t.Errorf("Expected structured field %s to be %v, found %v", key, value, log.Source.Structured[key])
+			}
+		}
+	}
+}
+
+func TestMessageParserKeepsStructured(t *testing.T) {
+	parser, err := NewMessageParser(ParseJSON, "")
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+
+	log := LogOptions{}
+	log.Source.Message = `{"status":200}`
+	log.Source.Structured = map[string]interface{}{"status": "503"}
+	log = parser.Parse(log)
+	if status := log.FieldString("structured.status"); status != "503" {
+		t.Errorf("Expected structured field status to be %v, found %v", "503", status)
+	}
+}
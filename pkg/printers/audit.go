@@ -0,0 +1,117 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// Table prints audit events as a table with a row per event.
+const Table = "table"
+
+// AuditPrinter writes audit events to an output stream in a specific format.
+// Flush must be called once after the last event has been printed.
+type AuditPrinter interface {
+	PrintEvent(event logs.AuditEvent) error
+	Flush() error
+}
+
+// NewAuditPrinter returns the printer for the requested output format of
+// audit events. Columns are not supported, the table has fixed columns.
+func NewAuditPrinter(out io.Writer, options PrintOptions) (AuditPrinter, error) {
+
+	format, template := splitFormat(options)
+	var printer documentPrinter
+	var err error
+	switch format {
+	case "", Table:
+		return newTablePrinter(out), nil
+	case JSON:
+		printer = &jsonPrinter{out: out}
+	case JSONLines:
+		printer = &jsonLinesPrinter{out: out}
+	case YAML:
+		printer = &yamlPrinter{out: out}
+	case Template, GoTemplate:
+		printer, err = newGoTemplatePrinter(out, template)
+	case JSONPath:
+		printer, err = newJSONPathPrinter(out, template)
+	default:
+		return nil, fmt.Errorf("invalid \"output\" format \"%s\" requested, one of %s is required", options.Output, strings.Join(AuditFormats(), "|"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return auditDocumentPrinter{printer}, nil
+}
+
+// AuditFormats lists the supported output formats of audit events.
+func AuditFormats() []string {
+	return []string{Table, JSON, JSONLines, YAML, Template, GoTemplate, JSONPath}
+}
+
+// auditDocumentPrinter prints audit events in a document format.
+type auditDocumentPrinter struct {
+	documentPrinter
+}
+
+func (p auditDocumentPrinter) PrintEvent(event logs.AuditEvent) error {
+	return p.printDocument(event)
+}
+
+// tablePrinter prints who did what to which object, and how the API server
+// answered. Fields an event does not have, like the verb of node audit
+// records, are printed as a dash.
+type tablePrinter struct {
+	out     *tabwriter.Writer
+	started bool
+}
+
+func newTablePrinter(out io.Writer) *tablePrinter {
+	return &tablePrinter{out: tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)}
+}
+
+func (p *tablePrinter) PrintEvent(event logs.AuditEvent) error {
+
+	if !p.started {
+		err := p.printRow("TIME", "USER", "VERB", "RESOURCE", "NAMESPACE", "NAME", "CODE")
+		if err != nil {
+			return err
+		}
+		p.started = true
+	}
+	return p.printRow(
+		event.Source.Timestamp.UTC().Format(time.RFC3339),
+		event.Username(),
+		event.Source.Verb,
+		event.Resource(),
+		event.Namespace(),
+		event.Name(),
+		event.Code(),
+	)
+}
+
+func (p *tablePrinter) printRow(cells ...string) error {
+	for index, cell := range cells {
+		if len(cell) == 0 {
+			cells[index] = "-"
+		}
+	}
+	_, err := fmt.Fprintln(p.out, strings.Join(cells, "\t"))
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing audit events: %v", err)
+	}
+	return nil
+}
+
+func (p *tablePrinter) Flush() error {
+	err := p.out.Flush()
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing audit events: %v", err)
+	}
+	return nil
+}
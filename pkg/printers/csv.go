@@ -0,0 +1,141 @@
+package printers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// DefaultColumns are printed by the CSV printer when no columns are selected.
+var DefaultColumns = []string{"timestamp", "namespace", "pod", "container", "level", "message"}
+
+var columns = map[string]func(log logs.LogOptions) string{
+	"id":        func(log logs.LogOptions) string { return log.ID },
+	"index":     func(log logs.LogOptions) string { return log.Index },
+	"timestamp": func(log logs.LogOptions) string { return log.Source.Timestamp.Format(time.RFC3339Nano) },
+	"namespace": func(log logs.LogOptions) string { return log.Source.Kubernetes.NamespaceName },
+	"pod":       func(log logs.LogOptions) string { return log.Source.Kubernetes.PodName },
+	"container": func(log logs.LogOptions) string { return log.Source.Kubernetes.ContainerName },
+	"host":      func(log logs.LogOptions) string { return log.Source.Kubernetes.Host },
+	"hostname":  func(log logs.LogOptions) string { return log.Source.Hostname },
+	"image":     func(log logs.LogOptions) string { return log.Source.Kubernetes.ContainerImage },
+	"level":     func(log logs.LogOptions) string { return log.Source.Level },
+	"message":   func(log logs.LogOptions) string { return log.Source.Message },
+	"viaqmsgid": func(log logs.LogOptions) string { return log.Source.ViaqMsgID },
+	"logtype":   func(log logs.LogOptions) string { return log.LogType() },
+	"unit":      func(log logs.LogOptions) string { return log.Unit() },
+	"bootid":    journalColumn(func(systemd *logs.Systemd) string { return systemd.T.BootID }),
+	"pid":       journalColumn(func(systemd *logs.Systemd) string { return systemd.T.PID }),
+	"comm":      journalColumn(func(systemd *logs.Systemd) string { return systemd.T.Comm }),
+	"cmdline":   journalColumn(func(systemd *logs.Systemd) string { return systemd.T.Cmdline }),
+	"transport": journalColumn(func(systemd *logs.Systemd) string { return systemd.T.Transport }),
+	"syslogidentifier": journalColumn(func(systemd *logs.Systemd) string {
+		return systemd.U.SyslogIdentifier
+	}),
+	"receivedat": func(log logs.LogOptions) string {
+		return log.Source.PipelineMetadata.Collector.ReceivedAt.Format(time.RFC3339Nano)
+	},
+}
+
+// journalColumn returns a column of a journal field, empty for container logs.
+func journalColumn(field func(systemd *logs.Systemd) string) func(log logs.LogOptions) string {
+	return func(log logs.LogOptions) string {
+		if log.Source.Systemd == nil {
+			return ""
+		}
+		return field(log.Source.Systemd)
+	}
+}
+
+// isFieldPath reports whether a column is the path of a document field, like
+// kubernetes.labels.app, rather than the name of a column.
+func isFieldPath(column string) bool {
+	return strings.Contains(strings.Trim(column, "."), ".") || strings.HasPrefix(column, "_")
+}
+
+// Columns lists the column names accepted by the CSV printer.
+func Columns() []string {
+	var names []string
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// csvPrinter prints a header row followed by one row per log entry.
+type csvPrinter struct {
+	writer  *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVPrinter(out io.Writer, selected []string) (*csvPrinter, error) {
+
+	if len(selected) == 0 {
+		selected = DefaultColumns
+	}
+
+	var names []string
+	for _, column := range selected {
+		name := strings.ToLower(strings.TrimSpace(column))
+		if _, ok := columns[name]; !ok {
+			// document fields are addressed by their case sensitive path
+			name = strings.TrimSpace(column)
+			if !isFieldPath(name) {
+				return nil, fmt.Errorf("invalid column \"%s\" requested, one of %s or the dotted path of a document field is required", column, strings.Join(Columns(), ","))
+			}
+		}
+		names = append(names, name)
+	}
+	return &csvPrinter{writer: csv.NewWriter(out), columns: names}, nil
+}
+
+func (p *csvPrinter) PrintLog(log logs.LogOptions) error {
+
+	if !p.started {
+		err := p.writer.Write(p.columns)
+		if err != nil {
+			return fmt.Errorf("an error occurred while printing logs: %v", err)
+		}
+		p.started = true
+	}
+
+	record := make([]string, len(p.columns))
+	for index, name := range p.columns {
+		if column, ok := columns[name]; ok {
+			record[index] = column(log)
+		} else {
+			record[index] = log.FieldString(name)
+		}
+	}
+	err := p.writer.Write(record)
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	// rows are written as they come, for follow mode
+	p.writer.Flush()
+	if err := p.writer.Error(); err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+func (p *csvPrinter) Flush() error {
+	if !p.started {
+		err := p.writer.Write(p.columns)
+		if err != nil {
+			return fmt.Errorf("an error occurred while printing logs: %v", err)
+		}
+	}
+	p.writer.Flush()
+	if err := p.writer.Error(); err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// jsonPrinter prints all log entries as one indented JSON array. Entries are
+// written as they arrive, the closing bracket is written by Flush.
+type jsonPrinter struct {
+	out     io.Writer
+	started bool
+}
+
+func (p *jsonPrinter) PrintLog(log logs.LogOptions) error {
+	return p.printDocument(log)
+}
+
+func (p *jsonPrinter) printDocument(document interface{}) error {
+
+	data, err := json.MarshalIndent(document, "    ", "    ")
+	if err != nil {
+		return fmt.Errorf("an error occurred while marshalling log to JSON: %v", err)
+	}
+
+	separator := ",\n    "
+	if !p.started {
+		separator = "[\n    "
+		p.started = true
+	}
+
+	_, err = fmt.Fprintf(p.out, "%s%s", separator, data)
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+func (p *jsonPrinter) Flush() error {
+
+	closing := "\n]\n"
+	if !p.started {
+		closing = "[]\n"
+	}
+	_, err := fmt.Fprint(p.out, closing)
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+// jsonLinesPrinter prints every log entry as a compact JSON object on its own line.
+type jsonLinesPrinter struct {
+	out io.Writer
+}
+
+func (p *jsonLinesPrinter) PrintLog(log logs.LogOptions) error {
+	return p.printDocument(log)
+}
+
+func (p *jsonLinesPrinter) printDocument(document interface{}) error {
+
+	buffer := &bytes.Buffer{}
+	err := json.NewEncoder(buffer).Encode(document)
+	if err != nil {
+		return fmt.Errorf("an error occurred while marshalling log to JSON: %v", err)
+	}
+
+	_, err = p.out.Write(buffer.Bytes())
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+func (p *jsonLinesPrinter) Flush() error {
+	return nil
+}
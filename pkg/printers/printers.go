@@ -0,0 +1,120 @@
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+const (
+	Raw        = "raw"
+	JSON       = "json"
+	JSONLines  = "jsonl"
+	YAML       = "yaml"
+	CSV        = "csv"
+	Template   = "template"
+	GoTemplate = "go-template"
+	JSONPath   = "jsonpath"
+)
+
+// LogPrinter writes log entries to an output stream in a specific format.
+// Flush must be called once after the last entry has been printed.
+type LogPrinter interface {
+	PrintLog(log logs.LogOptions) error
+	Flush() error
+}
+
+// SeparatorPrinter is implemented by printers of line oriented formats, which
+// separate groups of related log entries with a line like grep separates
+// context hunks.
+type SeparatorPrinter interface {
+	PrintSeparator() error
+}
+
+type PrintOptions struct {
+	Output   string
+	Template string
+	Columns  []string
+	Prefix   bool
+	// PrefixNamespace adds the namespace to the prefix of raw logs, for logs
+	// of several namespaces.
+	PrefixNamespace bool
+	// Pretty prints the fields of parsed messages as readable raw lines.
+	Pretty bool
+}
+
+// NewLogPrinter returns the printer for the requested output format. Template
+// formats accept the template either inline (-o template={{...}}) or through
+// the Template option.
+func NewLogPrinter(out io.Writer, options PrintOptions) (LogPrinter, error) {
+
+	format, template := splitFormat(options)
+	if options.Pretty && len(format) > 0 && format != Raw {
+		return nil, fmt.Errorf("\"pretty\" requires the \"%s\" output format", Raw)
+	}
+	switch format {
+	case "", Raw:
+		return &rawPrinter{out: out, prefix: options.Prefix, namespace: options.PrefixNamespace, pretty: options.Pretty}, nil
+	case JSON:
+		return &jsonPrinter{out: out}, nil
+	case JSONLines:
+		return &jsonLinesPrinter{out: out}, nil
+	case YAML:
+		return &yamlPrinter{out: out}, nil
+	case CSV:
+		return newCSVPrinter(out, options.Columns)
+	case Template, GoTemplate:
+		return newGoTemplatePrinter(out, template)
+	case JSONPath:
+		return newJSONPathPrinter(out, template)
+	default:
+		return nil, fmt.Errorf("invalid \"output\" format \"%s\" requested, one of %s is required", options.Output, strings.Join(Formats(), "|"))
+	}
+}
+
+// Formats lists the supported output formats.
+func Formats() []string {
+	return []string{Raw, JSON, JSONLines, YAML, CSV, Template, GoTemplate, JSONPath}
+}
+
+// splitFormat returns the output format and the template of the options,
+// taking an inline template over the Template option.
+func splitFormat(options PrintOptions) (string, string) {
+	format := options.Output
+	template := options.Template
+	if index := strings.Index(format, "="); index >= 0 {
+		template = format[index+1:]
+		format = format[:index]
+	}
+	return format, template
+}
+
+// documentPrinter is implemented by the printers of document formats, which
+// print log entries and audit events alike.
+type documentPrinter interface {
+	printDocument(document interface{}) error
+	Flush() error
+}
+
+// toMap converts a log entry or audit event to its generic JSON
+// representation, so that templates and JSONPath expressions address fields by
+// their document names.
+func toMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	// numbers are kept as written, large integers are not printed as floats
+	document := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&document)
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
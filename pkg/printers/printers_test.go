@@ -0,0 +1,466 @@
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"sigs.k8s.io/yaml"
+)
+
+const testLog = `{"_index":"infra-000001","_type":"_doc","_id":"ODE3MjIxYjAtZDM1My00YjNmLWFiYTUtNTNjNjNkZmFjNmI2","_score":1,"_source":{"kubernetes":{"container_name":"kube-scheduler-cert-syncer","namespace_name":"openshift-kube-scheduler","pod_name":"openshift-kube-scheduler-ip-10-0-162-9.ec2.internal","host":"ip-10-0-162-9.ec2.internal"},"message":"Syncing configmaps: []","level":"unknown","hostname":"ip-10-0-162-9.ec2.internal","@timestamp":"2021-03-18T06:41:17.541712Z","viaq_msg_id":"ODE3MjIxYjAtZDM1My00YjNmLWFiYTUtNTNjNjNkZmFjNmI2"}}`
+
+const testJournalLog = `{"_index":"infra-000001","_type":"_doc","_id":"OGQxYTQyZmUtMGZkYy00YWE5LTk0M2UtZmUzYTkyMzAxNWJi","_score":1,"_source":{"systemd":{"t":{"BOOT_ID":"213c31836fa34613b00fe94f1dae4533","COMM":"kubelet","PID":"1567","SYSTEMD_UNIT":"kubelet.service","TRANSPORT":"stdout"},"u":{"SYSLOG_FACILITY":"3","SYSLOG_IDENTIFIER":"hyperkube"}},"level":"info","message":"Readiness probe succeeded","hostname":"ip-10-0-157-165","@timestamp":"2021-03-18T06:41:47.456402+00:00","viaq_msg_id":"OGQxYTQyZmUtMGZkYy00YWE5LTk0M2UtZmUzYTkyMzAxNWJi","kubernetes":{}}}`
+
+func TestNewLogPrinter(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Options    PrintOptions
+		Output     string
+		Error      error
+	}{
+		{
+			"Default output",
+			false,
+			PrintOptions{},
+			"Syncing configmaps: []\n",
+			nil,
+		},
+		{
+			"Raw output with prefix",
+			false,
+			PrintOptions{Output: "raw", Prefix: true},
+			"pod/openshift-kube-scheduler-ip-10-0-162-9.ec2.internal/kube-scheduler-cert-syncer   Syncing configmaps: []\n",
+			nil,
+		},
+		{
+			"Raw output with namespace and prefix",
+			false,
+			PrintOptions{Output: "raw", Prefix: true, PrefixNamespace: true},
+			"openshift-kube-scheduler/pod/openshift-kube-scheduler-ip-10-0-162-9.ec2.internal/kube-scheduler-cert-syncer   Syncing configmaps: []\n",
+			nil,
+		},
+		{
+			"Raw output with namespace",
+			false,
+			PrintOptions{Output: "raw", PrefixNamespace: true},
+			"openshift-kube-scheduler   Syncing configmaps: []\n",
+			nil,
+		},
+		{
+			"JSON lines output",
+			false,
+			PrintOptions{Output: "jsonl"},
+			"",
+			nil,
+		},
+		{
+			"CSV output with selected columns",
+			false,
+			PrintOptions{Output: "csv", Columns: []string{"pod", "Level"}},
+			"pod,level\nopenshift-kube-scheduler-ip-10-0-162-9.ec2.internal,unknown\n",
+			nil,
+		},
+		{
+			"CSV output with invalid column",
+			false,
+			PrintOptions{Output: "csv", Columns: []string{"dummy"}},
+			"",
+			fmt.Errorf("invalid column \"dummy\" requested, one of bootid,cmdline,comm,container,host,hostname,id,image,index,level,logtype,message,namespace,pid,pod,receivedat,syslogidentifier,timestamp,transport,unit,viaqmsgid or the dotted path of a document field is required"),
+		},
+		{
+			"Go template output",
+			false,
+			PrintOptions{Output: "template", Template: "{{._source.level}} {{._source.message}}"},
+			"unknown Syncing configmaps: []\n",
+			nil,
+		},
+		{
+			"Inline go template output",
+			false,
+			PrintOptions{Output: "go-template={{index ._source \"@timestamp\"}}"},
+			"2021-03-18T06:41:17.541712Z\n",
+			nil,
+		},
+		{
+			"Template output without template",
+			false,
+			PrintOptions{Output: "template"},
+			"",
+			fmt.Errorf("a template is required for the \"template\" output format"),
+		},
+		{
+			"JSONPath output",
+			false,
+			PrintOptions{Output: "jsonpath={._source.kubernetes.namespace_name}"},
+			"openshift-kube-scheduler\n",
+			nil,
+		},
+		{
+			"YAML output",
+			false,
+			PrintOptions{Output: "yaml", Template: ""},
+			"",
+			nil,
+		},
+		{
+			"Invalid output",
+			false,
+			PrintOptions{Output: "xml"},
+			"",
+			fmt.Errorf("invalid \"output\" format \"xml\" requested, one of raw|json|jsonl|yaml|csv|template|go-template|jsonpath is required"),
+		},
+	}
+
+	log := logs.LogOptions{}
+	err := json.Unmarshal([]byte(testLog), &log)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test log: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, err := NewLogPrinter(out, tt.Options)
+		if err == nil && tt.Error != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error == nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil && tt.Error != nil && err.Error() != tt.Error.Error() {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		err = printer.PrintLog(log)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		err = printer.Flush()
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if len(tt.Output) > 0 && out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+		if len(out.String()) == 0 {
+			t.Errorf("Expected output, found none")
+		}
+	}
+}
+
+func TestCSVPrinterWritesRows(t *testing.T) {
+	log := logs.LogOptions{}
+	err := json.Unmarshal([]byte(testLog), &log)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test log: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	printer, err := NewLogPrinter(out, PrintOptions{Output: "csv", Columns: []string{"pod", "message"}})
+	if err != nil {
+		t.Fatalf("Expected error is %v, found %v", nil, err)
+	}
+	_ = printer.PrintLog(log)
+	expected := "pod,message\nopenshift-kube-scheduler-ip-10-0-162-9.ec2.internal,Syncing configmaps: []\n"
+	if out.String() != expected {
+		t.Errorf("Expected output %q before flushing, found %q", expected, out.String())
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		ShouldFail bool
+		Output     string
+		Count      int
+	}{
+		{"JSON array of logs", false, "json", 2},
+		{"Empty JSON array", false, "json", 0},
+		{"JSON lines", false, "jsonl", 3},
+		{"YAML list of logs", false, "yaml", 2},
+		{"Empty YAML list", false, "yaml", 0},
+	}
+
+	log := logs.LogOptions{}
+	err := json.Unmarshal([]byte(testLog), &log)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test log: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, _ := NewLogPrinter(out, PrintOptions{Output: tt.Output})
+		for index := 0; index < tt.Count; index++ {
+			_ = printer.PrintLog(log)
+		}
+		_ = printer.Flush()
+
+		var logList []logs.LogOptions
+		switch tt.Output {
+		case "json":
+			err = json.Unmarshal(out.Bytes(), &logList)
+		case "yaml":
+			err = yaml.Unmarshal(out.Bytes(), &logList)
+		case "jsonl":
+			decoder := json.NewDecoder(out)
+			for decoder.More() {
+				decoded := logs.LogOptions{}
+				err = decoder.Decode(&decoded)
+				logList = append(logList, decoded)
+			}
+		}
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", nil, err)
+		}
+		if len(logList) != tt.Count {
+			t.Errorf("Expected %d logs found %d", tt.Count, len(logList))
+		}
+		for _, decoded := range logList {
+			if decoded.ID != log.ID || decoded.Source.Message != log.Source.Message || !decoded.Source.Timestamp.Equal(log.Source.Timestamp) {
+				t.Errorf("Expected log %v found %v", log, decoded)
+			}
+		}
+	}
+}
+
+func TestJournalLogPrinters(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Options  PrintOptions
+		Output   string
+	}{
+		{
+			"Raw output with prefix",
+			PrintOptions{Output: "raw", Prefix: true, PrefixNamespace: true},
+			"unit/kubelet.service/ip-10-0-157-165   Readiness probe succeeded\n",
+		},
+		{
+			"CSV output with journal columns",
+			PrintOptions{Output: "csv", Columns: []string{"logtype", "unit", "bootid", "pid", "comm", "syslogidentifier"}},
+			"logtype,unit,bootid,pid,comm,syslogidentifier\ninfrastructure,kubelet.service,213c31836fa34613b00fe94f1dae4533,1567,kubelet,hyperkube\n",
+		},
+		{
+			"Go template output",
+			PrintOptions{Output: "template", Template: "{{._source.systemd.t.SYSTEMD_UNIT}}[{{._source.systemd.t.PID}}]: {{._source.message}}"},
+			"kubelet.service[1567]: Readiness probe succeeded\n",
+		},
+		{
+			"JSONPath output",
+			PrintOptions{Output: "jsonpath={._source.systemd.u.SYSLOG_IDENTIFIER}"},
+			"hyperkube\n",
+		},
+	}
+
+	log := logs.LogOptions{}
+	err := json.Unmarshal([]byte(testJournalLog), &log)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test log: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, err := NewLogPrinter(out, tt.Options)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		_ = printer.PrintLog(log)
+		_ = printer.Flush()
+		if out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+	}
+}
+
+const testLabelledLog = `{"_index":"app-000001","_id":"1","_source":{"kubernetes":{"pod_name":"web-1","labels":{"app":"web","app.kubernetes.io/version":"1.4"},"annotations":{"openshift.io/scc":"restricted"},"namespace_labels":{"team":"payments"}},"openshift":{"labels":{"cluster":"east"}},"structured":{"status":503,"bytes":1048576},"trace_id":"4bf92f35","message":"upstream unavailable","@timestamp":"2021-03-18T06:41:17Z"}}`
+
+func TestFieldPrinters(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Options  PrintOptions
+		Output   string
+	}{
+		{
+			"CSV output with field columns",
+			PrintOptions{Output: "csv", Columns: []string{"pod", "kubernetes.labels.app", "kubernetes.labels.app.kubernetes.io/version", "structured.bytes", "_source.trace_id", "_source.openshift.labels.cluster", "kubernetes.labels.missing"}},
+			"pod,kubernetes.labels.app,kubernetes.labels.app.kubernetes.io/version,structured.bytes,_source.trace_id,_source.openshift.labels.cluster,kubernetes.labels.missing\nweb-1,web,1.4,1048576,4bf92f35,east,\n",
+		},
+		{
+			"Go template output with unknown fields",
+			PrintOptions{Output: "template={{._source.trace_id}} {{._source.structured.bytes}} {{index ._source.kubernetes.annotations \"openshift.io/scc\"}}"},
+			"4bf92f35 1048576 restricted\n",
+		},
+		{
+			"JSONPath output with namespace labels",
+			PrintOptions{Output: "jsonpath={._source.kubernetes.namespace_labels.team}"},
+			"payments\n",
+		},
+	}
+
+	log := logs.LogOptions{}
+	err := json.Unmarshal([]byte(testLabelledLog), &log)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test log: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, err := NewLogPrinter(out, tt.Options)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		_ = printer.PrintLog(log)
+		_ = printer.Flush()
+		if out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+	}
+}
+
+func TestPrettyPrinter(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		Options    PrintOptions
+		Message    string
+		Structured map[string]interface{}
+		Output     string
+	}{
+		{
+			"Pretty structured fields",
+			PrintOptions{Pretty: true},
+			`{"lvl":"err","msg":"upstream unavailable","status":503}`,
+			map[string]interface{}{"lvl": "err", "msg": "upstream unavailable", "status": json.Number("503"), "http": map[string]interface{}{"path": "/api/orders", "agent": "curl 7.1"}},
+			"2021-03-18T06:41:17Z ERR upstream unavailable http.agent=\"curl 7.1\" http.path=/api/orders status=503\n",
+		},
+		{
+			"Pretty structured fields without message and level",
+			PrintOptions{Pretty: true, Prefix: true},
+			"status=200",
+			map[string]interface{}{"status": "200"},
+			"pod/web-1/app   2021-03-18T06:41:17Z INFO status=200\n",
+		},
+		{
+			"Pretty unstructured message",
+			PrintOptions{Output: "raw", Pretty: true},
+			"plain text",
+			nil,
+			"plain text\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		log := logs.LogOptions{}
+		log.Source.Timestamp, _ = time.Parse(time.RFC3339, "2021-03-18T06:41:17Z")
+		log.Source.Level = "info"
+		log.Source.Kubernetes.PodName = "web-1"
+		log.Source.Kubernetes.ContainerName = "app"
+		log.Source.Message = tt.Message
+		log.Source.Structured = tt.Structured
+
+		out := &bytes.Buffer{}
+		printer, err := NewLogPrinter(out, tt.Options)
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		_ = printer.PrintLog(log)
+		_ = printer.Flush()
+		if out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+	}
+
+	_, err := NewLogPrinter(&bytes.Buffer{}, PrintOptions{Output: "json", Pretty: true})
+	expected := fmt.Errorf("\"pretty\" requires the \"raw\" output format")
+	if fmt.Sprint(err) != fmt.Sprint(expected) {
+		t.Errorf("Expected error is %v, found %v", expected, err)
+	}
+}
+
+const testAuditEvent = `{"_index":"audit-000001","_id":"NjJmNDUwOWEtOGYxNy00ZDc4LWE1OWItNjVkMmE2YjYwMzE3","_source":{"@timestamp":"2021-03-18T06:41:17.541712Z","kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"62f4509a-8f17-4d78-a59b-65d2a6b60317","stage":"ResponseComplete","requestURI":"/apis/apps/v1/namespaces/project-a/deployments/web/scale","verb":"patch","user":{"username":"system:admin","groups":["system:masters"]},"objectRef":{"resource":"deployments","namespace":"project-a","name":"web","apiGroup":"apps","apiVersion":"v1","subresource":"scale"},"responseStatus":{"code":200},"hostname":"master-0"}}`
+
+func TestAuditPrinters(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Options  PrintOptions
+		Output   string
+		Error    string
+	}{
+		{
+			"Default table output",
+			PrintOptions{},
+			"TIME                   USER           VERB    RESOURCE                 NAMESPACE   NAME   CODE\n" +
+				"2021-03-18T06:41:17Z   system:admin   patch   deployments.apps/scale   project-a   web    200\n" +
+				"2021-03-18T06:41:17Z   -              -       -                        -           -      -\n",
+			"",
+		},
+		{
+			"Go template output",
+			PrintOptions{Output: "template={{._source.verb}} {{._source.requestURI}}"},
+			"patch /apis/apps/v1/namespaces/project-a/deployments/web/scale\n<no value> <no value>\n",
+			"",
+		},
+		{
+			"JSONPath output",
+			PrintOptions{Output: "jsonpath", Template: "{._source.responseStatus.code}"},
+			"200\n\n",
+			"",
+		},
+		{
+			"CSV output",
+			PrintOptions{Output: "csv"},
+			"",
+			"invalid \"output\" format \"csv\" requested, one of table|json|jsonl|yaml|template|go-template|jsonpath is required",
+		},
+	}
+
+	event := logs.AuditEvent{}
+	err := json.Unmarshal([]byte(testAuditEvent), &event)
+	if err != nil {
+		t.Fatalf("unable to unmarshal test audit event: %v", err)
+	}
+	nodeRecord := logs.AuditEvent{}
+	nodeRecord.Source.Timestamp = event.Source.Timestamp
+	nodeRecord.Source.Message = "type=SYSCALL msg=audit(1616049677.541:12): arch=c000003e"
+
+	for _, tt := range tests {
+		t.Log("Running:", tt.TestName)
+		out := &bytes.Buffer{}
+		printer, err := NewAuditPrinter(out, tt.Options)
+		if len(tt.Error) > 0 {
+			if err == nil || err.Error() != tt.Error {
+				t.Errorf("Expected error is %v, found %v", tt.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Expected error is %v, found %v", nil, err)
+		}
+		_ = printer.PrintEvent(event)
+		_ = printer.PrintEvent(nodeRecord)
+		_ = printer.Flush()
+		if out.String() != tt.Output {
+			t.Errorf("Expected output %q found %q", tt.Output, out.String())
+		}
+	}
+
+	out := &bytes.Buffer{}
+	printer, _ := NewAuditPrinter(out, PrintOptions{Output: "json"})
+	_ = printer.PrintEvent(event)
+	_ = printer.Flush()
+	var decoded []logs.AuditEvent
+	err = json.Unmarshal(out.Bytes(), &decoded)
+	if err != nil || len(decoded) != 1 || decoded[0].Resource() != "deployments.apps/scale" {
+		t.Errorf("Expected the JSON array of the event, found %s", out.String())
+	}
+}
@@ -0,0 +1,135 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+)
+
+// rawPrinter prints the log message only, optionally prefixed with its source
+// and the namespace of its source. The source of journal logs is their unit
+// and node.
+type rawPrinter struct {
+	out       io.Writer
+	prefix    bool
+	namespace bool
+	pretty    bool
+}
+
+func (p *rawPrinter) PrintLog(log logs.LogOptions) error {
+
+	if p.pretty && len(log.Source.Structured) > 0 {
+		log.Source.Message = prettyMessage(log)
+	}
+	if len(log.Source.Message) == 0 {
+		return nil
+	}
+
+	kubernetes := log.Source.Kubernetes
+	namespace := ""
+	if p.namespace && len(kubernetes.NamespaceName) > 0 {
+		namespace = kubernetes.NamespaceName + "/"
+	}
+
+	var err error
+	if unit := log.Unit(); p.prefix && len(unit) > 0 {
+		// journal logs have no namespace, the unit is named with its node
+		_, err = fmt.Fprintf(p.out, "unit/%s/%s   %s\n", unit, log.Source.Hostname, log.Source.Message)
+	} else if p.prefix && len(kubernetes.PodName) > 0 && len(kubernetes.ContainerName) > 0 {
+		_, err = fmt.Fprintf(p.out, "%spod/%s/%s   %s\n", namespace, kubernetes.PodName, kubernetes.ContainerName, log.Source.Message)
+	} else if len(namespace) > 0 {
+		_, err = fmt.Fprintf(p.out, "%s   %s\n", kubernetes.NamespaceName, log.Source.Message)
+	} else {
+		_, err = fmt.Fprintf(p.out, "%s\n", log.Source.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+func (p *rawPrinter) PrintSeparator() error {
+	_, err := fmt.Fprintln(p.out, "--")
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
+
+func (p *rawPrinter) Flush() error {
+	return nil
+}
+
+// prettyMessageKeys and prettyLevelKeys are the fields parsed messages carry
+// their text and level in.
+var (
+	prettyMessageKeys = []string{"message", "msg", "MESSAGE"}
+	prettyLevelKeys   = []string{"level", "lvl", "severity"}
+)
+
+// prettyMessage formats the fields of a parsed message as a line like
+// 2021-03-18T06:41:17Z ERROR upstream unavailable status=503 path=/api, with
+// the remaining fields sorted by key and nested ones flattened to dotted keys.
+func prettyMessage(log logs.LogOptions) string {
+
+	fields := map[string]string{}
+	flattenFields("", log.Source.Structured, fields)
+
+	// without a message field the parsed message is printed as its fields
+	message := ""
+	for _, key := range prettyMessageKeys {
+		if value, ok := fields[key]; ok {
+			message = value
+			delete(fields, key)
+			break
+		}
+	}
+	level := log.Source.Level
+	for _, key := range prettyLevelKeys {
+		if value, ok := fields[key]; ok {
+			if len(value) > 0 {
+				level = value
+			}
+			delete(fields, key)
+			break
+		}
+	}
+	if len(level) == 0 {
+		level = logs.LevelUnknown
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	line := []string{log.Source.Timestamp.UTC().Format(time.RFC3339), strings.ToUpper(logs.NormalizeLevel(level))}
+	if len(message) > 0 {
+		line = append(line, message)
+	}
+	for _, key := range keys {
+		value := fields[key]
+		if len(value) == 0 || strings.ContainsAny(value, " \t\"=") {
+			value = strconv.Quote(value)
+		}
+		line = append(line, key+"="+value)
+	}
+	return strings.Join(line, " ")
+}
+
+// flattenFields formats the fields of nested objects under dotted keys.
+func flattenFields(prefix string, document map[string]interface{}, fields map[string]string) {
+	for key, value := range document {
+		if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+			flattenFields(prefix+key+".", object, fields)
+			continue
+		}
+		fields[prefix+key] = logs.FormatField(value)
+	}
+}
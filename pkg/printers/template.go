@@ -0,0 +1,110 @@
+package printers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// goTemplatePrinter executes a Go template once per log entry. The template
+// sees the log document as it is stored, for example {{._source.message}}.
+type goTemplatePrinter struct {
+	out      io.Writer
+	template *template.Template
+}
+
+func newGoTemplatePrinter(out io.Writer, text string) (*goTemplatePrinter, error) {
+
+	if len(text) == 0 {
+		return nil, fmt.Errorf("a template is required for the \"template\" output format")
+	}
+	parsed, err := template.New("log").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while parsing template: %v", err)
+	}
+	return &goTemplatePrinter{out: out, template: parsed}, nil
+}
+
+func (p *goTemplatePrinter) PrintLog(log logs.LogOptions) error {
+	return p.printDocument(log)
+}
+
+func (p *goTemplatePrinter) printDocument(value interface{}) error {
+
+	document, err := toMap(value)
+	if err != nil {
+		return fmt.Errorf("an error occurred while converting log for template: %v", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	err = p.template.Execute(buffer, document)
+	if err != nil {
+		return fmt.Errorf("an error occurred while executing template: %v", err)
+	}
+	return writeLine(p.out, buffer.String())
+}
+
+func (p *goTemplatePrinter) Flush() error {
+	return nil
+}
+
+// jsonPathPrinter evaluates a JSONPath expression once per log entry, for
+// example {._source.kubernetes.pod_name}.
+type jsonPathPrinter struct {
+	out      io.Writer
+	jsonPath *jsonpath.JSONPath
+}
+
+func newJSONPathPrinter(out io.Writer, text string) (*jsonPathPrinter, error) {
+
+	if len(text) == 0 {
+		return nil, fmt.Errorf("a template is required for the \"jsonpath\" output format")
+	}
+	parser := jsonpath.New("log").AllowMissingKeys(true)
+	err := parser.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while parsing JSONPath template: %v", err)
+	}
+	return &jsonPathPrinter{out: out, jsonPath: parser}, nil
+}
+
+func (p *jsonPathPrinter) PrintLog(log logs.LogOptions) error {
+	return p.printDocument(log)
+}
+
+func (p *jsonPathPrinter) printDocument(value interface{}) error {
+
+	document, err := toMap(value)
+	if err != nil {
+		return fmt.Errorf("an error occurred while converting log for JSONPath: %v", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	err = p.jsonPath.Execute(buffer, document)
+	if err != nil {
+		return fmt.Errorf("an error occurred while executing JSONPath template: %v", err)
+	}
+	return writeLine(p.out, buffer.String())
+}
+
+func (p *jsonPathPrinter) Flush() error {
+	return nil
+}
+
+// writeLine writes the output of a template, making sure every entry ends up
+// on its own line.
+func writeLine(out io.Writer, line string) error {
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	_, err := io.WriteString(out, line)
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
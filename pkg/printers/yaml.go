@@ -0,0 +1,53 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
+	"sigs.k8s.io/yaml"
+)
+
+// yamlPrinter prints the log entries as the items of a single YAML list.
+type yamlPrinter struct {
+	out     io.Writer
+	started bool
+}
+
+func (p *yamlPrinter) PrintLog(log logs.LogOptions) error {
+	return p.printDocument(log)
+}
+
+func (p *yamlPrinter) printDocument(document interface{}) error {
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("an error occurred while marshalling log to YAML: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for index, line := range lines {
+		indent := "  "
+		if index == 0 {
+			indent = "- "
+		}
+		_, err = fmt.Fprintf(p.out, "%s%s\n", indent, line)
+		if err != nil {
+			return fmt.Errorf("an error occurred while printing logs: %v", err)
+		}
+	}
+	p.started = true
+	return nil
+}
+
+func (p *yamlPrinter) Flush() error {
+	if p.started {
+		return nil
+	}
+	_, err := fmt.Fprint(p.out, "[]\n")
+	if err != nil {
+		return fmt.Errorf("an error occurred while printing logs: %v", err)
+	}
+	return nil
+}
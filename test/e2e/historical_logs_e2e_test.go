@@ -1,12 +1,14 @@
 package test_e2e
 
 import (
+	"context"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/constants"
+	"net/http"
 	"strconv"
 	"testing"
+	"time"
 
-	"github.com/ViaQ/log-exploration-oc-plugin/pkg/cmd"
-	"github.com/ViaQ/log-exploration-oc-plugin/pkg/k8sresources"
+	"github.com/ViaQ/log-exploration-oc-plugin/pkg/backend"
 	"github.com/ViaQ/log-exploration-oc-plugin/pkg/logs"
 )
 
@@ -14,89 +16,100 @@ func TestFetchLogs(t *testing.T) {
 	tests := []struct {
 		TestName      string
 		ShouldFail    bool
-		TestLogs      chan []logs.LogOptions
-		TestApiUrl    string
+		TestBackend   backend.Backend
 		TestLogParams map[string]string
-		TestResources map[string]string
 		Error         error
 	}{
 		{
 			"Logs with no parameters",
 			false,
-			make(chan []logs.LogOptions),
-			"http://localhost:8080/logs/filter",
+			backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs/filter"),
 			map[string]string{},
-			map[string]string{"Pod": "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
 			nil,
 		},
 		{
 			"Logs by podname",
 			false,
-			make(chan []logs.LogOptions),
-			"http://localhost:8080/logs/filter",
+			backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs/filter"),
 			map[string]string{"Podname": "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
-			map[string]string{"Pod": "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
 			nil,
 		},
 		{
 			"Logs by given time interval",
 			false,
-			make(chan []logs.LogOptions),
-			"http://localhost:8080/logs/filter",
-			map[string]string{"Tail": "00h30m"},
-			map[string]string{"Pod": "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
+			backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs/filter"),
+			map[string]string{"StartTime": "2021-03-18T06:40:00Z", "EndTime": "2021-03-18T06:42:00Z"},
 			nil,
 		},
 		{
 			"Logs with max log limit",
 			false,
-			make(chan []logs.LogOptions),
-			"http://localhost:8080/logs/filter",
+			backend.NewAPIBackend(http.DefaultClient, "http://localhost:8080/logs/filter"),
 			map[string]string{"Limit": "5"},
-			map[string]string{"Pod": "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
+			nil,
+		},
+		{
+			"Logs from Elasticsearch",
+			false,
+			backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", constants.ESIndices),
+			map[string]string{},
+			nil,
+		},
+		{
+			"Logs from Elasticsearch by namespace with max log limit",
+			false,
+			backend.NewElasticsearchBackend(http.DefaultClient, "http://localhost:9200", constants.ESIndices),
+			map[string]string{"Namespace": "openshift-kube-scheduler", "Limit": "5"},
 			nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Log("Running:", tt.TestName)
-		logParameters := cmd.LogParameters{}
-		logParameters.Limit = constants.LimitUpperBound
+		query := backend.Query{
+			Pods:  []string{"openshift-kube-scheduler-ip-10-0-162-9.ec2.internal"},
+			Limit: constants.LimitUpperBound,
+		}
 		for k, v := range tt.TestLogParams {
 			switch k {
 			case "Namespace":
-				logParameters.Namespace = v
-			case "Tail":
-				logParameters.Tail = v
-			case "StartTime":
-				logParameters.StartTime = v
-			case "EndTime":
-				logParameters.EndTime = v
+				query.Namespace = v
 			case "Level":
-				logParameters.Level = v
+				levels, err := logs.ParseLevels(v)
+				if err != nil {
+					t.Fatalf("Expected error is %v, found %v", nil, err)
+				}
+				query.Levels = levels
+			case "StartTime", "EndTime":
+				timestamp, err := time.Parse(time.RFC3339Nano, v)
+				if err != nil {
+					t.Fatalf("Expected error is %v, found %v", nil, err)
+				}
+				if k == "StartTime" {
+					query.StartTime = timestamp
+				} else {
+					query.EndTime = timestamp
+				}
 			case "Limit":
-				logParameters.Limit, _ = strconv.Atoi(v)
-			}
-		}
-		logParameters.Resources = k8sresources.Resources{}
-		for k, v := range tt.TestResources {
-			switch k {
-			case "Deployment":
-				logParameters.Resources.IsDeployment = true
-			case "Daemonset":
-				logParameters.Resources.IsDaemonSet = true
-			case "Statefulset":
-				logParameters.Resources.IsStatefulSet = true
-			case "Pod":
-				logParameters.Resources.IsPod = true
+				query.Limit, _ = strconv.Atoi(v)
 			}
-			logParameters.Resources.Name = v
 		}
 
-		go cmd.FetchLogs(tt.TestApiUrl, &logParameters, "openshift-kube-scheduler-ip-10-0-162-9.ec2.internal", tt.TestLogs)
-		podLogs := <-tt.TestLogs
-		if podLogs == nil {
+		podLogs, err := tt.TestBackend.Query(context.Background(), query)
+		if err != nil {
+			t.Errorf("Expected error is %v, found %v", tt.Error, err)
+		}
+		if len(podLogs) == 0 {
 			t.Errorf("No logs found for the pod openshift-kube-scheduler-ip-10-0-162-9.ec2.internal")
 		}
+		if len(podLogs) > query.Limit {
+			t.Errorf("Expected at most %d logs found %d", query.Limit, len(podLogs))
+		}
+		for _, log := range podLogs {
+			timestamp := log.Source.Timestamp
+			if !query.StartTime.IsZero() && (timestamp.Before(query.StartTime) || timestamp.After(query.EndTime)) {
+				t.Errorf("Expected logs between %v and %v found a log at %v", query.StartTime, query.EndTime, timestamp)
+			}
+		}
 	}
 }